@@ -1,23 +1,16 @@
 package cmd
 
 import (
-	"errors"
-	"fmt"
-	"os"
-	"path/filepath"
-
 	tea "charm.land/bubbletea/v2"
 	"github.com/charmbracelet/log"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/theantichris/ghost/v3/internal/agent"
+	"github.com/theantichris/ghost/v3/internal/llm"
 	"github.com/theantichris/ghost/v3/internal/storage"
-	"github.com/theantichris/ghost/v3/internal/tool"
 	"github.com/theantichris/ghost/v3/internal/ui"
 )
 
-var ErrHomeDir = errors.New("failed to retrieve user home directory")
-
 func newChatCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "chat",
@@ -28,28 +21,22 @@ func newChatCommand() *cobra.Command {
 		RunE:    runChat,
 	}
 
+	cmd.Flags().Int("context-limit", 4096, "context window size in tokens used to trim history, --num-ctx takes precedence, 0 disables trimming")
+	cmd.Flags().String("context-policy", agent.ContextPolicyDrop, "how older messages are removed when the context is full (drop, summarize)")
+
 	return cmd
 }
 
 func runChat(cmd *cobra.Command, args []string) error {
 	logger := cmd.Context().Value(loggerKey{}).(*log.Logger)
 
-	tavilyAPIKey := viper.GetString("search.api-key")
-	maxResults := viper.GetInt("search.max-results")
-
-	dataDir := os.Getenv("XDG_DATA_HOME")
-	if dataDir == "" {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			logger.Error(ErrHomeDir.Error(), "error", err)
-
-			return fmt.Errorf("%w: %w", ErrHomeDir, err)
-		}
+	storeDir, err := dataDir()
+	if err != nil {
+		logger.Error("failed to find data directory", "error", err)
 
-		dataDir = filepath.Join(homeDir, ".local", "share")
+		return err
 	}
 
-	storeDir := filepath.Join(dataDir, "ghost")
 	store, err := storage.NewStore(storeDir)
 	if err != nil {
 		logger.Error("failed to create store", "path", storeDir, "error", err)
@@ -57,22 +44,75 @@ func runChat(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	options, err := loadOptions()
+	if err != nil {
+		return err
+	}
+
+	contextWindow := agent.ContextWindow{Limit: viper.GetInt("context-limit"), Policy: viper.GetString("context-policy")}
+	if err := agent.ValidateContextPolicy(contextWindow.Policy); err != nil {
+		return err
+	}
+
+	toolLimits, err := loadToolLimits()
+	if err != nil {
+		return err
+	}
+
+	toolPolicies, err := loadToolPolicies()
+	if err != nil {
+		return err
+	}
+
 	prompts := cmd.Context().Value(promptKey{}).(agent.Prompt)
+	provider := cmd.Context().Value(providerKey{}).(llm.Provider)
+
+	// Missing models can't be pulled once the TUI is running so they're pulled
+	// before it starts.
+	if viper.GetBool("auto-pull") {
+		if _, err := ensureModels(cmd, provider, confirmPullTTY(logger), logger); err != nil {
+			return err
+		}
+	}
+
+	images, err := cmd.Flags().GetStringArray("image")
+	if err != nil {
+		return err
+	}
+
+	chatInfo, err := resolveModels(cmd.Context(), provider, len(images) > 0, logger)
+	if err != nil {
+		return err
+	}
+
+	// The memory tool and the TUI share the store so their writes don't race.
+	memories := storage.NewMemoryStore(storeDir)
+
+	registry, closeMCP, err := newToolRegistry(cmd.Context(), chatInfo, toolLimits, toolPolicies, memories, logger)
+	if err != nil {
+		return err
+	}
+	defer closeMCP()
 
 	config := ui.ModelConfig{
-		Context:   cmd.Context(),
-		Logger:    logger,
-		URL:       viper.GetString("url"),
-		ChatLLM:   viper.GetString("model"),
-		VisionLLM: viper.GetString("vision.model"),
-		Prompts:   prompts,
-		Registry:  tool.NewRegistry(tavilyAPIKey, maxResults, logger),
-		Store:     store,
+		Context:       cmd.Context(),
+		Logger:        logger,
+		Provider:      provider,
+		ChatLLM:       viper.GetString("model"),
+		VisionLLM:     viper.GetString("vision.model"),
+		Options:       options,
+		Think:         loadThink(),
+		HideThinking:  viper.GetBool("hide-thinking"),
+		Prompts:       prompts,
+		Registry:      registry,
+		Store:         store,
+		Memories:      memories,
+		ContextWindow: contextWindow,
 	}
 
 	chatModel := ui.NewTUIModel(config)
 
-	logger.Info("entering chat", "ollama_url", config.URL, "chat_model", config.ChatLLM, "vision_model", config.VisionLLM)
+	logger.Info("entering chat", "provider", viper.GetString("provider"), "url", viper.GetString("url"), "chat_model", config.ChatLLM, "vision_model", config.VisionLLM)
 	program := tea.NewProgram(chatModel)
 	_, err = program.Run()
 
@@ -6,10 +6,20 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/internal/schema"
+	"github.com/theantichris/ghost/v3/internal/tool"
+)
+
+const (
+	defaultCommandTimeout   = 2 * time.Minute // Time run_command waits for a command.
+	defaultCommandMaxOutput = 16384           // Bytes of command output run_command keeps.
 )
 
 var (
@@ -19,12 +29,14 @@ var (
 	ErrInvalidImageFlag = errors.New("image data stream corrupted")
 	ErrConfig           = errors.New("config file compromised")
 	ErrBindFlags        = errors.New("flag interface malfunction")
+	ErrSchemaFormat     = errors.New("schema output is JSON only: drop the markdown format")
+	ErrHomeDir          = errors.New("failed to retrieve user home directory")
 )
 
 // initConfig reads in config file and ENV variables if set.
 func initConfig(cmd *cobra.Command, cfgFile string) error {
 	viper.SetEnvPrefix("GHOST")
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "*", "-", "*"))
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
 	viper.AutomaticEnv()
 
 	if cfgFile != "" {
@@ -53,13 +65,28 @@ func initConfig(cmd *cobra.Command, cfgFile string) error {
 		logger.Debug("system config loaded", "file", viper.ConfigFileUsed())
 	}
 
-	err := viper.BindPFlags(cmd.Flags())
+	// The tools key holds the [[tools]] entries, --tools is bound to
+	// enabled-tools instead.
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Name == "tools" {
+			err = errors.Join(err, viper.BindPFlag("enabled-tools", flag))
+
+			return
+		}
+
+		err = errors.Join(err, viper.BindPFlag(flag.Name, flag))
+	})
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrBindFlags, err)
 	}
 
+	if err := applyProfile(cmd.Flags()); err != nil {
+		return err
+	}
+
 	model := viper.GetString("model")
-	if model == "" {
+	if model == "" && cmd.Annotations[annotationNoModel] == "" {
 		return ErrNoModel
 	}
 
@@ -68,18 +95,24 @@ func initConfig(cmd *cobra.Command, cfgFile string) error {
 		return err
 	}
 
+	if viper.GetString("schema") != "" && strings.ToLower(viper.GetString("format")) == "markdown" {
+		return ErrSchemaFormat
+	}
+
 	err = viper.BindPFlag("vision.model", cmd.Flags().Lookup("vision-model"))
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrBindFlags, err)
 	}
 
-	imagePaths, err := cmd.Flags().GetStringArray("image")
-	if err != nil {
-		return fmt.Errorf("%w: %w", ErrInvalidImageFlag, err)
+	for _, name := range llm.OptionNames {
+		err = viper.BindPFlag("options."+name, cmd.Flags().Lookup(name))
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrBindFlags, err)
+		}
 	}
 
-	if len(imagePaths) > 0 && viper.GetString("vision.model") == "" {
-		return ErrNoVisionModel
+	if _, err := cmd.Flags().GetStringArray("image"); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidImageFlag, err)
 	}
 
 	return nil
@@ -94,6 +127,218 @@ func configDir() (string, error) {
 	return filepath.Join(home, ".config", "ghost"), nil
 }
 
+// dataDir returns the directory threads and memories are stored in, under
+// XDG_DATA_HOME or ~/.local/share.
+// Returns ErrHomeDir if the home directory can't be found.
+func dataDir() (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrHomeDir, err)
+		}
+
+		dataHome = filepath.Join(homeDir, ".local", "share")
+	}
+
+	return filepath.Join(dataHome, "ghost"), nil
+}
+
+// loadOptions returns the model options set via flags, environment, or config
+// file.
+// Options that aren't set are left to the model's defaults.
+func loadOptions() (llm.Options, error) {
+	var options llm.Options
+
+	for _, name := range llm.OptionNames {
+		key := "options." + name
+		if !viper.IsSet(key) {
+			continue
+		}
+
+		value := viper.GetString(key)
+		if name == "stop" {
+			value = strings.Join(viper.GetStringSlice(key), ",")
+		}
+
+		if err := options.Set(name, value); err != nil {
+			return llm.Options{}, fmt.Errorf("%w: %w", ErrConfig, err)
+		}
+	}
+
+	return options, nil
+}
+
+// profileKeys are the keys a profile can set, with the flags that override
+// them.
+var profileKeys = map[string]string{"enabled-tools": "tools", "no-tools": "no-tools"}
+
+// applyProfile sets the keys of the profile named by profile from its
+// [profiles.<name>] table over the top level keys. Flags set on the command
+// line take precedence over the profile.
+// Returns ErrConfig if the profile isn't configured.
+func applyProfile(flags *pflag.FlagSet) error {
+	name := viper.GetString("profile")
+	if name == "" {
+		return nil
+	}
+
+	profile := "profiles." + name
+	if !viper.IsSet(profile) {
+		return fmt.Errorf("%w: profile %q not found", ErrConfig, name)
+	}
+
+	for key, flagName := range profileKeys {
+		if !viper.IsSet(profile + "." + key) {
+			continue
+		}
+
+		if flag := flags.Lookup(flagName); flag != nil && flag.Changed {
+			continue
+		}
+
+		viper.Set(key, viper.Get(profile+"."+key))
+	}
+
+	return nil
+}
+
+// loadToolLimits returns the limits tools are executed with.
+// Returns ErrConfig if a tool timeout isn't a duration.
+func loadToolLimits() (tool.Limits, error) {
+	limits := tool.Limits{
+		Concurrency:   viper.GetInt("tool-concurrency"),
+		Timeout:       viper.GetDuration("tool-timeout"),
+		MaxResultSize: viper.GetInt("tool-max-result"),
+		MaxIterations: viper.GetInt("tool-max-iterations"),
+	}
+
+	for name, value := range viper.GetStringMapString("tool-timeouts") {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return tool.Limits{}, fmt.Errorf("%w: tool-timeouts.%s: %w", ErrConfig, name, err)
+		}
+
+		if limits.Timeouts == nil {
+			limits.Timeouts = map[string]time.Duration{}
+		}
+
+		limits.Timeouts[name] = timeout
+	}
+
+	return limits, nil
+}
+
+// loadToolPolicies returns the approval policies by tool name.
+// Returns ErrConfig if a policy isn't allow, ask, or deny.
+func loadToolPolicies() (map[string]tool.Policy, error) {
+	policies := map[string]tool.Policy{}
+
+	for name, value := range viper.GetStringMapString("tool-policy") {
+		policy, err := tool.ParsePolicy(strings.ToLower(value))
+		if err != nil {
+			return nil, fmt.Errorf("%w: tool-policy.%s: %w", ErrConfig, name, err)
+		}
+
+		policies[name] = policy
+	}
+
+	return policies, nil
+}
+
+// loadSearchProvider returns the web search provider configured under search.
+// Returns nil when no provider is set and there's no Tavily API key, search is
+// left out.
+// Returns ErrConfig if the provider is unknown or missing its key or URL.
+func loadSearchProvider() (tool.SearchProvider, error) {
+	name := viper.GetString("search.provider")
+
+	provider, err := tool.NewSearchProvider(name, viper.GetString("search.api-key"), viper.GetString("search.url"))
+	if err != nil {
+		if name == "" && errors.Is(err, tool.ErrNoAPIKey) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("%w: search: %w", ErrConfig, err)
+	}
+
+	return provider, nil
+}
+
+// loadRunCommand returns the run_command tool configured by the command-*
+// keys.
+// Returns ErrConfig if command-timeout isn't a duration.
+func loadRunCommand() (tool.RunCommand, error) {
+	command := tool.RunCommand{
+		Allow:     viper.GetStringSlice("command-allow"),
+		Deny:      viper.GetStringSlice("command-deny"),
+		Dir:       viper.GetString("command-dir"),
+		Timeout:   defaultCommandTimeout,
+		MaxOutput: defaultCommandMaxOutput,
+		Env:       viper.GetStringSlice("command-env"),
+	}
+
+	if viper.IsSet("command-timeout") {
+		timeout, err := time.ParseDuration(viper.GetString("command-timeout"))
+		if err != nil {
+			return tool.RunCommand{}, fmt.Errorf("%w: command-timeout: %w", ErrConfig, err)
+		}
+
+		command.Timeout = timeout
+	}
+
+	if viper.IsSet("command-max-output") {
+		command.MaxOutput = viper.GetInt("command-max-output")
+	}
+
+	return command, nil
+}
+
+// loadFetch returns the fetch_url tool configured by the fetch-* keys.
+// Returns ErrConfig if fetch-timeout isn't a duration.
+func loadFetch() (tool.Fetch, error) {
+	fetch := tool.NewFetch(viper.GetBool("fetch-allow-private"))
+
+	if viper.IsSet("fetch-timeout") {
+		timeout, err := time.ParseDuration(viper.GetString("fetch-timeout"))
+		if err != nil {
+			return tool.Fetch{}, fmt.Errorf("%w: fetch-timeout: %w", ErrConfig, err)
+		}
+
+		fetch.Timeout = timeout
+	}
+
+	if viper.IsSet("fetch-max-size") {
+		fetch.MaxSize = viper.GetInt64("fetch-max-size")
+	}
+
+	return fetch, nil
+}
+
+// loadSchema loads the JSON Schema the response must match.
+// Returns nil if no schema is set.
+func loadSchema() (*schema.Schema, error) {
+	path := viper.GetString("schema")
+	if path == "" {
+		return nil, nil
+	}
+
+	return schema.Load(path)
+}
+
+// loadThink returns whether thinking is enabled via flags, environment, or
+// config file.
+// Returns nil if it isn't set so the model's default is used.
+func loadThink() *bool {
+	if !viper.IsSet("think") {
+		return nil
+	}
+
+	think := viper.GetBool("think")
+
+	return &think
+}
+
 // validateFormat returns an error if the format flag isn't a valid value.
 func validateFormat(format string) error {
 	if format != "" && (format != "json" && format != "markdown") {
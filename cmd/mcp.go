@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/viper"
+	"github.com/theantichris/ghost/v3/internal/tool"
+)
+
+const defaultMCPTimeout = 10 * time.Second // Time to connect to an MCP server and list its tools.
+
+// loadMCPServers returns the MCP servers configured under mcp-servers, sorted
+// by name.
+// Returns ErrConfig if the servers can't be read.
+func loadMCPServers() ([]tool.MCPServer, error) {
+	var configured map[string]tool.MCPServer
+	if err := viper.UnmarshalKey("mcp-servers", &configured); err != nil {
+		return nil, fmt.Errorf("%w: mcp-servers: %w", ErrConfig, err)
+	}
+
+	var servers []tool.MCPServer
+
+	for name, server := range configured {
+		server.Name = name
+
+		// Viper lowercases keys, environment variables are conventionally upper
+		// case.
+		env := map[string]string{}
+		for key, value := range server.Env {
+			env[strings.ToUpper(key)] = value
+		}
+
+		server.Env = env
+		servers = append(servers, server)
+	}
+
+	slices.SortFunc(servers, func(a, b tool.MCPServer) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return servers, nil
+}
+
+// loadMCPTimeout returns the time allowed to connect to each MCP server, 0 for
+// none.
+// Returns ErrConfig if mcp-timeout isn't a duration.
+func loadMCPTimeout() (time.Duration, error) {
+	if !viper.IsSet("mcp-timeout") {
+		return defaultMCPTimeout, nil
+	}
+
+	timeout, err := time.ParseDuration(viper.GetString("mcp-timeout"))
+	if err != nil {
+		return 0, fmt.Errorf("%w: mcp-timeout: %w", ErrConfig, err)
+	}
+
+	return timeout, nil
+}
+
+// connectMCPServers connects to servers and registers their tools, allowing
+// each server timeout to connect and list its tools.
+// Servers that can't be reached in time are logged and skipped.
+// Returns a function that disconnects from the servers.
+func connectMCPServers(ctx context.Context, registry *tool.Registry, servers []tool.MCPServer, timeout time.Duration, logger *log.Logger) func() {
+	var clients []*tool.MCPClient
+
+	for _, server := range servers {
+		client, err := connectMCPServer(ctx, registry, server, timeout, logger)
+		if err != nil {
+			logger.Warn("MCP server unavailable, skipping its tools", "server", server.Name, "error", err)
+
+			continue
+		}
+
+		clients = append(clients, client)
+	}
+
+	return func() {
+		for _, client := range clients {
+			if err := client.Close(); err != nil {
+				logger.Debug("MCP server didn't stop cleanly", "error", err)
+			}
+		}
+	}
+}
+
+// connectMCPServer connects to server and registers its tools within timeout.
+func connectMCPServer(ctx context.Context, registry *tool.Registry, server tool.MCPServer, timeout time.Duration, logger *log.Logger) (*tool.MCPClient, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	client, err := tool.ConnectMCP(ctx, server, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := registry.RegisterMCP(ctx, client, logger); err != nil {
+		_ = client.Close()
+
+		return nil, err
+	}
+
+	return client, nil
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/viper"
+	"github.com/theantichris/ghost/v3/internal/tool"
+)
+
+func TestLoadMCPServers(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	config := `
+[mcp-servers.tracker]
+url = "http://localhost:8080/mcp"
+headers = { Authorization = "Bearer token" }
+
+[mcp-servers.docs]
+command = "docs-mcp"
+args = ["--stdio"]
+env = { DOCS_TOKEN = "secret" }
+`
+
+	viper.SetConfigType("toml")
+	if err := viper.ReadConfig(strings.NewReader(config)); err != nil {
+		t.Fatalf("ReadConfig() err = %v", err)
+	}
+
+	got, err := loadMCPServers()
+	if err != nil {
+		t.Fatalf("loadMCPServers() err = %v, want nil", err)
+	}
+
+	want := []tool.MCPServer{
+		{Name: "docs", Command: "docs-mcp", Args: []string{"--stdio"}, Env: map[string]string{"DOCS_TOKEN": "secret"}},
+		{Name: "tracker", URL: "http://localhost:8080/mcp", Env: map[string]string{}, Headers: map[string]string{"authorization": "Bearer token"}},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("loadMCPServers() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConnectMCPServers_Timeout(t *testing.T) {
+	// The server never answers the handshake.
+	stop := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-stop
+	}))
+	defer server.Close()
+	defer close(stop)
+
+	logger := log.New(io.Discard)
+	registry := tool.NewRegistry(nil, 0, nil, logger)
+	servers := []tool.MCPServer{{Name: "stalled", URL: server.URL}}
+
+	start := time.Now()
+	closeMCP := connectMCPServers(context.Background(), &registry, servers, 50*time.Millisecond, logger)
+	defer closeMCP()
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("connectMCPServers() took %v, want the server skipped after the timeout", elapsed)
+	}
+
+	if definitions := registry.Definitions(); len(definitions) != 0 {
+		t.Errorf("connectMCPServers() registered %d tools, want 0", len(definitions))
+	}
+}
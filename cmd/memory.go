@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/theantichris/ghost/v3/internal/storage"
+)
+
+func newMemoryCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "memory",
+		Short: "manages the memories kept between threads",
+		Long:  "lists and removes the memories the model saved with the memory tool",
+		Args:  cobra.NoArgs,
+	}
+
+	listCmd := &cobra.Command{
+		Use:         "list",
+		Short:       "lists the saved memories",
+		Long:        "lists the saved memories with their IDs, oldest first",
+		Example:     "ghost memory list\n  ghost memory list -f json",
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{annotationNoModel: "true"},
+		RunE:        runMemoryList,
+	}
+
+	rmCmd := &cobra.Command{
+		Use:         "rm <id>...",
+		Short:       "removes saved memories",
+		Long:        "removes the saved memories with the IDs shown by ghost memory list",
+		Example:     "ghost memory rm 3f2a9c1e",
+		Args:        cobra.MinimumNArgs(1),
+		Annotations: map[string]string{annotationNoModel: "true"},
+		RunE:        runMemoryRm,
+	}
+
+	cmd.AddCommand(listCmd)
+	cmd.AddCommand(rmCmd)
+
+	return cmd
+}
+
+func runMemoryList(cmd *cobra.Command, args []string) error {
+	storeDir, err := dataDir()
+	if err != nil {
+		return err
+	}
+
+	memories, err := storage.NewMemoryStore(storeDir).List()
+	if err != nil {
+		return err
+	}
+
+	return printMemories(cmd.OutOrStdout(), memories, strings.ToLower(viper.GetString("format")))
+}
+
+func runMemoryRm(cmd *cobra.Command, args []string) error {
+	storeDir, err := dataDir()
+	if err != nil {
+		return err
+	}
+
+	store := storage.NewMemoryStore(storeDir)
+
+	for _, id := range args {
+		if err := store.Delete(id); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "removed memory %s\n", id)
+	}
+
+	return nil
+}
+
+// printMemories writes the memories to w as a table, or as JSON in JSON format.
+func printMemories(w io.Writer, memories []storage.Memory, format string) error {
+	if format == "json" {
+		data, err := json.MarshalIndent(memories, "", "  ")
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrRender, err)
+		}
+
+		fmt.Fprintln(w, string(data))
+
+		return nil
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tSAVED\tCONTENT")
+
+	for _, memory := range memories {
+		fmt.Fprintf(table, "%s\t%s\t%s\n", memory.ID, memory.CreatedAt.Format("2006-01-02"), memory.Content)
+	}
+
+	return table.Flush()
+}
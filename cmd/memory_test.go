@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/theantichris/ghost/v3/internal/storage"
+)
+
+func TestPrintMemories(t *testing.T) {
+	memories := []storage.Memory{
+		{ID: "3f2a9c1e", Content: "Prefer cobra and viper for CLI tools", CreatedAt: time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)},
+	}
+
+	var table bytes.Buffer
+	if err := printMemories(&table, memories, ""); err != nil {
+		t.Fatalf("printMemories() err = %v, want nil", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("printMemories() lines = %d, want 2", len(lines))
+	}
+
+	for _, want := range []string{"3f2a9c1e", "2026-03-14", "Prefer cobra and viper for CLI tools"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("printMemories() row = %q, missing %q", lines[1], want)
+		}
+	}
+
+	var output bytes.Buffer
+	if err := printMemories(&output, memories, "json"); err != nil {
+		t.Fatalf("printMemories() err = %v, want nil", err)
+	}
+
+	var decoded []storage.Memory
+	if err := json.Unmarshal(output.Bytes(), &decoded); err != nil || len(decoded) != 1 || decoded[0].ID != "3f2a9c1e" {
+		t.Errorf("printMemories() json = %s, want array of 1 memory", output.String())
+	}
+}
+
+func TestDataDir(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/tmp/night-city")
+
+	got, err := dataDir()
+	if err != nil {
+		t.Fatalf("dataDir() err = %v, want nil", err)
+	}
+
+	if got != "/tmp/night-city/ghost" {
+		t.Errorf("dataDir() = %q, want %q", got, "/tmp/night-city/ghost")
+	}
+}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/theantichris/ghost/v3/internal/llm"
+)
+
+// annotationNoModel marks commands that run without a chat model configured.
+const annotationNoModel = "no-model"
+
+var ErrVisionCapability = errors.New("optics module incompatible: vision model does not support images")
+
+func newModelsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "models",
+		Short:       "lists the models installed on the LLM server",
+		Long:        "lists the models installed on the LLM server with their size, family, quantization, and capabilities",
+		Example:     "ghost models\n  ghost models -f json",
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{annotationNoModel: "true"},
+		RunE:        runModels,
+	}
+
+	return cmd
+}
+
+func runModels(cmd *cobra.Command, args []string) error {
+	logger := cmd.Context().Value(loggerKey{}).(*log.Logger)
+	provider := cmd.Context().Value(providerKey{}).(llm.Provider)
+
+	catalog, ok := provider.(llm.ModelCatalog)
+	if !ok {
+		return fmt.Errorf("%w: %s", llm.ErrModelDiscovery, viper.GetString("provider"))
+	}
+
+	models, err := catalog.ListModels(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	for i, model := range models {
+		info, err := catalog.ShowModel(cmd.Context(), model.Name)
+		if err != nil {
+			logger.Debug("failed to detect model capabilities", "model", model.Name, "error", err)
+
+			continue
+		}
+
+		models[i].Capabilities = info.Capabilities
+		models[i].ContextLength = info.ContextLength
+	}
+
+	return printModels(cmd.OutOrStdout(), models, strings.ToLower(viper.GetString("format")))
+}
+
+// printModels writes the models to w as a table, or as JSON in JSON format.
+func printModels(w io.Writer, models []llm.ModelInfo, format string) error {
+	if format == "json" {
+		data, err := json.MarshalIndent(models, "", "  ")
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrRender, err)
+		}
+
+		fmt.Fprintln(w, string(data))
+
+		return nil
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "NAME\tSIZE\tFAMILY\tPARAMETERS\tQUANTIZATION\tCONTEXT\tCAPABILITIES")
+
+	for _, model := range models {
+		context := "-"
+		if model.ContextLength > 0 {
+			context = fmt.Sprint(model.ContextLength)
+		}
+
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			model.Name,
+			formatSize(model.Size),
+			orDash(model.Family),
+			orDash(model.ParameterSize),
+			orDash(model.Quantization),
+			context,
+			orDash(strings.Join(model.Capabilities, ",")),
+		)
+	}
+
+	return table.Flush()
+}
+
+// resolveModels detects the capabilities of the configured models.
+// The chat model is used for vision when no vision model is set and it's
+// multimodal.
+// Returns ErrNoVisionModel or ErrVisionCapability if images are attached
+// without a model that can analyze them.
+// Returns nil info if the provider can't report capabilities, including
+// servers that report none, so nothing is gated on them.
+func resolveModels(ctx context.Context, provider llm.Provider, hasImages bool, logger *log.Logger) (*llm.ModelInfo, error) {
+	chatModel := viper.GetString("model")
+	visionModel := viper.GetString("vision.model")
+
+	catalog, ok := provider.(llm.ModelCatalog)
+
+	showModel := func(name string) *llm.ModelInfo {
+		if !ok || name == "" {
+			return nil
+		}
+
+		info, err := catalog.ShowModel(ctx, name)
+		if err != nil {
+			logger.Debug("failed to detect model capabilities", "model", name, "error", err)
+
+			return nil
+		}
+
+		if len(info.Capabilities) == 0 {
+			logger.Debug("model capabilities not reported", "model", name)
+
+			return nil
+		}
+
+		return &info
+	}
+
+	chatInfo := showModel(chatModel)
+
+	if visionModel == "" && chatInfo != nil && chatInfo.HasCapability(llm.CapabilityVision) {
+		logger.Debug("chat model is multimodal, using it for vision", "model", chatModel)
+
+		visionModel = chatModel
+		viper.Set("vision.model", chatModel)
+	}
+
+	if !hasImages {
+		return chatInfo, nil
+	}
+
+	if visionModel == "" {
+		return chatInfo, ErrNoVisionModel
+	}
+
+	visionInfo := chatInfo
+	if visionModel != chatModel {
+		visionInfo = showModel(visionModel)
+	}
+
+	if visionInfo != nil && !visionInfo.HasCapability(llm.CapabilityVision) {
+		return chatInfo, fmt.Errorf("%w: %s", ErrVisionCapability, visionModel)
+	}
+
+	return chatInfo, nil
+}
+
+// formatSize returns size in bytes formatted with a decimal unit.
+func formatSize(size int64) string {
+	if size <= 0 {
+		return "-"
+	}
+
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	value := float64(size)
+	unit := 0
+
+	for value >= 1000 && unit < len(units)-1 {
+		value /= 1000
+		unit++
+	}
+
+	if unit == 0 {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	return fmt.Sprintf("%.1f %s", value, units[unit])
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/viper"
+	"github.com/theantichris/ghost/v3/internal/llm"
+)
+
+func TestResolveModels(t *testing.T) {
+	capabilities := map[string][]string{
+		"llama3":          {llm.CapabilityCompletion, llm.CapabilityTools},
+		"gemma3":          {llm.CapabilityCompletion, llm.CapabilityVision},
+		"llama3.2-vision": {llm.CapabilityCompletion, llm.CapabilityVision},
+		"legacy":          nil, // Servers that predate capabilities don't report them.
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		modelCapabilities, ok := capabilities[body["model"]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"model not found"}`))
+
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"capabilities": modelCapabilities})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		provider        llm.Provider
+		model           string
+		visionModel     string
+		hasImages       bool
+		wantTools       bool
+		wantVisionModel string
+		wantNilInfo     bool
+		wantErr         bool
+		err             error
+	}{
+		{
+			name:      "detects chat model capabilities",
+			provider:  llm.NewOllama(server.URL),
+			model:     "llama3",
+			wantTools: true,
+		},
+		{
+			name:            "falls back to multimodal chat model for vision",
+			provider:        llm.NewOllama(server.URL),
+			model:           "gemma3",
+			hasImages:       true,
+			wantVisionModel: "gemma3",
+		},
+		{
+			name:            "keeps configured vision model",
+			provider:        llm.NewOllama(server.URL),
+			model:           "gemma3",
+			visionModel:     "llama3.2-vision",
+			hasImages:       true,
+			wantVisionModel: "llama3.2-vision",
+		},
+		{
+			name:      "returns error for images without vision model",
+			provider:  llm.NewOllama(server.URL),
+			model:     "llama3",
+			hasImages: true,
+			wantErr:   true,
+			err:       ErrNoVisionModel,
+		},
+		{
+			name:        "returns error for vision model without vision capability",
+			provider:    llm.NewOllama(server.URL),
+			model:       "gemma3",
+			visionModel: "llama3",
+			hasImages:   true,
+			wantErr:     true,
+			err:         ErrVisionCapability,
+		},
+		{
+			name:        "returns nil info when model is unknown",
+			provider:    llm.NewOllama(server.URL),
+			model:       "missing",
+			wantNilInfo: true,
+		},
+		{
+			name:            "returns nil info when capabilities aren't reported",
+			provider:        llm.NewOllama(server.URL),
+			model:           "legacy",
+			visionModel:     "legacy",
+			hasImages:       true,
+			wantVisionModel: "legacy",
+			wantNilInfo:     true,
+		},
+		{
+			name:            "returns nil info for providers without discovery",
+			provider:        llm.NewOpenAI(server.URL, ""),
+			model:           "llama3",
+			visionModel:     "llama3",
+			hasImages:       true,
+			wantVisionModel: "llama3",
+			wantNilInfo:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+
+			viper.Set("model", tt.model)
+			viper.Set("vision.model", tt.visionModel)
+
+			got, err := resolveModels(context.Background(), tt.provider, tt.hasImages, log.New(io.Discard))
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("resolveModels() err = %v, want %v", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("resolveModels() err = %v, want nil", err)
+			}
+
+			if (got == nil) != tt.wantNilInfo {
+				t.Fatalf("resolveModels() info = %v, want nil %v", got, tt.wantNilInfo)
+			}
+
+			if got != nil && got.HasCapability(llm.CapabilityTools) != tt.wantTools {
+				t.Errorf("resolveModels() tools = %v, want %v", got.HasCapability(llm.CapabilityTools), tt.wantTools)
+			}
+
+			if visionModel := viper.GetString("vision.model"); visionModel != tt.wantVisionModel {
+				t.Errorf("resolveModels() vision model = %q, want %q", visionModel, tt.wantVisionModel)
+			}
+		})
+	}
+}
+
+func TestPrintModels(t *testing.T) {
+	models := []llm.ModelInfo{
+		{Name: "qwen3:8b", Size: 5225388164, Family: "qwen3", ParameterSize: "8.2B", Quantization: "Q4_K_M", Capabilities: []string{"completion", "tools", "thinking"}, ContextLength: 40960},
+		{Name: "mystery:latest"},
+	}
+
+	var table bytes.Buffer
+	if err := printModels(&table, models, ""); err != nil {
+		t.Fatalf("printModels() err = %v, want nil", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("printModels() lines = %d, want 3", len(lines))
+	}
+
+	for _, want := range []string{"qwen3:8b", "5.2 GB", "Q4_K_M", "40960", "completion,tools,thinking"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("printModels() row = %q, missing %q", lines[1], want)
+		}
+	}
+
+	if fields := strings.Fields(lines[2]); len(fields) != 7 || fields[1] != "-" {
+		t.Errorf("printModels() row = %q, want dashes for unknown values", lines[2])
+	}
+
+	var output bytes.Buffer
+	if err := printModels(&output, models, "json"); err != nil {
+		t.Fatalf("printModels() err = %v, want nil", err)
+	}
+
+	var decoded []llm.ModelInfo
+	if err := json.Unmarshal(output.Bytes(), &decoded); err != nil || len(decoded) != 2 {
+		t.Errorf("printModels() json = %s, want array of 2 models", output.String())
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		size int64
+		want string
+	}{
+		{size: 0, want: "-"},
+		{size: 512, want: "512 B"},
+		{size: 1500, want: "1.5 KB"},
+		{size: 4661224676, want: "4.7 GB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatSize(tt.size); got != tt.want {
+			t.Errorf("formatSize(%d) = %q, want %q", tt.size, got, tt.want)
+		}
+	}
+}
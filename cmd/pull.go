@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/internal/ui"
+	"github.com/theantichris/ghost/v3/style"
+)
+
+var ErrModelPull = errors.New("provider does not support pulling models")
+
+func newPullCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "pull <model>",
+		Short:       "downloads a model to the LLM server",
+		Long:        "downloads a model to the LLM server with a progress bar",
+		Example:     "ghost pull llama3",
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{annotationNoModel: "true"},
+		RunE:        runPull,
+	}
+
+	return cmd
+}
+
+func runPull(cmd *cobra.Command, args []string) error {
+	logger := cmd.Context().Value(loggerKey{}).(*log.Logger)
+	provider := cmd.Context().Value(providerKey{}).(llm.Provider)
+
+	puller, ok := provider.(llm.ModelPuller)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrModelPull, viper.GetString("provider"))
+	}
+
+	return pullModel(cmd, puller, args[0], logger)
+}
+
+// pullModel pulls the model name rendering its progress.
+func pullModel(cmd *cobra.Command, puller llm.ModelPuller, name string, logger *log.Logger) error {
+	programOpts, cleanup := programOptions(logger)
+	defer cleanup()
+
+	pullProgram := tea.NewProgram(ui.NewPullModel(cmd.Context(), logger, puller, name), programOpts...)
+
+	returnedModel, err := pullProgram.Run()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStreamDisplay, err)
+	}
+
+	if finalModel := returnedModel.(ui.PullModel); finalModel.Err != nil {
+		return finalModel.Err
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "%s pulled %s\n", style.GlyphInfo, name)
+
+	return nil
+}
+
+// pullMissingModels offers to pull the configured models the server doesn't
+// have when err is llm.ErrModelNotFound and auto-pull is set.
+// Returns true if a model was pulled and the request can be retried, err
+// otherwise.
+func pullMissingModels(cmd *cobra.Command, provider llm.Provider, err error, logger *log.Logger) (bool, error) {
+	if !viper.GetBool("auto-pull") || !errors.Is(err, llm.ErrModelNotFound) {
+		return false, err
+	}
+
+	pulled, pullErr := ensureModels(cmd, provider, confirmPullTTY(logger), logger)
+	if pullErr != nil {
+		return false, pullErr
+	}
+
+	if !pulled {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ensureModels offers to pull the configured models the server doesn't have,
+// asking confirm before each pull.
+// Returns whether a model was pulled, llm.ErrModelNotFound if a pull is
+// declined.
+func ensureModels(cmd *cobra.Command, provider llm.Provider, confirm func(name string) (bool, error), logger *log.Logger) (bool, error) {
+	catalog, isCatalog := provider.(llm.ModelCatalog)
+	puller, isPuller := provider.(llm.ModelPuller)
+
+	if !isCatalog || !isPuller {
+		logger.Debug("provider can't pull models, skipping auto-pull", "provider", viper.GetString("provider"))
+
+		return false, nil
+	}
+
+	names := []string{viper.GetString("model")}
+	if visionModel := viper.GetString("vision.model"); visionModel != names[0] {
+		names = append(names, visionModel)
+	}
+
+	pulled := false
+
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+
+		_, err := catalog.ShowModel(cmd.Context(), name)
+		if !errors.Is(err, llm.ErrModelNotFound) {
+			continue
+		}
+
+		pull, err := confirm(name)
+		if err != nil {
+			return pulled, err
+		}
+
+		if !pull {
+			return pulled, fmt.Errorf("%w: %s", llm.ErrModelNotFound, name)
+		}
+
+		if err := pullModel(cmd, puller, name, logger); err != nil {
+			return pulled, err
+		}
+
+		pulled = true
+	}
+
+	return pulled, nil
+}
+
+// confirmPullTTY asks on the TTY whether to pull the missing model name.
+// Declines when there's no TTY.
+func confirmPullTTY(logger *log.Logger) func(name string) (bool, error) {
+	return func(name string) (bool, error) {
+		ttyIn, ttyOut, err := tea.OpenTTY()
+		if err != nil {
+			logger.Debug("TTY unavailable, can't offer to pull model", "error", err)
+
+			return false, nil
+		}
+
+		defer func() {
+			_ = ttyIn.Close()
+			_ = ttyOut.Close()
+		}()
+
+		return confirmPull(ttyIn, ttyOut, name)
+	}
+}
+
+// confirmPull asks on out whether to pull the missing model name and reads
+// the answer from in.
+func confirmPull(in io.Reader, out io.Writer, name string) (bool, error) {
+	fmt.Fprintf(out, "%s model %s not found, pull it? [y/N] ", style.GlyphInfo, name)
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	return answer == "y" || answer == "yes", nil
+}
+
+// programOptions returns options to run a program on the TTY so output isn't
+// mixed with stdout.
+// Falls back to standard I/O when there's no TTY.
+func programOptions(logger *log.Logger) ([]tea.ProgramOption, func()) {
+	ttyIn, ttyOut, err := tea.OpenTTY()
+	if err != nil {
+		logger.Debug("TTY unavailable, using standard I/O", "error", err)
+
+		return nil, func() {}
+	}
+
+	cleanup := func() {
+		_ = ttyIn.Close()
+		_ = ttyOut.Close()
+	}
+
+	return []tea.ProgramOption{tea.WithInput(ttyIn), tea.WithOutput(ttyOut)}, cleanup
+}
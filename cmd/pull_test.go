@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/theantichris/ghost/v3/internal/llm"
+)
+
+func TestConfirmPull(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{name: "accepts y", input: "y\n", want: true},
+		{name: "accepts yes", input: " YES \n", want: true},
+		{name: "declines n", input: "n\n"},
+		{name: "declines empty answer", input: "\n"},
+		{name: "declines on EOF", input: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			got, err := confirmPull(strings.NewReader(tt.input), &out, "llama3")
+			if err != nil {
+				t.Fatalf("confirmPull() err = %v, want nil", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("confirmPull() = %v, want %v", got, tt.want)
+			}
+
+			if !strings.Contains(out.String(), "model llama3 not found, pull it?") {
+				t.Errorf("confirmPull() prompt = %q", out.String())
+			}
+		})
+	}
+}
+
+func TestEnsureModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		if body["model"] != "llama3" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"model not found"}`))
+
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"capabilities": []string{llm.CapabilityCompletion}})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		provider    llm.Provider
+		model       string
+		visionModel string
+		wantAsked   []string
+		wantErr     bool
+		err         error
+	}{
+		{
+			name:     "skips installed models",
+			provider: llm.NewOllama(server.URL),
+			model:    "llama3",
+		},
+		{
+			name:        "returns error when pull is declined",
+			provider:    llm.NewOllama(server.URL),
+			model:       "llama3",
+			visionModel: "llava",
+			wantAsked:   []string{"llava"},
+			wantErr:     true,
+			err:         llm.ErrModelNotFound,
+		},
+		{
+			name:     "skips providers that can't pull",
+			provider: llm.NewOpenAI(server.URL, "key"),
+			model:    "gpt-4o",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			defer viper.Reset()
+
+			viper.Set("model", tt.model)
+			viper.Set("vision.model", tt.visionModel)
+
+			var asked []string
+			confirm := func(name string) (bool, error) {
+				asked = append(asked, name)
+
+				return false, nil
+			}
+
+			cmd := &cobra.Command{}
+			cmd.SetContext(context.Background())
+
+			pulled, err := ensureModels(cmd, tt.provider, confirm, log.New(io.Discard))
+
+			if pulled {
+				t.Error("ensureModels() pulled = true, want false")
+			}
+
+			if len(asked) != len(tt.wantAsked) || (len(asked) > 0 && asked[0] != tt.wantAsked[0]) {
+				t.Errorf("ensureModels() asked = %v, want %v", asked, tt.wantAsked)
+			}
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("ensureModels() err = %v, want %v", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("ensureModels() err = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestPullMissingModels(t *testing.T) {
+	notFound := fmt.Errorf("%w: llama3", llm.ErrModelNotFound)
+
+	tests := []struct {
+		name     string
+		autoPull bool
+		err      error
+	}{
+		{
+			name: "returns nil without an error",
+		},
+		{
+			name: "returns error when auto-pull is off",
+			err:  notFound,
+		},
+		{
+			name:     "returns other errors",
+			autoPull: true,
+			err:      llm.ErrUnexpectedStatus,
+		},
+		{
+			name:     "returns error when the provider can't pull",
+			autoPull: true,
+			err:      notFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			defer viper.Reset()
+
+			viper.Set("auto-pull", tt.autoPull)
+			viper.Set("model", "llama3")
+
+			cmd := &cobra.Command{}
+			cmd.SetContext(context.Background())
+
+			retry, err := pullMissingModels(cmd, llm.NewOpenAI("http://localhost", "key"), tt.err, log.New(io.Discard))
+
+			if retry {
+				t.Error("pullMissingModels() retry = true, want false")
+			}
+
+			if !errors.Is(err, tt.err) {
+				t.Errorf("pullMissingModels() err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/internal/storage"
+	"github.com/theantichris/ghost/v3/internal/tool"
+)
+
+// newToolRegistry creates the tool registry from config with tools executed
+// within limits, including the tools of the configured MCP servers.
+// The filesystem tools are confined to file-roots, or the working directory
+// when it isn't set, run_command is configured by the command-* keys, and
+// fetch_url by the fetch-* keys. memory keeps its notes in memories.
+// The external tools declared by [[tools]] are registered before the MCP
+// servers' tools.
+// Only the tools in enabled-tools are kept when it's set. Tools are left out
+// when no-tools is set or the chat model doesn't support them.
+// Returns a function that disconnects from the MCP servers.
+func newToolRegistry(ctx context.Context, chatInfo *llm.ModelInfo, limits tool.Limits, policies map[string]tool.Policy, memories *storage.MemoryStore, logger *log.Logger) (tool.Registry, func(), error) {
+	if chatInfo != nil && !chatInfo.HasCapability(llm.CapabilityTools) {
+		logger.Debug("model does not support tools, streaming without tools", "model", chatInfo.Name)
+
+		return tool.NewRegistry(nil, 0, nil, logger), func() {}, nil
+	}
+
+	if viper.GetBool("no-tools") {
+		logger.Debug("tools disabled, streaming without tools")
+
+		return tool.NewRegistry(nil, 0, nil, logger), func() {}, nil
+	}
+
+	roots := viper.GetStringSlice("file-roots")
+	if len(roots) == 0 {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return tool.Registry{}, func() {}, fmt.Errorf("%w: file-roots: %w", ErrConfig, err)
+		}
+
+		roots = []string{cwd}
+	}
+
+	search, err := loadSearchProvider()
+	if err != nil {
+		return tool.Registry{}, func() {}, err
+	}
+
+	registry := tool.NewRegistry(search, viper.GetInt("search.max-results"), roots, logger)
+	registry.Limits = limits
+	registry.Policies = policies
+
+	runCommand, err := loadRunCommand()
+	if err != nil {
+		return registry, func() {}, err
+	}
+
+	registry.Register(runCommand)
+
+	fetch, err := loadFetch()
+	if err != nil {
+		return registry, func() {}, err
+	}
+
+	registry.Register(fetch)
+
+	registry.Register(tool.Memory{Store: memories})
+
+	// Commands are bounded by command-timeout, which keeps their output, unless
+	// a timeout is set for the tool.
+	if _, ok := registry.Limits.Timeouts[runCommand.Definition().Function.Name]; !ok {
+		registry.Limits.Timeouts = maps.Clone(registry.Limits.Timeouts)
+		if registry.Limits.Timeouts == nil {
+			registry.Limits.Timeouts = map[string]time.Duration{}
+		}
+
+		registry.Limits.Timeouts[runCommand.Definition().Function.Name] = 0
+	}
+
+	externalTools, err := loadExternalTools(logger)
+	if err != nil {
+		return registry, func() {}, err
+	}
+
+	registerExternalTools(&registry, externalTools, logger)
+
+	servers, err := loadMCPServers()
+	if err != nil {
+		return registry, func() {}, err
+	}
+
+	mcpTimeout, err := loadMCPTimeout()
+	if err != nil {
+		return registry, func() {}, err
+	}
+
+	// MCP servers are only started when an enabled tool might be one of theirs.
+	enabled := viper.GetStringSlice("enabled-tools")
+	if len(enabled) > 0 && len(registry.Keep(enabled)) == 0 {
+		logger.Debug("enabled tools are all built in, skipping MCP servers", "servers", len(servers))
+
+		servers = nil
+	}
+
+	closeMCP := connectMCPServers(ctx, &registry, servers, mcpTimeout, logger)
+
+	if len(enabled) > 0 {
+		for _, name := range registry.Keep(enabled) {
+			logger.Warn("enabled tool isn't available", "name", name)
+		}
+	}
+
+	return registry, closeMCP, nil
+}
+
+// newCommandRegistry creates the tool registry for the tools commands. All
+// the configured tools are registered whatever the chat model supports.
+func newCommandRegistry(cmd *cobra.Command, logger *log.Logger) (tool.Registry, func(), error) {
+	limits, err := loadToolLimits()
+	if err != nil {
+		return tool.Registry{}, func() {}, err
+	}
+
+	policies, err := loadToolPolicies()
+	if err != nil {
+		return tool.Registry{}, func() {}, err
+	}
+
+	memoryDir, err := dataDir()
+	if err != nil {
+		return tool.Registry{}, func() {}, err
+	}
+
+	return newToolRegistry(cmd.Context(), nil, limits, policies, storage.NewMemoryStore(memoryDir), logger)
+}
+
+// loadExternalTools returns the external tools declared by the [[tools]]
+// entries in config.
+// Returns ErrConfig if an entry can't be read or is invalid.
+func loadExternalTools(logger *log.Logger) ([]tool.ExternalTool, error) {
+	var configs []tool.ExternalToolConfig
+	if err := viper.UnmarshalKey("tools", &configs); err != nil {
+		return nil, fmt.Errorf("%w: tools: %w", ErrConfig, err)
+	}
+
+	var tools []tool.ExternalTool
+
+	for _, config := range configs {
+		externalTool, err := tool.NewExternalTool(config, logger)
+		if err != nil {
+			return nil, fmt.Errorf("%w: tools: %w", ErrConfig, err)
+		}
+
+		tools = append(tools, externalTool)
+	}
+
+	return tools, nil
+}
+
+// registerExternalTools registers tools, skipping those named like a tool
+// already registered.
+func registerExternalTools(registry *tool.Registry, tools []tool.ExternalTool, logger *log.Logger) {
+	for _, externalTool := range tools {
+		name := externalTool.Definition().Function.Name
+		if _, ok := registry.Tools[name]; ok {
+			logger.Warn("external tool skipped, name already registered", "name", name)
+
+			continue
+		}
+
+		registry.Register(externalTool)
+		logger.Debug("tool registered", "name", name)
+	}
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/viper"
+	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/internal/storage"
+	"github.com/theantichris/ghost/v3/internal/tool"
+)
+
+func TestNewToolRegistry(t *testing.T) {
+	tests := []struct {
+		name      string
+		chatInfo  *llm.ModelInfo
+		config    map[string]any
+		wantTools int
+	}{
+		{
+			name:      "registers tools without capability info",
+			wantTools: 7,
+		},
+		{
+			name:      "registers tools for tool models",
+			chatInfo:  &llm.ModelInfo{Name: "llama3", Capabilities: []string{llm.CapabilityTools}},
+			wantTools: 7,
+		},
+		{
+			name:      "skips tools for models without tool support",
+			chatInfo:  &llm.ModelInfo{Name: "gemma3", Capabilities: []string{llm.CapabilityCompletion}},
+			wantTools: 0,
+		},
+		{
+			name:      "keeps enabled tools",
+			config:    map[string]any{"enabled-tools": []string{"web_search", "fetch_url", "unknown"}},
+			wantTools: 2,
+		},
+		{
+			name:      "skips tools when disabled",
+			config:    map[string]any{"no-tools": true},
+			wantTools: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+
+			viper.Set("search.api-key", "tvly-test")
+
+			for key, value := range tt.config {
+				viper.Set(key, value)
+			}
+
+			registry, closeMCP, err := newToolRegistry(context.Background(), tt.chatInfo, tool.Limits{}, nil, storage.NewMemoryStore(t.TempDir()), log.New(io.Discard))
+			if err != nil {
+				t.Fatalf("newToolRegistry() err = %v, want nil", err)
+			}
+			defer closeMCP()
+
+			if len(registry.Tools) != tt.wantTools {
+				t.Errorf("newToolRegistry() tools = %d, want %d", len(registry.Tools), tt.wantTools)
+			}
+		})
+	}
+}
+
+func TestNewToolRegistry_MCPServers(t *testing.T) {
+	tests := []struct {
+		name        string
+		enabled     []string
+		wantConnect bool
+	}{
+		{
+			name:        "connects to servers when an enabled tool isn't built in",
+			enabled:     []string{"web_search", "tracker_search"},
+			wantConnect: true,
+		},
+		{
+			name:    "skips servers when the enabled tools are all built in",
+			enabled: []string{"web_search"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+
+			viper.Set("search.api-key", "tvly-test")
+			viper.Set("enabled-tools", tt.enabled)
+			viper.Set("mcp-servers", map[string]any{"tracker": map[string]any{"command": "ghost-test-missing-mcp-server"}})
+
+			var logs bytes.Buffer
+
+			_, closeMCP, err := newToolRegistry(context.Background(), nil, tool.Limits{}, nil, storage.NewMemoryStore(t.TempDir()), log.New(&logs))
+			if err != nil {
+				t.Fatalf("newToolRegistry() err = %v, want nil", err)
+			}
+			defer closeMCP()
+
+			if got := strings.Contains(logs.String(), "MCP server unavailable"); got != tt.wantConnect {
+				t.Errorf("connected = %v, want %v, logs: %s", got, tt.wantConnect, logs.String())
+			}
+		})
+	}
+}
+
+func TestLoadExternalTools(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "loads tools",
+			config: `
+[[tools]]
+name = "jira_issue"
+description = "Look up a Jira issue"
+command = "jira-issue"
+args = ["--json"]
+timeout = "10s"
+parameters = '''{"type":"object","required":["issueKey"],"properties":{"issueKey":{"type":"string","minLength":1}}}'''
+
+[[tools]]
+name = "uptime"
+command = "uptime"
+input = "env"
+`,
+			want: []string{"jira_issue", "uptime"},
+		},
+		{
+			name: "loads no tools",
+		},
+		{
+			name: "returns error for invalid tool",
+			config: `
+[[tools]]
+name = "no command"
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+
+			viper.SetConfigType("toml")
+			if err := viper.ReadConfig(strings.NewReader(tt.config)); err != nil {
+				t.Fatalf("ReadConfig() err = %v", err)
+			}
+
+			got, err := loadExternalTools(log.New(io.Discard))
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrConfig) || !errors.Is(err, tool.ErrExternalConfig) {
+					t.Errorf("loadExternalTools() err = %v, want %v and %v", err, ErrConfig, tool.ErrExternalConfig)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("loadExternalTools() err = %v, want nil", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("loadExternalTools() tools = %d, want %d", len(got), len(tt.want))
+			}
+
+			for i, externalTool := range got {
+				if name := externalTool.Definition().Function.Name; name != tt.want[i] {
+					t.Errorf("loadExternalTools() tool %d = %q, want %q", i, name, tt.want[i])
+				}
+			}
+
+			if len(got) > 0 {
+				properties := got[0].Definition().Function.Parameters.Properties
+				if property, ok := properties["issueKey"]; !ok || property.MinLength == nil {
+					t.Errorf("loadExternalTools() parameters = %+v, want issueKey with minLength", properties)
+				}
+			}
+		})
+	}
+}
+
+func TestRegisterExternalTools(t *testing.T) {
+	logger := log.New(io.Discard)
+
+	registry := tool.NewRegistry(nil, 0, nil, logger)
+	registry.Register(tool.MockTool{Name: "uptime", Result: "built in"})
+
+	uptime, err := tool.NewExternalTool(tool.ExternalToolConfig{Name: "uptime", Command: "uptime", Timeout: time.Second}, logger)
+	if err != nil {
+		t.Fatalf("NewExternalTool() err = %v", err)
+	}
+
+	jira, err := tool.NewExternalTool(tool.ExternalToolConfig{Name: "jira_issue", Command: "jira"}, logger)
+	if err != nil {
+		t.Fatalf("NewExternalTool() err = %v", err)
+	}
+
+	registerExternalTools(&registry, []tool.ExternalTool{uptime, jira}, logger)
+
+	if _, ok := registry.Tools["uptime"].(tool.MockTool); !ok {
+		t.Error("registerExternalTools() replaced a registered tool, want it skipped")
+	}
+
+	if _, ok := registry.Tools["jira_issue"].(tool.ExternalTool); !ok {
+		t.Error("registerExternalTools() didn't register jira_issue")
+	}
+}
@@ -2,10 +2,13 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/charmbracelet/log"
@@ -13,7 +16,8 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/theantichris/ghost/v3/internal/agent"
-	"github.com/theantichris/ghost/v3/internal/tool"
+	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/internal/storage"
 	"github.com/theantichris/ghost/v3/internal/ui"
 	"github.com/theantichris/ghost/v3/style"
 )
@@ -23,6 +27,7 @@ const Version = "dev"
 // Cobra context keys
 type loggerKey struct{}
 type promptKey struct{}
+type providerKey struct{}
 
 var (
 	isTTY = term.IsTerminal(os.Stdout.Fd())
@@ -31,6 +36,17 @@ var (
 	ErrRender        = errors.New("rendering matrix collapsed")
 )
 
+// ErrorHints are the hints shown with errors on how to fix them.
+var ErrorHints = []style.ErrorHint{
+	{Err: llm.ErrConnectionRefused, Hint: "is the LLM server running? start it with `ollama serve` or check --url"},
+	{Err: llm.ErrTimeout, Hint: "the server took too long, try a smaller model or check the server load"},
+	{Err: llm.ErrConnectionReset, Hint: "the connection dropped, raise --retries or check the server logs"},
+	{Err: llm.ErrServerUnavailable, Hint: "the server is busy or still loading the model, raise --retries or --retry-delay"},
+	{Err: llm.ErrModelNotFound, Hint: "pull the model with `ghost pull <model>` or list installed models with `ghost models`"},
+	{Err: llm.ErrOutOfMemory, Hint: "the model doesn't fit in memory, try a smaller model or lower --num-ctx"},
+	{Err: llm.ErrContextOverflow, Hint: "the conversation is too long for the model, raise --num-ctx or start a new chat"},
+}
+
 // NewRootCmd creates and returns the root command.
 func NewRootCmd() (*cobra.Command, func() error, error) {
 	logger, loggerCleanup, err := initLogger()
@@ -63,7 +79,20 @@ func NewRootCmd() (*cobra.Command, func() error, error) {
 
 			cmd.SetContext(context.WithValue(cmd.Context(), promptKey{}, prompts))
 
-			return initConfig(cmd, cfgFile)
+			if err := initConfig(cmd, cfgFile); err != nil {
+				return err
+			}
+
+			retry := llm.Retry{Attempts: viper.GetInt("retries"), Delay: viper.GetDuration("retry-delay")}
+
+			provider, err := llm.NewProvider(viper.GetString("provider"), viper.GetString("url"), viper.GetString("api-key"), retry)
+			if err != nil {
+				return err
+			}
+
+			cmd.SetContext(context.WithValue(cmd.Context(), providerKey{}, provider))
+
+			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return run(cmd, args)
@@ -74,10 +103,38 @@ func NewRootCmd() (*cobra.Command, func() error, error) {
 	cmd.PersistentFlags().StringP("format", "f", "", "output format (JSON, markdown), unspecified for text")
 	cmd.PersistentFlags().StringArrayP("image", "i", []string{}, "path to image file(s) (can be specified multiple times)")
 	cmd.PersistentFlags().StringP("model", "m", "", "chat model to use")
-	cmd.PersistentFlags().StringP("url", "u", "http://localhost:11434/api", "url to the Ollama API")
+	cmd.PersistentFlags().String("provider", llm.ProviderOllama, "LLM API provider (ollama, openai)")
+	cmd.PersistentFlags().StringP("url", "u", "", "url to the LLM API, unspecified for the provider default")
 	cmd.PersistentFlags().StringP("vision-model", "V", "", "vision model to use")
+	cmd.PersistentFlags().Float64("temperature", 0, "sampling temperature, unspecified for model default")
+	cmd.PersistentFlags().Int("num-ctx", 0, "context window size in tokens, unspecified for model default")
+	cmd.PersistentFlags().Int("seed", 0, "random seed for reproducible output, unspecified for model default")
+	cmd.PersistentFlags().Float64("top-p", 0, "nucleus sampling probability, unspecified for model default")
+	cmd.PersistentFlags().Float64("repeat-penalty", 0, "penalty for repeated tokens, unspecified for model default")
+	cmd.PersistentFlags().StringSlice("stop", []string{}, "stop sequence (can be specified multiple times)")
+	cmd.PersistentFlags().Bool("think", false, "enable thinking for reasoning models, unspecified for model default")
+	cmd.PersistentFlags().Bool("hide-thinking", false, "hide the model's thinking")
+	cmd.PersistentFlags().Bool("auto-pull", false, "offer to pull configured models that aren't installed")
+	cmd.PersistentFlags().Int("retries", 2, "number of retries when the LLM server is unavailable")
+	cmd.PersistentFlags().Duration("retry-delay", time.Second, "delay before the first retry, doubled for each retry after")
+	cmd.PersistentFlags().Int("tool-concurrency", 4, "number of tool calls run at once")
+	cmd.PersistentFlags().Duration("tool-timeout", 30*time.Second, "timeout for each tool call, 0 for none")
+	cmd.PersistentFlags().Int("tool-max-result", 16384, "max bytes of a tool result sent to the model, 0 for no limit")
+	cmd.PersistentFlags().Int("tool-max-iterations", 10, "rounds of tool calls before a final answer is requested, 0 for no limit")
+	cmd.PersistentFlags().StringSlice("tools", []string{}, "tools the model can use, all tools when unspecified")
+	cmd.PersistentFlags().Bool("no-tools", false, "don't let the model use tools")
+	cmd.PersistentFlags().String("profile", "", "config profile whose tool settings override the top level ones")
+
+	cmd.Flags().String("schema", "", "path to a JSON Schema the response must match, implies JSON format")
+	cmd.Flags().Int("schema-retries", 2, "number of retries when the response fails schema validation")
+	cmd.Flags().Bool("stats", false, "print token usage and timing to stderr")
+	cmd.Flags().BoolP("yes", "y", false, "approve tool calls that ask for approval, they're denied otherwise")
 
 	cmd.AddCommand(newChatCommand())
+	cmd.AddCommand(newModelsCommand())
+	cmd.AddCommand(newPullCommand())
+	cmd.AddCommand(newToolsCommand())
+	cmd.AddCommand(newMemoryCommand())
 
 	return cmd, loggerCleanup, err
 }
@@ -89,6 +146,7 @@ func NewRootCmd() (*cobra.Command, func() error, error) {
 func run(cmd *cobra.Command, args []string) error {
 	logger := cmd.Context().Value(loggerKey{}).(*log.Logger)
 	prompts := cmd.Context().Value(promptKey{}).(agent.Prompt)
+	provider := cmd.Context().Value(providerKey{}).(llm.Provider)
 
 	format := strings.ToLower(viper.GetString("format"))
 	images, err := cmd.Flags().GetStringArray("image")
@@ -96,20 +154,62 @@ func run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	chatInfo, err := resolveModels(cmd.Context(), provider, len(images) > 0, logger)
+	if err != nil {
+		return err
+	}
+
+	options, err := loadOptions()
+	if err != nil {
+		return err
+	}
+
+	responseSchema, err := loadSchema()
+	if err != nil {
+		return err
+	}
+
+	toolLimits, err := loadToolLimits()
+	if err != nil {
+		return err
+	}
+
+	toolPolicies, err := loadToolPolicies()
+	if err != nil {
+		return err
+	}
+
+	memoryDir, err := dataDir()
+	if err != nil {
+		return err
+	}
+
+	registry, closeMCP, err := newToolRegistry(cmd.Context(), chatInfo, toolLimits, toolPolicies, storage.NewMemoryStore(memoryDir), logger)
+	if err != nil {
+		return err
+	}
+	defer closeMCP()
+
+	if responseSchema != nil {
+		format = "json"
+	}
+
 	modelConfig := ui.ModelConfig{
-		Context:   cmd.Context(),
-		Prompts:   prompts,
-		Logger:    logger,
-		URL:       viper.GetString("url"),
-		ChatLLM:   viper.GetString("model"),
-		VisionLLM: viper.GetString("vision.model"),
-		Format:    format,
-		Images:    images,
-		Registry: tool.NewRegistry(
-			viper.GetString("search.api-key"),
-			viper.GetInt("search.max-results"),
-			logger,
-		),
+		Context:       cmd.Context(),
+		Prompts:       prompts,
+		Logger:        logger,
+		Provider:      provider,
+		ChatLLM:       viper.GetString("model"),
+		VisionLLM:     viper.GetString("vision.model"),
+		Options:       options,
+		Think:         loadThink(),
+		HideThinking:  viper.GetBool("hide-thinking"),
+		Format:        format,
+		Schema:        responseSchema,
+		SchemaRetries: viper.GetInt("schema-retries"),
+		Images:        images,
+		Registry:      registry,
+		AutoApprove:   viper.GetBool("yes"),
 	}
 
 	streamModel, err := ui.NewCLIModel(modelConfig, args[0])
@@ -117,27 +217,26 @@ func run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	var programOpts []tea.ProgramOption
-	if ttyIn, ttyOut, err := tea.OpenTTY(); err == nil {
-		programOpts = append(programOpts, tea.WithInput(ttyIn), tea.WithOutput(ttyOut))
-		defer func() { _ = ttyIn.Close() }()
-		defer func() { _ = ttyOut.Close() }()
-	} else {
-		logger.Debug("TTY unavailable, using standard I/O", "error", err)
+	finalModel, err := runStream(streamModel, logger)
+	if err != nil {
+		return err
 	}
 
-	streamProgram := tea.NewProgram(streamModel, programOpts...)
-
-	returnedModel, err := streamProgram.Run()
+	// The request is sent again once a missing model is pulled.
+	retry, err := pullMissingModels(cmd, provider, finalModel.Err, logger)
 	if err != nil {
-		return fmt.Errorf("%w: %w", ErrStreamDisplay, err)
+		return err
 	}
 
-	// Bubble Tea clears the output once it exits so rerender the content to
-	// Stdout.
-	finalModel := returnedModel.(ui.CLIModel)
-	if finalModel.Err != nil {
-		return finalModel.Err
+	if retry {
+		finalModel, err = runStream(streamModel.Restart(), logger)
+		if err != nil {
+			return err
+		}
+
+		if finalModel.Err != nil {
+			return finalModel.Err
+		}
 	}
 
 	render, err := style.RenderContent(finalModel.Content(), format, isTTY)
@@ -147,5 +246,53 @@ func run(cmd *cobra.Command, args []string) error {
 
 	fmt.Fprintln(cmd.OutOrStdout(), render)
 
+	for _, warning := range finalModel.Warnings() {
+		fmt.Fprintf(cmd.ErrOrStderr(), "%s warning: %v\n", style.GlyphInfo, warning)
+	}
+
+	if viper.GetBool("stats") {
+		return printStats(cmd.ErrOrStderr(), finalModel.Metrics(), format)
+	}
+
+	return nil
+}
+
+// runStream runs the program streaming the response of streamModel and
+// returns the finished model.
+// Bubble Tea clears the output once it exits so the caller rerenders the
+// content to Stdout.
+func runStream(streamModel ui.CLIModel, logger *log.Logger) (ui.CLIModel, error) {
+	programOpts, cleanup := programOptions(logger)
+	defer cleanup()
+
+	returnedModel, err := tea.NewProgram(streamModel, programOpts...).Run()
+	if err != nil {
+		return ui.CLIModel{}, fmt.Errorf("%w: %w", ErrStreamDisplay, err)
+	}
+
+	return returnedModel.(ui.CLIModel), nil
+}
+
+// printStats writes the response metrics to w, as JSON in JSON format.
+func printStats(w io.Writer, metrics *llm.Metrics, format string) error {
+	if metrics == nil {
+		fmt.Fprintf(w, "%s stats not reported by the provider\n", style.GlyphInfo)
+
+		return nil
+	}
+
+	if format == "json" {
+		stats, err := json.Marshal(metrics)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrRender, err)
+		}
+
+		fmt.Fprintln(w, string(stats))
+
+		return nil
+	}
+
+	fmt.Fprintf(w, "%s %s\n", style.GlyphInfo, metrics)
+
 	return nil
 }
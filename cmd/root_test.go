@@ -1,8 +1,22 @@
 package cmd
 
 import (
+	"bytes"
 	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/internal/schema"
+	"github.com/theantichris/ghost/v3/internal/tool"
+	"github.com/theantichris/ghost/v3/style"
 )
 
 func TestValidateFormat(t *testing.T) {
@@ -53,3 +67,606 @@ func TestValidateFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]any
+		want    string
+		wantErr bool
+		err     error
+	}{
+		{
+			name: "returns defaults when nothing is set",
+			want: "defaults",
+		},
+		{
+			name: "returns options from config",
+			config: map[string]any{
+				"options.temperature": 0.5,
+				"options.num-ctx":     8192,
+				"options.stop":        []string{"END", "STOP"},
+			},
+			want: "temperature=0.5 num-ctx=8192 stop=END,STOP",
+		},
+		{
+			name: "returns error for invalid option value",
+			config: map[string]any{
+				"options.seed": "random",
+			},
+			wantErr: true,
+			err:     ErrConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+
+			for key, value := range tt.config {
+				viper.Set(key, value)
+			}
+
+			got, err := loadOptions()
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("loadOptions() err = nil, want error")
+				}
+
+				if !errors.Is(err, tt.err) {
+					t.Errorf("loadOptions() err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("loadOptions() error = %v, want no error", err)
+			}
+
+			if got.String() != tt.want {
+				t.Errorf("loadOptions() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadThink(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want *bool
+	}{
+		{
+			name: "returns nil when think isn't set",
+			want: nil,
+		},
+		{
+			name: "returns true when think is set",
+			args: []string{"--think"},
+			want: boolPtr(true),
+		},
+		{
+			name: "returns false when think is disabled",
+			args: []string{"--think=false"},
+			want: boolPtr(false),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.Bool("think", false, "")
+
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatalf("Parse() err = %v", err)
+			}
+
+			if err := viper.BindPFlags(flags); err != nil {
+				t.Fatalf("BindPFlags() err = %v", err)
+			}
+
+			got := loadThink()
+
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("loadThink() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestLoadSchema(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "schema.json")
+	if err := os.WriteFile(path, []byte(`{"type":"object"}`), 0600); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantNil bool
+		wantErr bool
+		err     error
+	}{
+		{
+			name:    "returns nil when schema isn't set",
+			wantNil: true,
+		},
+		{
+			name: "loads schema",
+			path: path,
+		},
+		{
+			name:    "returns error for missing schema",
+			path:    filepath.Join(dir, "missing.json"),
+			wantErr: true,
+			err:     schema.ErrSchemaLoad,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+
+			viper.Set("schema", tt.path)
+
+			got, err := loadSchema()
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("loadSchema() err = %v, want %v", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("loadSchema() err = %v, want nil", err)
+			}
+
+			if (got == nil) != tt.wantNil {
+				t.Errorf("loadSchema() = %v, want nil %v", got, tt.wantNil)
+			}
+		})
+	}
+}
+
+func TestLoadToolLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeouts map[string]any
+		want     tool.Limits
+		wantErr  bool
+		err      error
+	}{
+		{
+			name: "loads limits",
+			want: tool.Limits{Concurrency: 2, Timeout: 5 * time.Second, MaxResultSize: 1024, MaxIterations: 3},
+		},
+		{
+			name:     "loads timeouts by tool name",
+			timeouts: map[string]any{"web_search": "10s"},
+			want: tool.Limits{
+				Concurrency:   2,
+				Timeout:       5 * time.Second,
+				Timeouts:      map[string]time.Duration{"web_search": 10 * time.Second},
+				MaxResultSize: 1024,
+				MaxIterations: 3,
+			},
+		},
+		{
+			name:     "returns error for invalid timeout",
+			timeouts: map[string]any{"web_search": "soon"},
+			wantErr:  true,
+			err:      ErrConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+
+			viper.Set("tool-concurrency", 2)
+			viper.Set("tool-timeout", "5s")
+			viper.Set("tool-max-result", 1024)
+			viper.Set("tool-max-iterations", 3)
+			viper.Set("tool-timeouts", tt.timeouts)
+
+			got, err := loadToolLimits()
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("loadToolLimits() err = %v, want %v", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("loadToolLimits() err = %v, want nil", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("loadToolLimits() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	config := `
+enabled-tools = ["web_search"]
+
+[profiles.offline]
+no-tools = true
+
+[profiles.research]
+enabled-tools = ["web_search", "fetch_url"]
+`
+
+	tests := []struct {
+		name             string
+		profile          string
+		args             []string
+		wantEnabledTools []string
+		wantNoTools      bool
+		wantErr          bool
+		err              error
+	}{
+		{
+			name:             "keeps top level keys without a profile",
+			wantEnabledTools: []string{"web_search"},
+		},
+		{
+			name:             "overrides enabled tools",
+			profile:          "research",
+			wantEnabledTools: []string{"web_search", "fetch_url"},
+		},
+		{
+			name:             "keeps keys the profile doesn't set",
+			profile:          "offline",
+			wantEnabledTools: []string{"web_search"},
+			wantNoTools:      true,
+		},
+		{
+			name:             "keeps flags over the profile",
+			profile:          "research",
+			args:             []string{"--tools", "read_file"},
+			wantEnabledTools: []string{"read_file"},
+		},
+		{
+			name:    "returns error for unknown profile",
+			profile: "missing",
+			wantErr: true,
+			err:     ErrConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+
+			viper.SetConfigType("toml")
+			if err := viper.ReadConfig(strings.NewReader(config)); err != nil {
+				t.Fatalf("ReadConfig() err = %v", err)
+			}
+
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.StringSlice("tools", []string{}, "")
+			flags.Bool("no-tools", false, "")
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatalf("Parse() err = %v", err)
+			}
+
+			if err := viper.BindPFlag("enabled-tools", flags.Lookup("tools")); err != nil {
+				t.Fatalf("BindPFlag() err = %v", err)
+			}
+
+			viper.Set("profile", tt.profile)
+
+			err := applyProfile(flags)
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("applyProfile() err = %v, want %v", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("applyProfile() err = %v, want nil", err)
+			}
+
+			if diff := cmp.Diff(tt.wantEnabledTools, viper.GetStringSlice("enabled-tools")); diff != "" {
+				t.Errorf("applyProfile() enabled-tools mismatch (-want +got):\n%s", diff)
+			}
+
+			if noTools := viper.GetBool("no-tools"); noTools != tt.wantNoTools {
+				t.Errorf("applyProfile() no-tools = %v, want %v", noTools, tt.wantNoTools)
+			}
+		})
+	}
+}
+
+func TestLoadToolPolicies(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies map[string]any
+		want     map[string]tool.Policy
+		wantErr  bool
+		err      error
+	}{
+		{
+			name:     "loads policies by tool name",
+			policies: map[string]any{"web_search": "allow", "write_file": "Ask"},
+			want:     map[string]tool.Policy{"web_search": tool.PolicyAllow, "write_file": tool.PolicyAsk},
+		},
+		{
+			name: "loads no policies",
+			want: map[string]tool.Policy{},
+		},
+		{
+			name:     "returns error for unknown policy",
+			policies: map[string]any{"web_search": "sometimes"},
+			wantErr:  true,
+			err:      tool.ErrToolPolicy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+
+			viper.Set("tool-policy", tt.policies)
+
+			got, err := loadToolPolicies()
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrConfig) || !errors.Is(err, tt.err) {
+					t.Errorf("loadToolPolicies() err = %v, want %v and %v", err, ErrConfig, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("loadToolPolicies() err = %v, want nil", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("loadToolPolicies() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPrintStats(t *testing.T) {
+	metrics := &llm.Metrics{PromptEvalCount: 12, EvalCount: 50, TotalDuration: 5 * time.Second, EvalDuration: 2 * time.Second}
+
+	tests := []struct {
+		name    string
+		metrics *llm.Metrics
+		format  string
+		want    string
+	}{
+		{
+			name:    "prints text stats",
+			metrics: metrics,
+			want:    style.GlyphInfo + " prompt 12 tokens, response 50 tokens, 25.0 tok/s, 5s\n",
+		},
+		{
+			name:    "prints json stats",
+			metrics: metrics,
+			format:  "json",
+			want:    `{"prompt_eval_count":12,"eval_count":50,"total_duration":5000000000,"eval_duration":2000000000}` + "\n",
+		},
+		{
+			name: "prints notice without metrics",
+			want: style.GlyphInfo + " stats not reported by the provider\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got bytes.Buffer
+
+			if err := printStats(&got, tt.metrics, tt.format); err != nil {
+				t.Fatalf("printStats() err = %v, want nil", err)
+			}
+
+			if got.String() != tt.want {
+				t.Errorf("printStats() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadRunCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]any
+		want    tool.RunCommand
+		wantErr bool
+	}{
+		{
+			name: "loads defaults",
+			want: tool.RunCommand{Timeout: defaultCommandTimeout, MaxOutput: defaultCommandMaxOutput},
+		},
+		{
+			name: "loads config",
+			config: map[string]any{
+				"command-allow":      []string{"go test"},
+				"command-deny":       []string{"rm"},
+				"command-dir":        "/tmp",
+				"command-timeout":    "5s",
+				"command-max-output": 0,
+				"command-env":        []string{"GOPATH"},
+			},
+			want: tool.RunCommand{Allow: []string{"go test"}, Deny: []string{"rm"}, Dir: "/tmp", Timeout: 5 * time.Second, Env: []string{"GOPATH"}},
+		},
+		{
+			name:    "returns error for invalid timeout",
+			config:  map[string]any{"command-timeout": "soon"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+
+			for key, value := range tt.config {
+				viper.Set(key, value)
+			}
+
+			got, err := loadRunCommand()
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrConfig) {
+					t.Errorf("loadRunCommand() err = %v, want %v", err, ErrConfig)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("loadRunCommand() err = %v, want nil", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("loadRunCommand() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoadFetch(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]any
+		want    tool.Fetch
+		wantErr bool
+	}{
+		{
+			name: "loads defaults",
+			want: tool.NewFetch(false),
+		},
+		{
+			name:   "loads config",
+			config: map[string]any{"fetch-allow-private": true, "fetch-timeout": "5s", "fetch-max-size": 1024},
+			want:   tool.Fetch{MaxSize: 1024, Timeout: 5 * time.Second, MaxLength: tool.NewFetch(false).MaxLength, AllowPrivate: true},
+		},
+		{
+			name:    "returns error for invalid timeout",
+			config:  map[string]any{"fetch-timeout": "soon"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+
+			for key, value := range tt.config {
+				viper.Set(key, value)
+			}
+
+			got, err := loadFetch()
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrConfig) {
+					t.Errorf("loadFetch() err = %v, want %v", err, ErrConfig)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("loadFetch() err = %v, want nil", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("loadFetch() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoadSearchProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]any
+		want    tool.SearchProvider
+		wantErr bool
+	}{
+		{
+			name: "loads no provider without tavily key",
+		},
+		{
+			name:   "loads tavily with key",
+			config: map[string]any{"search.api-key": "tvly-key"},
+			want:   tool.Tavily{APIKey: "tvly-key", URL: "https://api.tavily.com/search"},
+		},
+		{
+			name:   "loads searxng",
+			config: map[string]any{"search.provider": "searxng", "search.url": "http://localhost:8888"},
+			want:   tool.SearXNG{URL: "http://localhost:8888"},
+		},
+		{
+			name:    "returns error for provider missing key",
+			config:  map[string]any{"search.provider": "brave"},
+			wantErr: true,
+		},
+		{
+			name:    "returns error for unknown provider",
+			config:  map[string]any{"search.provider": "altavista"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+
+			for key, value := range tt.config {
+				viper.Set(key, value)
+			}
+
+			got, err := loadSearchProvider()
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrConfig) {
+					t.Errorf("loadSearchProvider() err = %v, want %v", err, ErrConfig)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("loadSearchProvider() err = %v, want nil", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("loadSearchProvider() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
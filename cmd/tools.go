@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/theantichris/ghost/v3/internal/agent"
+	"github.com/theantichris/ghost/v3/internal/llm"
+)
+
+func newToolsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tools",
+		Short: "lists and calls the tools available to the model",
+		Long:  "lists and calls the tools available to the model, without involving the model",
+		Args:  cobra.NoArgs,
+	}
+
+	listCmd := &cobra.Command{
+		Use:         "list",
+		Short:       "lists the registered tools",
+		Long:        "lists the registered tools with their descriptions and parameter schemas",
+		Example:     "ghost tools list\n  ghost tools list -f json",
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{annotationNoModel: "true"},
+		RunE:        runToolsList,
+	}
+
+	callCmd := &cobra.Command{
+		Use:         "call <name> [arguments]",
+		Short:       "calls a tool with JSON arguments",
+		Long:        "calls a registered tool with JSON arguments and prints the result the model would get",
+		Example:     "ghost tools call web_search '{\"query\":\"night city\"}'\n  ghost tools call list_dir",
+		Args:        cobra.RangeArgs(1, 2),
+		Annotations: map[string]string{annotationNoModel: "true"},
+		RunE:        runToolsCall,
+	}
+
+	callCmd.Flags().BoolP("yes", "y", false, "call tools whose policy asks for approval, they're denied otherwise")
+
+	cmd.AddCommand(listCmd)
+	cmd.AddCommand(callCmd)
+
+	return cmd
+}
+
+func runToolsList(cmd *cobra.Command, args []string) error {
+	logger := cmd.Context().Value(loggerKey{}).(*log.Logger)
+
+	registry, closeMCP, err := newCommandRegistry(cmd, logger)
+	if err != nil {
+		return err
+	}
+	defer closeMCP()
+
+	return printTools(cmd.OutOrStdout(), registry.Definitions(), strings.ToLower(viper.GetString("format")))
+}
+
+func runToolsCall(cmd *cobra.Command, args []string) error {
+	logger := cmd.Context().Value(loggerKey{}).(*log.Logger)
+
+	registry, closeMCP, err := newCommandRegistry(cmd, logger)
+	if err != nil {
+		return err
+	}
+	defer closeMCP()
+
+	var toolCall llm.ToolCall
+	toolCall.Function.Name = args[0]
+	if len(args) > 1 {
+		toolCall.Function.Arguments = json.RawMessage(args[1])
+	}
+
+	// There's no prompt, tools that ask are answered by --yes like in one-shot
+	// mode.
+	approve := func(llm.ToolCall) bool {
+		return viper.GetBool("yes")
+	}
+
+	if err := agent.CheckPolicy(registry, toolCall, approve); err != nil {
+		return err
+	}
+
+	result, err := registry.Execute(cmd.Context(), toolCall.Function.Name, toolCall.Function.Arguments)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), result)
+
+	return nil
+}
+
+// printTools writes the tool definitions to w sorted by name with their
+// parameters, or as JSON in JSON format.
+func printTools(w io.Writer, definitions []llm.Tool, format string) error {
+	slices.SortFunc(definitions, func(a, b llm.Tool) int {
+		return strings.Compare(a.Function.Name, b.Function.Name)
+	})
+
+	if format == "json" {
+		data, err := json.MarshalIndent(definitions, "", "  ")
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrRender, err)
+		}
+
+		fmt.Fprintln(w, string(data))
+
+		return nil
+	}
+
+	for i, definition := range definitions {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		parameters, err := json.MarshalIndent(definition.Function.Parameters, "  ", "  ")
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrRender, err)
+		}
+
+		fmt.Fprintln(w, definition.Function.Name)
+		fmt.Fprintf(w, "  %s\n", definition.Function.Description)
+		fmt.Fprintf(w, "  %s\n", parameters)
+	}
+
+	return nil
+}
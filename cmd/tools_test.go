@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/internal/tool"
+)
+
+func TestPrintTools(t *testing.T) {
+	definitions := []llm.Tool{
+		tool.MockTool{Name: "web_search", Parameters: llm.ToolParameters{Type: "object", Required: []string{"query"}, Properties: map[string]llm.ToolProperty{"query": {Type: "string"}}}}.Definition(),
+		tool.MockTool{Name: "fetch_url"}.Definition(),
+	}
+
+	var text bytes.Buffer
+	if err := printTools(&text, definitions, ""); err != nil {
+		t.Fatalf("printTools() err = %v, want nil", err)
+	}
+
+	output := text.String()
+	if !strings.HasPrefix(output, "fetch_url\n") {
+		t.Errorf("printTools() = %q, want tools sorted by name", output)
+	}
+
+	for _, want := range []string{"web_search\n", `"required": [`, `"query": {`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("printTools() = %q, missing %q", output, want)
+		}
+	}
+
+	var data bytes.Buffer
+	if err := printTools(&data, definitions, "json"); err != nil {
+		t.Fatalf("printTools() err = %v, want nil", err)
+	}
+
+	var decoded []llm.Tool
+	if err := json.Unmarshal(data.Bytes(), &decoded); err != nil || len(decoded) != 2 {
+		t.Errorf("printTools() json = %s, want array of 2 tools", data.String())
+	}
+}
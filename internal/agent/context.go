@@ -0,0 +1,184 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/theantichris/ghost/v3/internal/llm"
+)
+
+const (
+	ContextPolicyDrop      = "drop"      // Drop the oldest turns.
+	ContextPolicySummarize = "summarize" // Replace the oldest turns with a summary.
+)
+
+const (
+	charsPerToken  = 4   // Rough characters per token for English text.
+	tokensPerMsg   = 4   // Role and formatting overhead per message.
+	tokensPerImage = 768 // Rough cost of an image for vision models.
+	summaryPrefix  = "[CONVERSATION SUMMARY]\n"
+)
+
+var (
+	ErrContextPolicy = errors.New("unknown context policy: valid options are drop or summarize")
+	ErrSummarize     = errors.New("memory compression failed")
+)
+
+// ContextWindow holds how message history is fit into the model's context
+// window.
+type ContextWindow struct {
+	Limit  int    // Context window size in tokens, 0 disables management.
+	Policy string // ContextPolicyDrop or ContextPolicySummarize.
+}
+
+// ValidateContextPolicy returns ErrContextPolicy if policy isn't a context
+// policy. An empty policy is valid and drops turns.
+func ValidateContextPolicy(policy string) error {
+	switch policy {
+	case "", ContextPolicyDrop, ContextPolicySummarize:
+		return nil
+
+	default:
+		return fmt.Errorf("%w: %s", ErrContextPolicy, policy)
+	}
+}
+
+// EstimateTokens returns a rough count of the tokens in messages.
+func EstimateTokens(messages []llm.ChatMessage) int {
+	tokens := 0
+
+	for _, message := range messages {
+		chars := len(message.Content) + len(message.Thinking)
+		for _, toolCall := range message.ToolCalls {
+			chars += len(toolCall.Function.Name) + len(toolCall.Function.Arguments)
+		}
+
+		tokens += tokensPerMsg + chars/charsPerToken + len(message.Images)*tokensPerImage
+	}
+
+	return tokens
+}
+
+// FitContext returns messages trimmed to fit window.
+// The leading system and format prompts and the latest turn are always kept.
+// Older turns are dropped or summarized according to the window policy, if a
+// summary fails the turns are dropped.
+func FitContext(ctx context.Context, provider llm.Provider, model string, prompts Prompt, window ContextWindow, messages []llm.ChatMessage, logger *log.Logger) []llm.ChatMessage {
+	// Leave a quarter of the window for the response.
+	budget := window.Limit * 3 / 4
+
+	if window.Limit <= 0 || EstimateTokens(messages) <= budget {
+		return messages
+	}
+
+	pinned, turns := splitTurns(messages)
+
+	cut := 0
+	for cut < len(turns)-1 && EstimateTokens(pinned)+EstimateTokens(flatten(turns[cut:])) > budget {
+		cut++
+	}
+
+	dropped := flatten(turns[:cut])
+	kept := slices.Concat(pinned, flatten(turns[cut:]))
+
+	logger.Info("context window full, trimming history", "limit", window.Limit, "policy", window.Policy, "messages", len(dropped))
+
+	if window.Policy != ContextPolicySummarize {
+		return kept
+	}
+
+	summary, err := summarize(ctx, provider, model, prompts, dropped)
+	if err != nil {
+		logger.Warn("summary failed, dropping history", "error", err)
+
+		return kept
+	}
+
+	return slices.Concat(pinned, []llm.ChatMessage{summary}, flatten(turns[cut:]))
+}
+
+// CompactContext replaces all but the latest keep turns of messages with a
+// summary.
+// The leading system and format prompts are always kept.
+// Returns messages unchanged if there's nothing to summarize.
+func CompactContext(ctx context.Context, provider llm.Provider, model string, prompts Prompt, messages []llm.ChatMessage, keep int, logger *log.Logger) ([]llm.ChatMessage, error) {
+	pinned, turns := splitTurns(messages)
+
+	cut := max(len(turns)-keep, 0)
+	if cut == 0 || (cut == 1 && isSummary(turns[0])) {
+		return messages, nil
+	}
+
+	logger.Debug("compacting history", "messages", len(flatten(turns[:cut])))
+
+	summary, err := summarize(ctx, provider, model, prompts, flatten(turns[:cut]))
+	if err != nil {
+		return messages, err
+	}
+
+	return slices.Concat(pinned, []llm.ChatMessage{summary}, flatten(turns[cut:])), nil
+}
+
+// splitTurns splits messages into the pinned leading system messages and
+// turns. A turn starts with a user message and holds the responses and tool
+// results that follow it so tool calls are never split from their results.
+func splitTurns(messages []llm.ChatMessage) ([]llm.ChatMessage, [][]llm.ChatMessage) {
+	pinned := 0
+	for pinned < len(messages) && messages[pinned].Role == llm.RoleSystem && !strings.HasPrefix(messages[pinned].Content, summaryPrefix) {
+		pinned++
+	}
+
+	var turns [][]llm.ChatMessage
+	for _, message := range messages[pinned:] {
+		if message.Role == llm.RoleUser || len(turns) == 0 {
+			turns = append(turns, nil)
+		}
+
+		turns[len(turns)-1] = append(turns[len(turns)-1], message)
+	}
+
+	return messages[:pinned], turns
+}
+
+// isSummary returns true if turn only holds a summary of earlier turns.
+func isSummary(turn []llm.ChatMessage) bool {
+	return len(turn) == 1 && turn[0].Role == llm.RoleSystem && strings.HasPrefix(turn[0].Content, summaryPrefix)
+}
+
+func flatten(turns [][]llm.ChatMessage) []llm.ChatMessage {
+	var messages []llm.ChatMessage
+	for _, turn := range turns {
+		messages = append(messages, turn...)
+	}
+
+	return messages
+}
+
+// summarize asks the model to summarize messages and returns the summary as a
+// system message.
+func summarize(ctx context.Context, provider llm.Provider, model string, prompts Prompt, messages []llm.ChatMessage) (llm.ChatMessage, error) {
+	var transcript strings.Builder
+	for _, message := range messages {
+		content := strings.TrimPrefix(message.Content, summaryPrefix)
+		fmt.Fprintf(&transcript, "%s: %s\n\n", message.Role, content)
+	}
+
+	request := llm.ChatRequest{
+		Model: model,
+		Messages: []llm.ChatMessage{
+			{Role: llm.RoleSystem, Content: prompts.Summary},
+			{Role: llm.RoleUser, Content: transcript.String()},
+		},
+	}
+
+	response, err := provider.Chat(ctx, request)
+	if err != nil {
+		return llm.ChatMessage{}, fmt.Errorf("%w: %w", ErrSummarize, err)
+	}
+
+	return llm.ChatMessage{Role: llm.RoleSystem, Content: summaryPrefix + strings.TrimSpace(response.Content)}, nil
+}
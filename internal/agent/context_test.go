@@ -0,0 +1,195 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/theantichris/ghost/v3/internal/llm"
+)
+
+// newContextTestServer returns a server that answers chat requests with a
+// summary or fails if status isn't 200.
+func newContextTestServer(t *testing.T, status int) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":"runner asked about the heist"},"done":true}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func contextTestHistory() []llm.ChatMessage {
+	long := strings.Repeat("x", 400) // 100 tokens
+
+	var toolCall llm.ToolCall
+	toolCall.Function.Name = "web_search"
+
+	return []llm.ChatMessage{
+		{Role: llm.RoleSystem, Content: "system"},
+		{Role: llm.RoleSystem, Content: "format"},
+		{Role: llm.RoleUser, Content: long},
+		{Role: llm.RoleAssistant, Content: long, ToolCalls: []llm.ToolCall{toolCall}},
+		{Role: llm.RoleTool, Content: long},
+		{Role: llm.RoleAssistant, Content: long},
+		{Role: llm.RoleUser, Content: long},
+		{Role: llm.RoleAssistant, Content: long},
+		{Role: llm.RoleUser, Content: "latest"},
+	}
+}
+
+func TestFitContext(t *testing.T) {
+	tests := []struct {
+		name         string
+		window       ContextWindow
+		status       int
+		wantContents []string // First 12 characters of each kept message
+	}{
+		{
+			name:         "keeps history under budget",
+			window:       ContextWindow{Limit: 4096},
+			wantContents: []string{"system", "format", "xxxxxxxxxxxx", "xxxxxxxxxxxx", "xxxxxxxxxxxx", "xxxxxxxxxxxx", "xxxxxxxxxxxx", "xxxxxxxxxxxx", "latest"},
+		},
+		{
+			name:         "disabled without limit",
+			window:       ContextWindow{},
+			wantContents: []string{"system", "format", "xxxxxxxxxxxx", "xxxxxxxxxxxx", "xxxxxxxxxxxx", "xxxxxxxxxxxx", "xxxxxxxxxxxx", "xxxxxxxxxxxx", "latest"},
+		},
+		{
+			name:         "drops oldest turns with tool results",
+			window:       ContextWindow{Limit: 400, Policy: ContextPolicyDrop},
+			wantContents: []string{"system", "format", "xxxxxxxxxxxx", "xxxxxxxxxxxx", "latest"},
+		},
+		{
+			name:         "keeps latest turn when over budget",
+			window:       ContextWindow{Limit: 20, Policy: ContextPolicyDrop},
+			wantContents: []string{"system", "format", "latest"},
+		},
+		{
+			name:         "summarizes oldest turns",
+			window:       ContextWindow{Limit: 400, Policy: ContextPolicySummarize},
+			status:       http.StatusOK,
+			wantContents: []string{"system", "format", summaryPrefix[:12], "xxxxxxxxxxxx", "xxxxxxxxxxxx", "latest"},
+		},
+		{
+			name:         "drops turns when summary fails",
+			window:       ContextWindow{Limit: 400, Policy: ContextPolicySummarize},
+			status:       http.StatusInternalServerError,
+			wantContents: []string{"system", "format", "xxxxxxxxxxxx", "xxxxxxxxxxxx", "latest"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newContextTestServer(t, max(tt.status, http.StatusOK))
+
+			got := FitContext(context.Background(), llm.NewOllama(server.URL), "llama3", Prompt{Summary: "summarize"}, tt.window, contextTestHistory(), log.New(io.Discard))
+
+			var contents []string
+			for _, message := range got {
+				contents = append(contents, message.Content[:min(len(message.Content), 12)])
+			}
+
+			if strings.Join(contents, ",") != strings.Join(tt.wantContents, ",") {
+				t.Errorf("FitContext() = %v, want %v", contents, tt.wantContents)
+			}
+		})
+	}
+}
+
+func TestCompactContext(t *testing.T) {
+	tests := []struct {
+		name      string
+		messages  []llm.ChatMessage
+		status    int
+		wantCount int
+		wantErr   bool
+		err       error
+	}{
+		{
+			name:      "summarizes all but the latest turn",
+			messages:  contextTestHistory(),
+			status:    http.StatusOK,
+			wantCount: 4,
+		},
+		{
+			name:      "returns messages with nothing to compact",
+			messages:  []llm.ChatMessage{{Role: llm.RoleSystem, Content: "system"}, {Role: llm.RoleUser, Content: "hi"}},
+			status:    http.StatusOK,
+			wantCount: 2,
+		},
+		{
+			name: "doesn't summarize a lone summary",
+			messages: []llm.ChatMessage{
+				{Role: llm.RoleSystem, Content: "system"},
+				{Role: llm.RoleSystem, Content: summaryPrefix + "earlier"},
+				{Role: llm.RoleUser, Content: "hi"},
+			},
+			status:    http.StatusOK,
+			wantCount: 3,
+		},
+		{
+			name:      "returns error when summary fails",
+			messages:  contextTestHistory(),
+			status:    http.StatusInternalServerError,
+			wantCount: 9,
+			wantErr:   true,
+			err:       ErrSummarize,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newContextTestServer(t, tt.status)
+
+			got, err := CompactContext(context.Background(), llm.NewOllama(server.URL), "llama3", Prompt{Summary: "summarize"}, tt.messages, 1, log.New(io.Discard))
+
+			if len(got) != tt.wantCount {
+				t.Errorf("CompactContext() messages = %d, want %d", len(got), tt.wantCount)
+			}
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("CompactContext() err = %v, want %v", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("CompactContext() err = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	messages := []llm.ChatMessage{
+		{Role: llm.RoleUser, Content: strings.Repeat("x", 40)},
+		{Role: llm.RoleUser, Content: "look", Images: []string{"base64"}},
+	}
+
+	if got, want := EstimateTokens(messages), 4+10+4+1+768; got != want {
+		t.Errorf("EstimateTokens() = %d, want %d", got, want)
+	}
+}
+
+func TestValidateContextPolicy(t *testing.T) {
+	for _, policy := range []string{"", ContextPolicyDrop, ContextPolicySummarize} {
+		if err := ValidateContextPolicy(policy); err != nil {
+			t.Errorf("ValidateContextPolicy(%q) err = %v, want nil", policy, err)
+		}
+	}
+
+	if err := ValidateContextPolicy("forget"); !errors.Is(err, ErrContextPolicy) {
+		t.Errorf("ValidateContextPolicy() err = %v, want %v", err, ErrContextPolicy)
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"slices"
 
 	"github.com/charmbracelet/log"
 	"github.com/theantichris/ghost/v3/internal/llm"
@@ -17,7 +16,8 @@ var ErrImageAnalysis = errors.New("visual recon failed")
 
 // AnalyzeImages sends requests to the LLM to analyze images and returns a slice
 // of llm.ChatMessage with the reports.
-func AnalyseImages(ctx context.Context, url, visionModel string, prompts Prompt, images []string, logger *log.Logger) ([]llm.ChatMessage, error) {
+// options are sent with each request.
+func AnalyseImages(ctx context.Context, provider llm.Provider, visionModel string, options llm.Options, prompts Prompt, images []string, logger *log.Logger) ([]llm.ChatMessage, error) {
 	var imageAnalysis []llm.ChatMessage
 
 	// Loop through each image and send it to the LLM for analysis, attach response to returned messages.
@@ -35,9 +35,9 @@ func AnalyseImages(ctx context.Context, url, visionModel string, prompts Prompt,
 		messages = append(messages, llm.ChatMessage{Role: llm.RoleUser, Content: prompt})
 		messages[len(messages)-1].Images = []string{encodedImage} // Attach images to user prompt message.
 
-		logger.Info("initializing visual recon", "model", visionModel, "url", url, "filename", filename, "format", "markdown")
+		logger.Info("initializing visual recon", "model", visionModel, "filename", filename, "format", "markdown")
 
-		response, err := llm.AnalyzeImages(ctx, url, visionModel, messages)
+		response, err := provider.AnalyzeImages(ctx, llm.ChatRequest{Model: visionModel, Messages: messages, Options: options})
 		if err != nil {
 			logger.Error("visual recon failed", "filename", filename, "model", visionModel, "error", err)
 			return []llm.ChatMessage{}, err
@@ -62,15 +62,3 @@ func encodeImage(image string) (string, error) {
 
 	return encodedImage, nil
 }
-
-func isImage(mediaType, path string) bool {
-	if slices.Contains(imageFileTypes, mediaType) {
-		return true
-	}
-
-	if mediaType == "text/xml" && filepath.Ext(path) == ".svg" {
-		return true
-	}
-
-	return false
-}
@@ -38,6 +38,13 @@ END_IMAGE_ANALYSIS
 
 const visionPrompt = `Analyze the attached image. If no text is visible, write "none" for TEXT.`
 
+const summaryPrompt = `You compress conversation logs for a cyberpunk AI assistant named ghost.
+
+Summarize the conversation you are given so it can replace the original in
+ghost's memory. Keep facts, decisions, names, file paths, code and open
+questions. Drop greetings and filler. Write in the third person without a
+preamble.`
+
 var ErrPromptLoad = errors.New("failed to load prompt")
 
 // Prompt holds the prompts populated from the prompt config files.
@@ -47,6 +54,7 @@ type Prompt struct {
 	Vision       string
 	JSON         string
 	Markdown     string
+	Summary      string
 }
 
 // LoadPrompts reads the prompt files and saves the content to the Prompt struct.
@@ -72,6 +80,7 @@ func LoadPrompts(configDir string, logger *log.Logger) (Prompt, error) {
 		{"vision.md", visionPrompt, &prompt.Vision},
 		{"json.md", jsonPrompt, &prompt.JSON},
 		{"markdown.md", markdownPrompt, &prompt.Markdown},
+		{"summary.md", summaryPrompt, &prompt.Summary},
 	}
 
 	for _, target := range targets {
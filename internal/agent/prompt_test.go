@@ -159,10 +159,13 @@ func TestLoadPrompts(t *testing.T) {
 				if p.Markdown != markdownPrompt {
 					t.Errorf("Markdown = %q, want default", p.Markdown)
 				}
+				if p.Summary != summaryPrompt {
+					t.Errorf("Summary = %q, want default", p.Summary)
+				}
 
 				// Verify files were created on disk.
 				promptDir := filepath.Join(configDir, "prompts")
-				files := []string{"system.md", "vision_system.md", "vision.md", "json.md", "markdown.md"}
+				files := []string{"system.md", "vision_system.md", "vision.md", "json.md", "markdown.md", "summary.md"}
 				for _, f := range files {
 					if _, err := os.Stat(filepath.Join(promptDir, f)); err != nil {
 						t.Errorf("expected file %s to exist: %v", f, err)
@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/log"
+	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/internal/schema"
+	"github.com/theantichris/ghost/v3/internal/tool"
+)
+
+var ErrSchemaRetries = errors.New("response failed schema validation after all retries")
+
+// RunSchemaLoop runs the tool loop with the response constrained to the schema
+// and validates the final response against it.
+// When validation fails the error is fed back to the LLM and the request is
+// retried up to retries times.
+// onRetry is called with the validation error before each retry, a nil onRetry
+// is ignored. loopOptions are passed to the tool loop.
+// Returns ErrSchemaRetries wrapping the last validation error if the response
+// never matches.
+func RunSchemaLoop(ctx context.Context, registry tool.Registry, provider llm.Provider, request llm.ChatRequest, responseSchema *schema.Schema, retries int, onRetry func(error), loopOptions ToolLoopOptions, logger *log.Logger) ([]llm.ChatMessage, error) {
+	request.Format = responseSchema.Raw
+
+	if onRetry == nil {
+		onRetry = func(error) {}
+	}
+
+	for attempt := 0; ; attempt++ {
+		messages, err := RunToolLoop(ctx, registry, provider, request, loopOptions, logger)
+		if err != nil {
+			return messages, err
+		}
+
+		response := messages[len(messages)-1]
+
+		err = responseSchema.Validate([]byte(response.Content))
+		if err == nil {
+			return messages, nil
+		}
+
+		if attempt >= retries {
+			logger.Error("response failed schema validation", "attempts", attempt+1, "error", err)
+
+			return messages, fmt.Errorf("%w: %w", ErrSchemaRetries, err)
+		}
+
+		logger.Debug("response failed schema validation, retrying", "attempt", attempt+1, "error", err)
+		onRetry(err)
+
+		feedback := llm.ChatMessage{
+			Role:    llm.RoleUser,
+			Content: fmt.Sprintf("Your response failed validation against the JSON schema: %s\nRespond again with only JSON that matches the schema.", err),
+		}
+
+		request.Messages = append(messages, feedback)
+	}
+}
@@ -0,0 +1,132 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/internal/schema"
+	"github.com/theantichris/ghost/v3/internal/tool"
+)
+
+func TestRunSchemaLoop(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockResponses []string
+		retries       int
+		wantRetries   int
+		wantContent   string
+		wantErr       bool
+		err           error
+	}{
+		{
+			name: "returns valid response",
+			mockResponses: []string{
+				`{"message":{"role":"assistant","content":"{\"name\":\"Case\"}"}}`,
+			},
+			retries:     2,
+			wantContent: `{"name":"Case"}`,
+		},
+		{
+			name: "retries invalid response",
+			mockResponses: []string{
+				`{"message":{"role":"assistant","content":"{\"handle\":\"Case\"}"}}`,
+				`{"message":{"role":"assistant","content":"{\"name\":\"Case\"}"}}`,
+			},
+			retries:     2,
+			wantRetries: 1,
+			wantContent: `{"name":"Case"}`,
+		},
+		{
+			name: "returns error when retries are exhausted",
+			mockResponses: []string{
+				`{"message":{"role":"assistant","content":"not json"}}`,
+				`{"message":{"role":"assistant","content":"{}"}}`,
+			},
+			retries:     1,
+			wantRetries: 1,
+			wantErr:     true,
+			err:         ErrSchemaRetries,
+		},
+	}
+
+	responseSchema, err := schema.Parse([]byte(`{"type":"object","properties":{"name":{"type":"string"}},"required":["name"]}`))
+	if err != nil {
+		t.Fatalf("Parse() err = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var callCount atomic.Int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				idx := int(callCount.Add(1)) - 1
+
+				var request llm.ChatRequest
+				if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+					t.Errorf("failed to decode request: %v", err)
+				}
+
+				if string(request.Format) != string(responseSchema.Raw) {
+					t.Errorf("request format = %s, want %s", request.Format, responseSchema.Raw)
+				}
+
+				if idx >= len(tt.mockResponses) {
+					t.Errorf("unexpected request #%d", idx+1)
+					w.WriteHeader(http.StatusInternalServerError)
+
+					return
+				}
+
+				_, _ = w.Write([]byte(tt.mockResponses[idx]))
+			}))
+			defer server.Close()
+
+			logger := log.New(io.Discard)
+			registry := tool.NewRegistry(nil, 0, nil, logger)
+			request := llm.ChatRequest{Model: "test-model", Messages: []llm.ChatMessage{{Role: llm.RoleUser, Content: "test"}}}
+
+			var retries int
+			onRetry := func(err error) {
+				if !errors.Is(err, schema.ErrValidation) {
+					t.Errorf("onRetry() err = %v, want %v", err, schema.ErrValidation)
+				}
+
+				retries++
+			}
+
+			got, err := RunSchemaLoop(context.Background(), registry, llm.NewOllama(server.URL), request, responseSchema, tt.retries, onRetry, ToolLoopOptions{}, logger)
+
+			if retries != tt.wantRetries {
+				t.Errorf("RunSchemaLoop() retries = %d, want %d", retries, tt.wantRetries)
+			}
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("RunSchemaLoop() err = %v, want %v", err, tt.err)
+				}
+
+				if !errors.Is(err, schema.ErrValidation) {
+					t.Errorf("RunSchemaLoop() err = %v, want wrapped %v", err, schema.ErrValidation)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("RunSchemaLoop() err = %v, want nil", err)
+			}
+
+			if content := got[len(got)-1].Content; content != tt.wantContent {
+				t.Errorf("RunSchemaLoop() content = %q, want %q", content, tt.wantContent)
+			}
+		})
+	}
+}
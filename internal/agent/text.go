@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"slices"
 	"strings"
 
 	"github.com/charmbracelet/log"
@@ -13,42 +12,6 @@ import (
 
 var ErrPipedInput = errors.New("data stream interrupted")
 
-// ReadTextFile reads a file and returns formatted content for the LLM.
-// Returns the formatted content and any error encountered.
-func ReadTextFile(path string) (string, error) {
-	info, err := os.Stat(path)
-	if err != nil {
-		return "", fmt.Errorf("%w: %w", ErrFileAccess, err)
-	}
-
-	if info.IsDir() {
-		return "", ErrIsDir
-	}
-
-	if info.Size() > maxFileSize {
-		return "", fmt.Errorf("%w (%d bytes)", ErrFileSize, info.Size())
-	}
-
-	content, err := os.ReadFile(path)
-	if err != nil {
-		return "", fmt.Errorf("%w: %w", ErrReadFile, err)
-	}
-
-	return fmt.Sprintf("[FILE: %s]\n%s", path, string(content)), nil
-}
-
-func isText(mediaType string) bool {
-	if strings.HasPrefix(mediaType, "text/") {
-		return true
-	}
-
-	if slices.Contains(textFileTypes, mediaType) {
-		return true
-	}
-
-	return false
-}
-
 // GetPipedInput detects, reads, and returns any input piped to the command.
 func GetPipedInput(file *os.File, logger *log.Logger) (string, error) {
 	fileInfo, err := file.Stat()
@@ -1,52 +1,254 @@
 package agent
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/google/uuid"
 	"github.com/theantichris/ghost/v3/internal/llm"
 	"github.com/theantichris/ghost/v3/internal/tool"
 )
 
-// RunToolLoop sends a request to the LLM and executes any tool calls needed.
-// Results are appended to messages and returned.
-// Returns early if no tools are registered.
-func RunToolLoop(ctx context.Context, registry tool.Registry, url, model string, messages []llm.ChatMessage, logger *log.Logger) ([]llm.ChatMessage, error) {
-	tools := registry.Definitions()
-	if len(tools) == 0 {
-		logger.Debug("no tools registered, exiting tool loop")
+var (
+	ErrToolLoopLimit    = errors.New("tool call limit reached")
+	ErrRepeatedToolCall = errors.New("tool call repeated")
+)
+
+// finalAnswerPrompt asks for an answer without tools once the tool call limit
+// is reached.
+const finalAnswerPrompt = "The tool call limit has been reached. Answer with the information you have without calling any more tools."
+
+// ToolLoopOptions holds the callbacks of the tool loop. Nil callbacks are
+// ignored, a nil Approve denies the calls that ask for approval.
+type ToolLoopOptions struct {
+	// OnChunk is called for each streamed chunk of content and thinking.
+	OnChunk func(llm.ChatMessage)
+	// OnWarning is called with ErrRepeatedToolCall when a call repeats an earlier
+	// one, with ErrToolLoopLimit when the registry's iteration limit is reached
+	// and a final answer is requested without tools, and with tool.ErrToolDenied
+	// when a call is denied.
+	OnWarning func(error)
+	// Approve is called for tools with tool.PolicyAsk and returns true to run the
+	// call.
+	Approve func(llm.ToolCall) bool
+	// OnToolCalls is called with each response holding tool calls and the
+	// results of its calls once they've run.
+	OnToolCalls func(llm.ChatMessage, []llm.ChatMessage)
+}
+
+// withDefaults returns the options with no-ops for the callbacks that are
+// called unconditionally.
+func (options ToolLoopOptions) withDefaults() ToolLoopOptions {
+	if options.OnChunk == nil {
+		options.OnChunk = func(llm.ChatMessage) {}
+	}
+
+	if options.OnWarning == nil {
+		options.OnWarning = func(error) {}
+	}
 
-		return messages, nil
+	if options.OnToolCalls == nil {
+		options.OnToolCalls = func(llm.ChatMessage, []llm.ChatMessage) {}
 	}
 
-	for {
-		resp, err := llm.Chat(ctx, url, model, messages, tools)
+	return options
+}
+
+// RunToolLoop streams a request to the LLM and executes any tool calls in the
+// response, repeating until the LLM responds without tool calls.
+// request holds the model, options, and message history, tools are set from the
+// registry. loopOptions holds the callbacks for the chunks, warnings, approvals,
+// and tool calls of the loop.
+// Tool calls, tool results, and the final response are appended to the message
+// history and returned.
+func RunToolLoop(ctx context.Context, registry tool.Registry, provider llm.Provider, request llm.ChatRequest, loopOptions ToolLoopOptions, logger *log.Logger) ([]llm.ChatMessage, error) {
+	loopOptions = loopOptions.withDefaults()
+
+	messages := request.Messages
+
+	request.Tools = registry.Definitions()
+	if len(request.Tools) == 0 {
+		logger.Debug("no tools registered, streaming without tools")
+	}
+
+	seen := map[string]bool{}
+
+	for iteration := 1; ; iteration++ {
+		request.Messages = messages
+
+		resp, err := provider.StreamChat(ctx, request, loopOptions.OnChunk)
 		if err != nil {
-			logger.Error("tool request failed", "error", err)
+			logger.Error("chat request failed", "error", err)
 
 			return messages, err
 		}
 
 		if len(resp.ToolCalls) == 0 {
-			// Final response not appended, caller streams it separately for UX
+			messages = append(messages, resp)
+
 			break
 		}
 
+		setToolCallIDs(resp.ToolCalls)
 		messages = append(messages, resp)
 
-		for _, toolCall := range resp.ToolCalls {
+		results := runToolCalls(ctx, registry, resp.ToolCalls, seen, loopOptions.OnWarning, loopOptions.Approve, logger)
+		messages = append(messages, results...)
+
+		loopOptions.OnToolCalls(resp, results)
+
+		if maxIterations := registry.Limits.MaxIterations; maxIterations > 0 && iteration >= maxIterations {
+			logger.Warn("tool call limit reached, requesting final answer", "iterations", iteration)
+			loopOptions.OnWarning(fmt.Errorf("%w: %d iterations, requesting final answer", ErrToolLoopLimit, iteration))
+
+			// The prompt is only sent with the final request, it isn't kept in the
+			// history.
+			request.Tools = nil
+			request.Messages = append(slices.Clone(messages), llm.ChatMessage{Role: llm.RoleSystem, Content: finalAnswerPrompt})
+
+			resp, err := provider.StreamChat(ctx, request, loopOptions.OnChunk)
+			if err != nil {
+				logger.Error("chat request failed", "error", err)
+
+				return messages, err
+			}
+
+			messages = append(messages, resp)
+
+			break
+		}
+	}
+
+	return messages, nil
+}
+
+// runToolCalls executes the toolCalls that haven't been seen before and are
+// allowed by the registry's policies, and returns the tool results in the order
+// of the calls, named after their tool and carrying their call's ID.
+// Repeated and denied calls aren't executed, they get a result telling the LLM
+// why. Approval is asked one call at a time before any call runs.
+func runToolCalls(ctx context.Context, registry tool.Registry, toolCalls []llm.ToolCall, seen map[string]bool, onWarning func(error), approve func(llm.ToolCall) bool, logger *log.Logger) []llm.ChatMessage {
+	results := make([]llm.ChatMessage, len(toolCalls))
+
+	var newCalls []llm.ToolCall
+	var newIndexes []int
+
+	for i, toolCall := range toolCalls {
+		key := toolCallKey(toolCall)
+
+		if seen[key] {
+			logger.Warn("repeated tool call", "name", toolCall.Function.Name)
+			onWarning(fmt.Errorf("%w: %s", ErrRepeatedToolCall, toolCall.Function.Name))
+
+			results[i] = llm.ChatMessage{
+				Role:    llm.RoleTool,
+				Content: fmt.Sprintf("error: %s was already called with these arguments, use the earlier result", toolCall.Function.Name),
+			}
+
+			continue
+		}
+
+		if err := CheckPolicy(registry, toolCall, approve); err != nil {
+			logger.Warn("tool call denied", "name", toolCall.Function.Name, "error", err)
+			onWarning(err)
+
+			results[i] = llm.ChatMessage{Role: llm.RoleTool, Content: fmt.Sprintf("error: %s", err.Error())}
+
+			continue
+		}
+
+		seen[key] = true
+		newCalls = append(newCalls, toolCall)
+		newIndexes = append(newIndexes, i)
+	}
+
+	for i, result := range executeToolCalls(ctx, registry, newCalls, logger) {
+		results[newIndexes[i]] = result
+	}
+
+	for i, toolCall := range toolCalls {
+		results[i].ToolName = toolCall.Function.Name
+		results[i].ToolCallID = toolCall.ID
+	}
+
+	return results
+}
+
+// setToolCallIDs gives the toolCalls the provider didn't set an ID for a
+// unique one so their results can be linked to them.
+func setToolCallIDs(toolCalls []llm.ToolCall) {
+	for i := range toolCalls {
+		if toolCalls[i].ID == "" {
+			toolCalls[i].ID = "call_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+		}
+	}
+}
+
+// CheckPolicy returns tool.ErrToolDenied if the registry's policy for
+// toolCall denies it or approve declines it.
+func CheckPolicy(registry tool.Registry, toolCall llm.ToolCall, approve func(llm.ToolCall) bool) error {
+	switch registry.CallPolicy(toolCall.Function.Name, toolCall.Function.Arguments) {
+	case tool.PolicyDeny:
+		return fmt.Errorf("%w by policy: %s", tool.ErrToolDenied, toolCall.Function.Name)
+
+	case tool.PolicyAsk:
+		if approve == nil || !approve(toolCall) {
+			return fmt.Errorf("%w by user: %s", tool.ErrToolDenied, toolCall.Function.Name)
+		}
+	}
+
+	return nil
+}
+
+// toolCallKey identifies a tool call by its name and arguments, ignoring
+// whitespace in the arguments.
+func toolCallKey(toolCall llm.ToolCall) string {
+	var args bytes.Buffer
+	if err := json.Compact(&args, toolCall.Function.Arguments); err != nil {
+		return toolCall.Function.Name + "\x00" + string(toolCall.Function.Arguments)
+	}
+
+	return toolCall.Function.Name + "\x00" + args.String()
+}
+
+// executeToolCalls runs toolCalls concurrently up to the registry's concurrency
+// limit and returns the tool results, with the time each call took, in the
+// order of the calls.
+// Failed calls return the error as their result so the LLM can recover.
+func executeToolCalls(ctx context.Context, registry tool.Registry, toolCalls []llm.ToolCall, logger *log.Logger) []llm.ChatMessage {
+	results := make([]llm.ChatMessage, len(toolCalls))
+	slots := make(chan struct{}, max(registry.Limits.Concurrency, 1))
+
+	var wg sync.WaitGroup
+
+	for i, toolCall := range toolCalls {
+		wg.Go(func() {
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
 			logger.Debug("executing tool", "name", toolCall.Function.Name)
 
+			start := time.Now()
+
 			result, err := registry.Execute(ctx, toolCall.Function.Name, toolCall.Function.Arguments)
 			if err != nil {
 				logger.Error("tool execution failed", "name", toolCall.Function.Name, "error", err)
 				result = fmt.Sprintf("error: %s", err.Error())
 			}
 
-			messages = append(messages, llm.ChatMessage{Role: llm.RoleTool, Content: result})
-		}
+			results[i] = llm.ChatMessage{Role: llm.RoleTool, Content: result, Duration: time.Since(start)}
+		})
 	}
 
-	return messages, nil
+	wg.Wait()
+
+	return results
 }
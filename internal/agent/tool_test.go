@@ -2,12 +2,17 @@ package agent
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/theantichris/ghost/v3/internal/llm"
@@ -22,23 +27,37 @@ func TestRunToolLoop(t *testing.T) {
 		mockStatusCodes []int
 		toolResult      string
 		toolErr         error
+		maxIterations   int
+		policy          tool.Policy
+		approve         bool
 		wantMsgCount    int
+		wantContent     string
+		wantToolResult  string // Substring of the first tool result.
+		wantWarnings    []error
+		wantNoTools     bool // The last request is sent without tools.
 		wantErr         bool
 		err             error
 	}{
 		{
-			name:         "returns messages unchanged when no tools registered",
+			name:         "streams response when no tools registered",
 			registerTool: false,
-			wantMsgCount: 1,
+			mockResponses: []string{
+				`{"message":{"role":"assistant","content":"Hello!"}}`,
+			},
+			mockStatusCodes: []int{http.StatusOK},
+			wantMsgCount:    2, // original + final response
+			wantContent:     "Hello!",
 		},
 		{
-			name:         "returns messages when LLM returns no tool calls",
+			name:         "returns final response when LLM returns no tool calls",
 			registerTool: true,
 			mockResponses: []string{
-				`{"message":{"role":"assistant","content":"Hello!"}}`,
+				`{"message":{"role":"assistant","content":"Hello"}}
+{"message":{"role":"assistant","content":"!"}}`,
 			},
 			mockStatusCodes: []int{http.StatusOK},
-			wantMsgCount:    1,
+			wantMsgCount:    2, // original + final response
+			wantContent:     "Hello!",
 		},
 		{
 			name:         "executes single tool call",
@@ -49,30 +68,63 @@ func TestRunToolLoop(t *testing.T) {
 			},
 			mockStatusCodes: []int{http.StatusOK, http.StatusOK},
 			toolResult:      "tool result",
-			wantMsgCount:    3, // original + assistant with tool call + tool result
+			wantMsgCount:    4, // original + assistant with tool call + tool result + final response
+			wantContent:     "Done!",
 		},
 		{
 			name:         "executes multiple tool calls in single response",
 			registerTool: true,
 			mockResponses: []string{
-				`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"mock_tool","arguments":{}}},{"function":{"name":"mock_tool","arguments":{}}}]}}`,
+				`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"mock_tool","arguments":{"q":"a"}}},{"function":{"name":"mock_tool","arguments":{"q":"b"}}}]}}`,
 				`{"message":{"role":"assistant","content":"Done!"}}`,
 			},
 			mockStatusCodes: []int{http.StatusOK, http.StatusOK},
 			toolResult:      "tool result",
-			wantMsgCount:    4, // original + assistant with tool calls + 2 tool results
+			wantMsgCount:    5, // original + assistant with tool calls + 2 tool results + final response
+			wantContent:     "Done!",
 		},
 		{
 			name:         "executes multi-iteration tool loop",
 			registerTool: true,
 			mockResponses: []string{
-				`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"mock_tool","arguments":{}}}]}}`,
-				`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"mock_tool","arguments":{}}}]}}`,
+				`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"mock_tool","arguments":{"q":"a"}}}]}}`,
+				`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"mock_tool","arguments":{"q":"b"}}}]}}`,
+				`{"message":{"role":"assistant","content":"Done!"}}`,
+			},
+			mockStatusCodes: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+			toolResult:      "tool result",
+			wantMsgCount:    6, // original + (assistant + tool result) * 2 + final response
+			wantContent:     "Done!",
+		},
+		{
+			name:         "skips repeated tool call",
+			registerTool: true,
+			mockResponses: []string{
+				`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"mock_tool","arguments":{"q":"a"}}}]}}`,
+				`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"mock_tool","arguments":{ "q": "a" }}}]}}`,
 				`{"message":{"role":"assistant","content":"Done!"}}`,
 			},
 			mockStatusCodes: []int{http.StatusOK, http.StatusOK, http.StatusOK},
 			toolResult:      "tool result",
-			wantMsgCount:    5, // original + (assistant + tool result) * 2
+			wantMsgCount:    6, // original + (assistant + tool result) * 2 + final response
+			wantContent:     "Done!",
+			wantWarnings:    []error{ErrRepeatedToolCall},
+		},
+		{
+			name:         "requests final answer without tools at iteration limit",
+			registerTool: true,
+			mockResponses: []string{
+				`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"mock_tool","arguments":{"q":"a"}}}]}}`,
+				`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"mock_tool","arguments":{"q":"b"}}}]}}`,
+				`{"message":{"role":"assistant","content":"Done!"}}`,
+			},
+			mockStatusCodes: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+			toolResult:      "tool result",
+			maxIterations:   2,
+			wantMsgCount:    6, // original + (assistant + tool result) * 2 + final response
+			wantContent:     "Done!",
+			wantWarnings:    []error{ErrToolLoopLimit},
+			wantNoTools:     true,
 		},
 		{
 			name:         "returns error when LLM request fails",
@@ -94,17 +146,69 @@ func TestRunToolLoop(t *testing.T) {
 			},
 			mockStatusCodes: []int{http.StatusOK, http.StatusOK},
 			toolErr:         errors.New("tool failed"),
-			wantMsgCount:    3, // original + assistant with tool call + error message
+			wantMsgCount:    4, // original + assistant with tool call + error message + final response
+			wantContent:     "Done!",
+		},
+		{
+			name:         "denies tool call by policy",
+			registerTool: true,
+			mockResponses: []string{
+				`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"mock_tool","arguments":{}}}]}}`,
+				`{"message":{"role":"assistant","content":"Done!"}}`,
+			},
+			mockStatusCodes: []int{http.StatusOK, http.StatusOK},
+			policy:          tool.PolicyDeny,
+			wantToolResult:  "denied by policy",
+			wantMsgCount:    4, // original + assistant with tool call + denial + final response
+			wantContent:     "Done!",
+			wantWarnings:    []error{tool.ErrToolDenied},
+		},
+		{
+			name:         "denies tool call the user declines",
+			registerTool: true,
+			mockResponses: []string{
+				`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"mock_tool","arguments":{}}}]}}`,
+				`{"message":{"role":"assistant","content":"Done!"}}`,
+			},
+			mockStatusCodes: []int{http.StatusOK, http.StatusOK},
+			policy:          tool.PolicyAsk,
+			wantToolResult:  "denied by user",
+			wantMsgCount:    4, // original + assistant with tool call + denial + final response
+			wantContent:     "Done!",
+			wantWarnings:    []error{tool.ErrToolDenied},
+		},
+		{
+			name:         "executes tool call the user approves",
+			registerTool: true,
+			mockResponses: []string{
+				`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"mock_tool","arguments":{}}}]}}`,
+				`{"message":{"role":"assistant","content":"Done!"}}`,
+			},
+			mockStatusCodes: []int{http.StatusOK, http.StatusOK},
+			toolResult:      "tool result",
+			policy:          tool.PolicyAsk,
+			approve:         true,
+			wantToolResult:  "tool result",
+			wantMsgCount:    4, // original + assistant with tool call + tool result + final response
+			wantContent:     "Done!",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			var callCount atomic.Int32
+			var lastRequest struct {
+				Tools []json.RawMessage `json:"tools"`
+			}
 
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				idx := int(callCount.Add(1)) - 1
 
+				lastRequest.Tools = nil
+				if err := json.NewDecoder(r.Body).Decode(&lastRequest); err != nil {
+					t.Errorf("failed to decode request: %v", err)
+				}
+
 				if idx >= len(tt.mockResponses) {
 					t.Errorf("unexpected request #%d", idx+1)
 					w.WriteHeader(http.StatusInternalServerError)
@@ -118,7 +222,12 @@ func TestRunToolLoop(t *testing.T) {
 			defer server.Close()
 
 			logger := log.New(io.Discard)
-			registry := tool.NewRegistry("", 0, logger)
+			registry := tool.NewRegistry(nil, 0, nil, logger)
+			registry.Limits.MaxIterations = tt.maxIterations
+
+			if tt.policy != "" {
+				registry.Policies = map[string]tool.Policy{"mock_tool": tt.policy}
+			}
 
 			if tt.registerTool {
 				registry.Register(tool.MockTool{
@@ -132,7 +241,30 @@ func TestRunToolLoop(t *testing.T) {
 				{Role: llm.RoleUser, Content: "test"},
 			}
 
-			got, err := RunToolLoop(context.Background(), registry, server.URL, "test-model", messages, logger)
+			var content strings.Builder
+			onChunk := func(chunk llm.ChatMessage) {
+				content.WriteString(chunk.Content)
+			}
+
+			var warnings []error
+			onWarning := func(err error) {
+				warnings = append(warnings, err)
+			}
+
+			approve := func(llm.ToolCall) bool {
+				return tt.approve
+			}
+
+			var toolCallRounds int
+			onToolCalls := func(call llm.ChatMessage, results []llm.ChatMessage) {
+				toolCallRounds++
+
+				if len(results) != len(call.ToolCalls) {
+					t.Errorf("RunToolLoop() tool call results = %d, want %d", len(results), len(call.ToolCalls))
+				}
+			}
+
+			got, err := RunToolLoop(context.Background(), registry, llm.NewOllama(server.URL), llm.ChatRequest{Model: "test-model", Messages: messages}, ToolLoopOptions{OnChunk: onChunk, OnWarning: onWarning, Approve: approve, OnToolCalls: onToolCalls}, logger)
 
 			if tt.wantErr {
 				if err == nil {
@@ -153,6 +285,157 @@ func TestRunToolLoop(t *testing.T) {
 			if len(got) != tt.wantMsgCount {
 				t.Errorf("RunToolLoop() message count = %d, want %d", len(got), tt.wantMsgCount)
 			}
+
+			if content.String() != tt.wantContent {
+				t.Errorf("RunToolLoop() streamed content = %q, want %q", content.String(), tt.wantContent)
+			}
+
+			last := got[len(got)-1]
+			if last.Role != llm.RoleAssistant || last.Content != tt.wantContent {
+				t.Errorf("RunToolLoop() last message = %+v, want assistant with %q", last, tt.wantContent)
+			}
+
+			if len(warnings) != len(tt.wantWarnings) {
+				t.Fatalf("RunToolLoop() warnings = %v, want %v", warnings, tt.wantWarnings)
+			}
+
+			for i, warning := range warnings {
+				if !errors.Is(warning, tt.wantWarnings[i]) {
+					t.Errorf("RunToolLoop() warning = %v, want %v", warning, tt.wantWarnings[i])
+				}
+			}
+
+			if tt.wantToolResult != "" {
+				index := slices.IndexFunc(got, func(message llm.ChatMessage) bool { return message.Role == llm.RoleTool })
+				if index < 0 || !strings.Contains(got[index].Content, tt.wantToolResult) {
+					t.Errorf("RunToolLoop() tool result = %+v, want %q", got, tt.wantToolResult)
+				}
+			}
+
+			if tt.wantNoTools && len(lastRequest.Tools) != 0 {
+				t.Errorf("RunToolLoop() last request tools = %d, want 0", len(lastRequest.Tools))
+			}
+
+			wantRounds := 0
+			for i, message := range got {
+				if message.Role == llm.RoleSystem {
+					t.Errorf("RunToolLoop() history has system message %q, want none", message.Content)
+				}
+
+				if len(message.ToolCalls) > 0 {
+					wantRounds++
+				}
+
+				// Results follow their calls and are linked to them.
+				for j, toolCall := range message.ToolCalls {
+					result := got[i+1+j]
+					if toolCall.ID == "" || result.ToolCallID != toolCall.ID || result.ToolName != toolCall.Function.Name {
+						t.Errorf("RunToolLoop() result = %+v, want linked to call %+v", result, toolCall)
+					}
+				}
+			}
+
+			if toolCallRounds != wantRounds {
+				t.Errorf("RunToolLoop() onToolCalls calls = %d, want %d", toolCallRounds, wantRounds)
+			}
+		})
+	}
+}
+
+func TestRunToolLoop_NilCallbacks(t *testing.T) {
+	responses := []string{
+		`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"mock_tool","arguments":{"q":"a"}}}]}}`,
+		`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"mock_tool","arguments":{"q":"a"}}}]}}`,
+		`{"message":{"role":"assistant","content":"Done!"}}`,
+	}
+
+	var callCount atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(responses[min(int(callCount.Add(1))-1, len(responses)-1)]))
+	}))
+	defer server.Close()
+
+	logger := log.New(io.Discard)
+	registry := tool.NewRegistry(nil, 0, nil, logger)
+	registry.Limits.MaxIterations = 2
+	registry.Register(tool.MockTool{Name: "mock_tool", Result: "tool result"})
+
+	// The repeated call and the iteration limit both warn.
+	got, err := RunToolLoop(context.Background(), registry, llm.NewOllama(server.URL), llm.ChatRequest{Model: "test-model", Messages: []llm.ChatMessage{{Role: llm.RoleUser, Content: "test"}}}, ToolLoopOptions{}, logger)
+	if err != nil {
+		t.Fatalf("RunToolLoop() err = %v, want nil", err)
+	}
+
+	if last := got[len(got)-1]; last.Content != "Done!" {
+		t.Errorf("RunToolLoop() last message = %+v, want %q", last, "Done!")
+	}
+}
+
+// countingTool records how many calls run at once.
+type countingTool struct {
+	tool.MockTool
+	running *atomic.Int32
+	peak    *atomic.Int32
+}
+
+func (t countingTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	running := t.running.Add(1)
+	defer t.running.Add(-1)
+
+	for peak := t.peak.Load(); running > peak && !t.peak.CompareAndSwap(peak, running); peak = t.peak.Load() {
+	}
+
+	return t.MockTool.Execute(ctx, args)
+}
+
+func TestExecuteToolCalls(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency int
+		wantPeak    int32
+	}{
+		{name: "runs one call at a time by default", concurrency: 0, wantPeak: 1},
+		{name: "runs calls up to the limit", concurrency: 2, wantPeak: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, peak atomic.Int32
+
+			registry := tool.NewRegistry(nil, 0, nil, log.New(io.Discard))
+			registry.Limits = tool.Limits{Concurrency: tt.concurrency}
+
+			// Earlier calls take longer so results finish out of order.
+			var toolCalls []llm.ToolCall
+			for i, delay := range []time.Duration{40, 30, 20, 10} {
+				name := fmt.Sprintf("tool_%d", i)
+				registry.Register(countingTool{
+					MockTool: tool.MockTool{Name: name, Result: name, Delay: delay * time.Millisecond},
+					running:  &running,
+					peak:     &peak,
+				})
+
+				var toolCall llm.ToolCall
+				toolCall.Function.Name = name
+				toolCalls = append(toolCalls, toolCall)
+			}
+
+			results := executeToolCalls(context.Background(), registry, toolCalls, log.New(io.Discard))
+
+			for i, result := range results {
+				if want := fmt.Sprintf("tool_%d", i); result.Content != want || result.Role != llm.RoleTool {
+					t.Errorf("results[%d] = %s %q, want tool %q", i, result.Role, result.Content, want)
+				}
+
+				if result.Duration < 10*time.Millisecond {
+					t.Errorf("results[%d] duration = %s, want the time the call took", i, result.Duration)
+				}
+			}
+
+			if got := peak.Load(); got != tt.wantPeak {
+				t.Errorf("peak concurrency = %d, want %d", got, tt.wantPeak)
+			}
 		})
 	}
 }
@@ -0,0 +1,133 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+type Type string
+
+const (
+	maxSize = 10 * 1024 * 1024 // 10MB
+
+	TypeText  Type = "text"
+	TypeImage Type = "image"
+	TypeDir   Type = "dir"
+)
+
+var (
+	ErrAccess          = errors.New("failed to access file")
+	ErrIsDir           = errors.New("path is a directory, not a file")
+	ErrSize            = errors.New("file exceeds 10MB limit")
+	ErrRead            = errors.New("failed to read file")
+	ErrTypeUnsupported = errors.New("file type unsupported")
+
+	textFileTypes = []string{
+		"application/javascript",
+		"application/json",
+		"application/x-sh",
+		"application/xml",
+	}
+
+	imageFileTypes = []string{
+		"image/png",
+		"image/jpeg",
+		"image/webp",
+	}
+)
+
+// DetectType returns a Type based on files mime type and path.
+// Returns TypeDir if path is a directory.
+// Returns ErrTypeUnsupported if the file type is not supported.
+func DetectType(path string) (Type, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrAccess, err)
+	}
+
+	if info.IsDir() {
+		return TypeDir, nil
+	}
+
+	if info.Size() > maxSize {
+		return "", fmt.Errorf("%w (%d bytes)", ErrSize, info.Size())
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrRead, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	buffer := make([]byte, 512)
+	n, err := file.Read(buffer)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrRead, err)
+	}
+
+	mime := http.DetectContentType(buffer[:n])
+	mediaType := strings.SplitN(mime, ";", 2)[0]
+
+	if isImage(mediaType, path) {
+		return TypeImage, nil
+	}
+
+	if isText(mediaType) {
+		return TypeText, nil
+	}
+
+	return "", ErrTypeUnsupported
+}
+
+// ReadText reads a file and returns formatted content for the LLM.
+// Returns the formatted content and any error encountered.
+func ReadText(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrAccess, err)
+	}
+
+	if info.IsDir() {
+		return "", ErrIsDir
+	}
+
+	if info.Size() > maxSize {
+		return "", fmt.Errorf("%w (%d bytes)", ErrSize, info.Size())
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrRead, err)
+	}
+
+	return fmt.Sprintf("[FILE: %s]\n%s", path, string(content)), nil
+}
+
+func isText(mediaType string) bool {
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+
+	if slices.Contains(textFileTypes, mediaType) {
+		return true
+	}
+
+	return false
+}
+
+func isImage(mediaType, path string) bool {
+	if slices.Contains(imageFileTypes, mediaType) {
+		return true
+	}
+
+	if mediaType == "text/xml" && filepath.Ext(path) == ".svg" {
+		return true
+	}
+
+	return false
+}
@@ -1,4 +1,4 @@
-package agent
+package file
 
 import (
 	"errors"
@@ -7,7 +7,7 @@ import (
 	"testing"
 )
 
-func TestDetectFileType(t *testing.T) {
+func TestDetectType(t *testing.T) {
 	// PNG magic bytes: 89 50 4E 47 0D 0A 1A 0A
 	pngBytes := []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A, 0x00, 0x00, 0x00, 0x0D}
 
@@ -51,28 +51,28 @@ func TestDetectFileType(t *testing.T) {
 		filename string
 		content  []byte
 		isDir    bool
-		want     FileType
+		want     Type
 		wantErr  error
 	}{
 		{
 			name:     "PNG image",
 			filename: "test.png",
 			content:  pngBytes,
-			want:     FileTypeImage,
+			want:     TypeImage,
 			wantErr:  nil,
 		},
 		{
 			name:     "JPEG image",
 			filename: "test.jpg",
 			content:  jpegBytes,
-			want:     FileTypeImage,
+			want:     TypeImage,
 			wantErr:  nil,
 		},
 		{
 			name:     "WebP image",
 			filename: "test.webp",
 			content:  webpBytes,
-			want:     FileTypeImage,
+			want:     TypeImage,
 			wantErr:  nil,
 		},
 		{
@@ -80,48 +80,48 @@ func TestDetectFileType(t *testing.T) {
 			filename: "test.gif",
 			content:  gifBytes,
 			want:     "",
-			wantErr:  ErrFileTypeUnsupported,
+			wantErr:  ErrTypeUnsupported,
 		},
 		{
 			name:     "plain text file",
 			filename: "test.txt",
 			content:  textBytes,
-			want:     FileTypeText,
+			want:     TypeText,
 			wantErr:  nil,
 		},
 		{
 			name:     "JSON file",
 			filename: "test.json",
 			content:  jsonBytes,
-			want:     FileTypeText,
+			want:     TypeText,
 			wantErr:  nil,
 		},
 		{
 			name:     "XML file",
 			filename: "test.xml",
 			content:  xmlBytes,
-			want:     FileTypeText,
+			want:     TypeText,
 			wantErr:  nil,
 		},
 		{
 			name:     "SVG file routes to image",
 			filename: "test.svg",
 			content:  svgBytes,
-			want:     FileTypeImage,
+			want:     TypeImage,
 			wantErr:  nil,
 		},
 		{
 			name:     "shell script",
 			filename: "test.sh",
 			content:  shBytes,
-			want:     FileTypeText,
+			want:     TypeText,
 			wantErr:  nil,
 		},
 		{
 			name:     "JavaScript file",
 			filename: "test.js",
 			content:  jsBytes,
-			want:     FileTypeText,
+			want:     TypeText,
 			wantErr:  nil,
 		},
 		{
@@ -129,13 +129,13 @@ func TestDetectFileType(t *testing.T) {
 			filename: "test.exe",
 			content:  elfBytes,
 			want:     "",
-			wantErr:  ErrFileTypeUnsupported,
+			wantErr:  ErrTypeUnsupported,
 		},
 		{
-			name:     "directory returns FileTypeDir",
+			name:     "directory returns TypeDir",
 			filename: "testdir",
 			isDir:    true,
-			want:     FileTypeDir,
+			want:     TypeDir,
 			wantErr:  nil,
 		},
 	}
@@ -155,44 +155,44 @@ func TestDetectFileType(t *testing.T) {
 				}
 			}
 
-			got, err := DetectFileType(path)
+			got, err := DetectType(path)
 
 			if tt.wantErr != nil {
 				if err == nil {
-					t.Fatalf("DetectFileType() error = nil, want %v", tt.wantErr)
+					t.Fatalf("DetectType() error = nil, want %v", tt.wantErr)
 				}
 
 				if !errors.Is(err, tt.wantErr) {
-					t.Errorf("DetectFileType() error = %v, want %v", err, tt.wantErr)
+					t.Errorf("DetectType() error = %v, want %v", err, tt.wantErr)
 				}
 
 				return
 			}
 
 			if err != nil {
-				t.Fatalf("DetectFileType() unexpected error = %v", err)
+				t.Fatalf("DetectType() unexpected error = %v", err)
 			}
 
 			if got != tt.want {
-				t.Errorf("DetectFileType() = %v, want %v", got, tt.want)
+				t.Errorf("DetectType() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func TestDetectFileType_NonExistentFile(t *testing.T) {
-	_, err := DetectFileType("/nonexistent/path/to/file.txt")
+func TestDetectType_NonExistentFile(t *testing.T) {
+	_, err := DetectType("/nonexistent/path/to/file.txt")
 
 	if err == nil {
-		t.Fatal("DetectFileType() error = nil, want error")
+		t.Fatal("DetectType() error = nil, want error")
 	}
 
-	if !errors.Is(err, ErrFileAccess) {
-		t.Errorf("DetectFileType() error = %v, want %v", err, ErrFileAccess)
+	if !errors.Is(err, ErrAccess) {
+		t.Errorf("DetectType() error = %v, want %v", err, ErrAccess)
 	}
 }
 
-func TestDetectFileType_FileTooLarge(t *testing.T) {
+func TestDetectType_FileTooLarge(t *testing.T) {
 	tmpDir := t.TempDir()
 	path := filepath.Join(tmpDir, "large.txt")
 
@@ -209,14 +209,14 @@ func TestDetectFileType_FileTooLarge(t *testing.T) {
 	}
 	defer func() { _ = f.Close() }()
 
-	_, err = DetectFileType(path)
+	_, err = DetectType(path)
 
 	if err == nil {
-		t.Fatal("DetectFileType() error = nil, want error")
+		t.Fatal("DetectType() error = nil, want error")
 	}
 
-	if !errors.Is(err, ErrFileSize) {
-		t.Errorf("DetectFileType() error = %v, want %v", err, ErrFileSize)
+	if !errors.Is(err, ErrSize) {
+		t.Errorf("DetectType() error = %v, want %v", err, ErrSize)
 	}
 }
 
@@ -244,3 +244,29 @@ func TestIsImage(t *testing.T) {
 		})
 	}
 }
+
+func TestIsText(t *testing.T) {
+	tests := []struct {
+		name      string
+		mediaType string
+		want      bool
+	}{
+		{"text/plain", "text/plain", true},
+		{"text/html", "text/html", true},
+		{"text/css", "text/css", true},
+		{"application/json", "application/json", true},
+		{"application/xml", "application/xml", true},
+		{"application/javascript", "application/javascript", true},
+		{"application/x-sh", "application/x-sh", true},
+		{"image/png not text", "image/png", false},
+		{"application/octet-stream not text", "application/octet-stream", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isText(tt.mediaType); got != tt.want {
+				t.Errorf("isText(%q) = %v, want %v", tt.mediaType, got, tt.want)
+			}
+		})
+	}
+}
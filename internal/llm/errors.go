@@ -0,0 +1,126 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+)
+
+var (
+	ErrConnectionRefused = errors.New("neural network offline: connection refused")
+	ErrConnectionReset   = errors.New("neural link severed mid-transmission")
+	ErrTimeout           = errors.New("neural network timed out")
+	ErrServerUnavailable = errors.New("neural network unavailable")
+	ErrOutOfMemory       = errors.New("neural network out of memory")
+	ErrContextOverflow   = errors.New("memory buffer overflow: context window exceeded")
+)
+
+// StatusError is returned when the server responds with an error.
+// StatusCode is the HTTP status, an error sent mid-stream keeps the 200 status.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error returns the status and the server's message.
+func (err *StatusError) Error() string {
+	if err.Message == "" {
+		return fmt.Sprintf("status %d", err.StatusCode)
+	}
+
+	return fmt.Sprintf("status %d: %s", err.StatusCode, err.Message)
+}
+
+// IsTransient returns true if err may succeed when the request is retried.
+func IsTransient(err error) bool {
+	return errors.Is(err, ErrConnectionReset) || errors.Is(err, ErrServerUnavailable)
+}
+
+// checkStatus returns a StatusError if the response status isn't 200.
+// Reads the message from an Ollama or OpenAI error body.
+func checkStatus(response *http.Response) error {
+	if response.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var body struct {
+		Error json.RawMessage `json:"error"`
+	}
+
+	statusErr := &StatusError{StatusCode: response.StatusCode}
+
+	if err := json.NewDecoder(response.Body).Decode(&body); err != nil || len(body.Error) == 0 {
+		return statusErr
+	}
+
+	var openAIError struct {
+		Message string `json:"message"`
+	}
+
+	if err := json.Unmarshal(body.Error, &statusErr.Message); err != nil {
+		if err := json.Unmarshal(body.Error, &openAIError); err == nil {
+			statusErr.Message = openAIError.Message
+		}
+	}
+
+	return statusErr
+}
+
+// classifyError maps a request error to the typed error for its cause.
+// Returns nil if err is nil.
+func classifyError(err error, model string) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, ErrDecodeChunk) || errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return fmt.Errorf("%w: %w", ErrConnectionRefused, err)
+
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return fmt.Errorf("%w: %w", ErrConnectionReset, err)
+	}
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return fmt.Errorf("%w: %w", ErrUnexpectedStatus, err)
+	}
+
+	message := strings.ToLower(statusErr.Message)
+
+	switch {
+	case strings.Contains(message, "does not support tools"):
+		return fmt.Errorf("%w: %s", ErrToolSupport, model)
+
+	case strings.Contains(message, "context length"), strings.Contains(message, "context window"):
+		return fmt.Errorf("%w: %w", ErrContextOverflow, err)
+
+	case strings.Contains(message, "memory"):
+		return fmt.Errorf("%w: %w", ErrOutOfMemory, err)
+
+	case statusErr.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrModelNotFound, model)
+
+	case statusErr.StatusCode == http.StatusServiceUnavailable,
+		statusErr.StatusCode == http.StatusBadGateway,
+		statusErr.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %w", ErrServerUnavailable, err)
+	}
+
+	return fmt.Errorf("%w: %w", ErrUnexpectedStatus, err)
+}
@@ -0,0 +1,107 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockStatusCode int
+		mockResponse   string
+		err            error
+	}{
+		{
+			name:           "classifies missing model",
+			mockStatusCode: http.StatusNotFound,
+			mockResponse:   `{"error":"model 'llama3' not found"}`,
+			err:            ErrModelNotFound,
+		},
+		{
+			name:           "classifies out of memory",
+			mockStatusCode: http.StatusInternalServerError,
+			mockResponse:   `{"error":"model requires more system memory (12 GiB) than is available (8 GiB)"}`,
+			err:            ErrOutOfMemory,
+		},
+		{
+			name:           "classifies context overflow",
+			mockStatusCode: http.StatusBadRequest,
+			mockResponse:   `{"error":{"message":"This model's maximum context length is 8192 tokens"}}`,
+			err:            ErrContextOverflow,
+		},
+		{
+			name:           "classifies tool support",
+			mockStatusCode: http.StatusBadRequest,
+			mockResponse:   `{"error":"registry.ollama.ai/library/gemma:latest does not support tools"}`,
+			err:            ErrToolSupport,
+		},
+		{
+			name:           "classifies loading model as unavailable",
+			mockStatusCode: http.StatusServiceUnavailable,
+			mockResponse:   `{"error":"server busy, please try again"}`,
+			err:            ErrServerUnavailable,
+		},
+		{
+			name:           "classifies other status as unexpected",
+			mockStatusCode: http.StatusInternalServerError,
+			mockResponse:   `internal server error`,
+			err:            ErrUnexpectedStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.mockStatusCode)
+				_, _ = w.Write([]byte(tt.mockResponse))
+			}))
+			defer server.Close()
+
+			_, err := NewOllama(server.URL).Chat(context.Background(), ChatRequest{Model: "llama3"})
+
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Chat() err = %v, want %v", err, tt.err)
+			}
+
+			var statusErr *StatusError
+			if errors.Is(tt.err, ErrUnexpectedStatus) && !errors.As(err, &statusErr) {
+				t.Errorf("Chat() err = %v, want StatusError", err)
+			}
+		})
+	}
+}
+
+func TestClassifyError_ConnectionRefused(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	_, err := NewOllama(server.URL).Chat(context.Background(), ChatRequest{Model: "llama3"})
+
+	if !errors.Is(err, ErrConnectionRefused) {
+		t.Errorf("Chat() err = %v, want %v", err, ErrConnectionRefused)
+	}
+}
+
+func TestClassifyError_Timeout(t *testing.T) {
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := NewOllama(server.URL).Chat(ctx, ChatRequest{Model: "llama3"})
+
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Chat() err = %v, want %v", err, ErrTimeout)
+	}
+}
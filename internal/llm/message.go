@@ -1,5 +1,7 @@
 package llm
 
+import "time"
+
 // Role represents the author of a message in the chat history.
 type Role string
 
@@ -12,10 +14,15 @@ const (
 
 // ChatMessage holds a single message in the chat history.
 type ChatMessage struct {
-	Role      Role       `json:"role"`
-	Content   string     `json:"content"`
-	Images    []string   `json:"images,omitempty"`
-	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	Role       Role          `json:"role"`
+	Content    string        `json:"content"`
+	Thinking   string        `json:"thinking,omitempty"`
+	Images     []string      `json:"images,omitempty"`
+	ToolCalls  []ToolCall    `json:"tool_calls,omitempty"`
+	ToolName   string        `json:"tool_name,omitempty"`    // Name of the tool on tool results
+	ToolCallID string        `json:"tool_call_id,omitempty"` // ID of the call on tool results
+	Metrics    *Metrics      `json:"-"`                      // Set on responses when the provider reports usage
+	Duration   time.Duration `json:"-"`                      // Set on tool results with the time the call took
 }
 
 // NewMessageHistory takes system and format prompts and returns an initial message
@@ -0,0 +1,47 @@
+package llm
+
+import (
+	"fmt"
+	"time"
+)
+
+// Metrics holds the token usage and timing of a response.
+// Durations are in nanoseconds when encoded, matching the Ollama API.
+type Metrics struct {
+	PromptEvalCount int           `json:"prompt_eval_count"` // Tokens in the prompt
+	EvalCount       int           `json:"eval_count"`        // Tokens in the response
+	TotalDuration   time.Duration `json:"total_duration"`
+	EvalDuration    time.Duration `json:"eval_duration"` // Time spent generating the response, zero if unknown
+}
+
+// TokensPerSecond returns the response generation rate.
+// Falls back to the total duration when the generation time is unknown.
+func (metrics Metrics) TokensPerSecond() float64 {
+	duration := metrics.EvalDuration
+	if duration == 0 {
+		duration = metrics.TotalDuration
+	}
+
+	if duration <= 0 {
+		return 0
+	}
+
+	return float64(metrics.EvalCount) / duration.Seconds()
+}
+
+// ContextUsed returns the number of tokens of the context window used by the
+// request and response.
+func (metrics Metrics) ContextUsed() int {
+	return metrics.PromptEvalCount + metrics.EvalCount
+}
+
+// String returns the metrics formatted for display.
+func (metrics Metrics) String() string {
+	return fmt.Sprintf(
+		"prompt %d tokens, response %d tokens, %.1f tok/s, %s",
+		metrics.PromptEvalCount,
+		metrics.EvalCount,
+		metrics.TokensPerSecond(),
+		metrics.TotalDuration.Round(time.Millisecond),
+	)
+}
@@ -0,0 +1,54 @@
+package llm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	tests := []struct {
+		name                string
+		metrics             Metrics
+		wantTokensPerSecond float64
+		wantContextUsed     int
+		wantString          string
+	}{
+		{
+			name:                "uses eval duration",
+			metrics:             Metrics{PromptEvalCount: 100, EvalCount: 50, TotalDuration: 5 * time.Second, EvalDuration: 2 * time.Second},
+			wantTokensPerSecond: 25,
+			wantContextUsed:     150,
+			wantString:          "prompt 100 tokens, response 50 tokens, 25.0 tok/s, 5s",
+		},
+		{
+			name:                "falls back to total duration",
+			metrics:             Metrics{PromptEvalCount: 10, EvalCount: 50, TotalDuration: 5 * time.Second},
+			wantTokensPerSecond: 10,
+			wantContextUsed:     60,
+			wantString:          "prompt 10 tokens, response 50 tokens, 10.0 tok/s, 5s",
+		},
+		{
+			name:                "returns zero rate without durations",
+			metrics:             Metrics{EvalCount: 50},
+			wantTokensPerSecond: 0,
+			wantContextUsed:     50,
+			wantString:          "prompt 0 tokens, response 50 tokens, 0.0 tok/s, 0s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.metrics.TokensPerSecond(); got != tt.wantTokensPerSecond {
+				t.Errorf("TokensPerSecond() = %v, want %v", got, tt.wantTokensPerSecond)
+			}
+
+			if got := tt.metrics.ContextUsed(); got != tt.wantContextUsed {
+				t.Errorf("ContextUsed() = %v, want %v", got, tt.wantContextUsed)
+			}
+
+			if got := tt.metrics.String(); got != tt.wantString {
+				t.Errorf("String() = %q, want %q", got, tt.wantString)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/carlmjohnson/requests"
+)
+
+// Capabilities reported by Ollama for a model.
+const (
+	CapabilityCompletion = "completion"
+	CapabilityTools      = "tools"
+	CapabilityVision     = "vision"
+	CapabilityThinking   = "thinking"
+)
+
+var ErrModelDiscovery = errors.New("provider does not support model discovery")
+
+// ModelCatalog is implemented by providers that can list the installed models
+// and report what they can do.
+type ModelCatalog interface {
+	// ListModels returns the installed models.
+	ListModels(ctx context.Context) ([]ModelInfo, error)
+
+	// ShowModel returns the details and capabilities of the model name.
+	ShowModel(ctx context.Context, name string) (ModelInfo, error)
+}
+
+// ModelInfo holds the details and capabilities of a model.
+type ModelInfo struct {
+	Name          string    `json:"name"`
+	Size          int64     `json:"size,omitempty"` // Size on disk in bytes
+	ModifiedAt    time.Time `json:"modified_at"`
+	Family        string    `json:"family,omitempty"`
+	ParameterSize string    `json:"parameter_size,omitempty"`
+	Quantization  string    `json:"quantization,omitempty"`
+	Capabilities  []string  `json:"capabilities,omitempty"`
+	ContextLength int       `json:"context_length,omitempty"`
+}
+
+// HasCapability returns true if the model reports capability.
+func (info ModelInfo) HasCapability(capability string) bool {
+	return slices.Contains(info.Capabilities, capability)
+}
+
+// modelDetails holds the details returned by the tags and show endpoints.
+type modelDetails struct {
+	Family            string `json:"family"`
+	ParameterSize     string `json:"parameter_size"`
+	QuantizationLevel string `json:"quantization_level"`
+}
+
+// tagsResponse holds the response from the tags endpoint.
+type tagsResponse struct {
+	Models []struct {
+		Name       string       `json:"name"`
+		Size       int64        `json:"size"`
+		ModifiedAt time.Time    `json:"modified_at"`
+		Details    modelDetails `json:"details"`
+	} `json:"models"`
+}
+
+// showResponse holds the response from the show endpoint.
+// The context length is in ModelInfo under "<architecture>.context_length".
+type showResponse struct {
+	Details      modelDetails   `json:"details"`
+	ModelInfo    map[string]any `json:"model_info"`
+	Capabilities []string       `json:"capabilities"`
+	ModifiedAt   time.Time      `json:"modified_at"`
+}
+
+// ListModels returns the models installed on the Ollama server.
+func (ollama Ollama) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	var response tagsResponse
+
+	err := requests.
+		URL(ollama.URL + "/tags").
+		AddValidator(checkStatus).
+		ToJSON(&response).
+		Fetch(ctx)
+
+	if err != nil {
+		return nil, classifyError(err, "")
+	}
+
+	models := make([]ModelInfo, 0, len(response.Models))
+	for _, model := range response.Models {
+		models = append(models, ModelInfo{
+			Name:          model.Name,
+			Size:          model.Size,
+			ModifiedAt:    model.ModifiedAt,
+			Family:        model.Details.Family,
+			ParameterSize: model.Details.ParameterSize,
+			Quantization:  model.Details.QuantizationLevel,
+		})
+	}
+
+	return models, nil
+}
+
+// ShowModel returns the details and capabilities of the model name.
+// Returns ErrModelNotFound if the model isn't installed.
+func (ollama Ollama) ShowModel(ctx context.Context, name string) (ModelInfo, error) {
+	var response showResponse
+
+	err := requests.
+		URL(ollama.URL + "/show").
+		BodyJSON(map[string]string{"model": name}).
+		AddValidator(checkStatus).
+		ToJSON(&response).
+		Fetch(ctx)
+
+	if err != nil {
+		return ModelInfo{}, classifyError(err, name)
+	}
+
+	info := ModelInfo{
+		Name:          name,
+		ModifiedAt:    response.ModifiedAt,
+		Family:        response.Details.Family,
+		ParameterSize: response.Details.ParameterSize,
+		Quantization:  response.Details.QuantizationLevel,
+		Capabilities:  response.Capabilities,
+	}
+
+	for key, value := range response.ModelInfo {
+		if length, ok := value.(float64); ok && strings.HasSuffix(key, ".context_length") {
+			info.ContextLength = int(length)
+		}
+	}
+
+	return info, nil
+}
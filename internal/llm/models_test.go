@@ -0,0 +1,151 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestListModels(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockStatusCode int
+		mockResponse   string
+		want           []ModelInfo
+		wantErr        bool
+		err            error
+	}{
+		{
+			name:           "lists models",
+			mockStatusCode: http.StatusOK,
+			mockResponse:   `{"models":[{"name":"llama3:latest","size":4661224676,"details":{"family":"llama","parameter_size":"8.0B","quantization_level":"Q4_0"}}]}`,
+			want: []ModelInfo{
+				{Name: "llama3:latest", Size: 4661224676, Family: "llama", ParameterSize: "8.0B", Quantization: "Q4_0"},
+			},
+		},
+		{
+			name:           "returns empty list with no models",
+			mockStatusCode: http.StatusOK,
+			mockResponse:   `{"models":[]}`,
+			want:           []ModelInfo{},
+		},
+		{
+			name:           "returns error for unexpected status",
+			mockStatusCode: http.StatusInternalServerError,
+			mockResponse:   `{"error":"internal server error"}`,
+			wantErr:        true,
+			err:            ErrUnexpectedStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/tags" {
+					t.Errorf("unexpected path: %s", r.URL.Path)
+				}
+
+				w.WriteHeader(tt.mockStatusCode)
+				_, _ = w.Write([]byte(tt.mockResponse))
+			}))
+			defer server.Close()
+
+			got, err := NewOllama(server.URL).ListModels(context.Background())
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("ListModels() err = %v, want %v", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ListModels() err = %v, want nil", err)
+			}
+
+			if !cmp.Equal(got, tt.want, cmpopts.EquateEmpty()) {
+				t.Errorf("ListModels() diff = %s", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestShowModel(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockStatusCode int
+		mockResponse   string
+		want           ModelInfo
+		wantErr        bool
+		err            error
+	}{
+		{
+			name:           "returns capabilities and context length",
+			mockStatusCode: http.StatusOK,
+			mockResponse:   `{"details":{"family":"qwen3","parameter_size":"8.2B","quantization_level":"Q4_K_M"},"model_info":{"general.architecture":"qwen3","qwen3.context_length":40960},"capabilities":["completion","tools","thinking"]}`,
+			want: ModelInfo{
+				Name:          "qwen3",
+				Family:        "qwen3",
+				ParameterSize: "8.2B",
+				Quantization:  "Q4_K_M",
+				Capabilities:  []string{CapabilityCompletion, CapabilityTools, CapabilityThinking},
+				ContextLength: 40960,
+			},
+		},
+		{
+			name:           "returns error for model not found",
+			mockStatusCode: http.StatusNotFound,
+			mockResponse:   `{"error":"model 'qwen3' not found"}`,
+			wantErr:        true,
+			err:            ErrModelNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body map[string]string
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("failed to decode request: %v", err)
+				}
+
+				if r.URL.Path != "/show" || body["model"] != "qwen3" {
+					t.Errorf("unexpected request: %s %v", r.URL.Path, body)
+				}
+
+				w.WriteHeader(tt.mockStatusCode)
+				_, _ = w.Write([]byte(tt.mockResponse))
+			}))
+			defer server.Close()
+
+			got, err := NewOllama(server.URL).ShowModel(context.Background(), "qwen3")
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("ShowModel() err = %v, want %v", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ShowModel() err = %v, want nil", err)
+			}
+
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("ShowModel() diff = %s", cmp.Diff(tt.want, got))
+			}
+
+			if !got.HasCapability(CapabilityTools) || got.HasCapability(CapabilityVision) {
+				t.Errorf("HasCapability() tools = %v, vision = %v, want true, false", got.HasCapability(CapabilityTools), got.HasCapability(CapabilityVision))
+			}
+		})
+	}
+}
@@ -21,65 +21,82 @@ var (
 
 // ChatRequest holds the information for the chat endpoint.
 type ChatRequest struct {
-	Model    string        `json:"model"`
-	Stream   bool          `json:"stream"`
-	Messages []ChatMessage `json:"messages"`
-	Tools    []Tool        `json:"tools,omitempty"`
+	Model    string          `json:"model"`
+	Stream   bool            `json:"stream"`
+	Messages []ChatMessage   `json:"messages"`
+	Tools    []Tool          `json:"tools,omitempty"`
+	Options  Options         `json:"options"`
+	Think    *bool           `json:"think,omitempty"`  // Nil leaves thinking to the model's default
+	Format   json.RawMessage `json:"format,omitempty"` // "json" or a JSON Schema the response must match
 }
 
 // ChatResponse holds the response from the chat endpoint.
+// Metrics are set on the final chunk of a stream.
 type ChatResponse struct {
 	Message ChatMessage `json:"message"`
+	Done    bool        `json:"done"`
 	Error   string      `json:"error,omitempty"`
+	Metrics
+}
+
+// Ollama is the Provider for the Ollama API.
+type Ollama struct {
+	URL   string
+	Retry Retry
+}
+
+// NewOllama creates and returns a new Ollama provider for the API at url.
+func NewOllama(url string) Ollama {
+	return Ollama{URL: url}
 }
 
 // AnalyzeImages sends a request to the chat endpoint with images to analyze and
 // returns the response message.
-func AnalyzeImages(ctx context.Context, host, model string, messages []ChatMessage) (ChatMessage, error) {
-	request := ChatRequest{
-		Model:    model,
-		Stream:   false,
-		Messages: messages,
-	}
+func (ollama Ollama) AnalyzeImages(ctx context.Context, request ChatRequest) (ChatMessage, error) {
+	request.Stream = false
+	request.Tools = nil
 
 	var chatResponse ChatResponse
 
-	err := requests.
-		URL(host + "/chat").
-		BodyJSON(&request).
-		AddValidator(nil).
-		Handle(func(response *http.Response) error {
-			defer func() {
-				_ = response.Body.Close()
-			}()
-
-			if err := json.NewDecoder(response.Body).Decode(&chatResponse); err != nil {
-				return fmt.Errorf("%w: %w", ErrDecodeChunk, err)
-			}
+	err := ollama.Retry.do(ctx, func() error {
+		err := requests.
+			URL(ollama.URL + "/chat").
+			BodyJSON(&request).
+			AddValidator(checkStatus).
+			Handle(func(response *http.Response) error {
+				defer func() {
+					_ = response.Body.Close()
+				}()
+
+				if err := json.NewDecoder(response.Body).Decode(&chatResponse); err != nil {
+					return fmt.Errorf("%w: %w", ErrDecodeChunk, err)
+				}
 
-			if response.StatusCode != http.StatusOK {
 				if chatResponse.Error != "" {
-					return fmt.Errorf("status %d: %s", response.StatusCode, chatResponse.Error)
+					return &StatusError{StatusCode: response.StatusCode, Message: chatResponse.Error}
 				}
 
-				return fmt.Errorf("status %d", response.StatusCode)
-			}
+				return nil
+			}).
+			Fetch(ctx)
 
-			if chatResponse.Error != "" {
-				return fmt.Errorf("%s", chatResponse.Error)
-			}
-
-			return nil
-		}).
-		Fetch(ctx)
+		return classifyError(err, request.Model)
+	})
 
 	if err != nil {
-		return handleHTTPErrors(err, model)
+		return ChatMessage{}, err
 	}
 
+	thinking, content := splitThinking(chatResponse.Message.Content)
+
 	chatMessage := ChatMessage{
-		Role:    RoleAssistant,
-		Content: chatResponse.Message.Content,
+		Role:     RoleAssistant,
+		Content:  content,
+		Thinking: chatResponse.Message.Thinking + thinking,
+	}
+
+	if chatResponse.Done {
+		chatMessage.Metrics = &chatResponse.Metrics
 	}
 
 	return chatMessage, nil
@@ -87,111 +104,120 @@ func AnalyzeImages(ctx context.Context, host, model string, messages []ChatMessa
 
 // Chat sends a non-streaming request to the chat endpoint with tools and returns
 // the response message.
-func Chat(ctx context.Context, host, model string, messages []ChatMessage, tools []Tool) (ChatMessage, error) {
-	request := ChatRequest{
-		Model:    model,
-		Stream:   false,
-		Messages: messages,
-		Tools:    tools,
-	}
+func (ollama Ollama) Chat(ctx context.Context, request ChatRequest) (ChatMessage, error) {
+	request.Stream = false
 
 	var chatResponse ChatResponse
 
-	err := requests.
-		URL(host + "/chat").
-		BodyJSON(&request).
-		ToJSON(&chatResponse).
-		Fetch(ctx)
+	err := ollama.Retry.do(ctx, func() error {
+		err := requests.
+			URL(ollama.URL + "/chat").
+			BodyJSON(&request).
+			AddValidator(checkStatus).
+			ToJSON(&chatResponse).
+			Fetch(ctx)
+
+		if err == nil && chatResponse.Error != "" {
+			err = &StatusError{StatusCode: http.StatusOK, Message: chatResponse.Error}
+		}
+
+		return classifyError(err, request.Model)
+	})
 
 	if err != nil {
-		return handleHTTPErrors(err, model)
+		return ChatMessage{}, err
 	}
 
-	if chatResponse.Error != "" {
-		if strings.Contains(chatResponse.Error, "does not support tools") {
-			return ChatMessage{}, fmt.Errorf("%w: %s", ErrToolSupport, model)
-		}
+	// Return chatResponse.Message directly to preserve ToolCalls.
+	thinking, content := splitThinking(chatResponse.Message.Content)
+	chatResponse.Message.Content = content
+	chatResponse.Message.Thinking += thinking
 
-		return ChatMessage{}, fmt.Errorf("%w: %s", ErrUnexpectedStatus, chatResponse.Error)
+	if chatResponse.Done {
+		chatResponse.Message.Metrics = &chatResponse.Metrics
 	}
 
-	// Return chatResponse.Message directly to preserve ToolCalls.
 	return chatResponse.Message, nil
 }
 
 // StreamChat sends a streaming request to the chat endpoint and returns the
-// response message.
-// onChunk is called for each streamed chunk of content.
-func StreamChat(ctx context.Context, host, model string, messages []ChatMessage, tools []Tool, onChunk func(string)) (ChatMessage, error) {
-	request := ChatRequest{
-		Model:    model,
-		Stream:   true,
-		Messages: messages,
-		Tools:    tools,
+// response message with any tool calls the model made.
+// onChunk is called for each streamed chunk of content and thinking. Thinking
+// is read from the thinking field or inline <think> tags.
+func (ollama Ollama) StreamChat(ctx context.Context, request ChatRequest, onChunk func(ChatMessage)) (ChatMessage, error) {
+	request.Stream = true
+
+	var chatContent, chatThinking strings.Builder
+	var toolCalls []ToolCall
+	var parser thinkingParser
+	var metrics *Metrics
+
+	emit := func(thinking, content string) {
+		if thinking == "" && content == "" {
+			return
+		}
+
+		onChunk(ChatMessage{Role: RoleAssistant, Content: content, Thinking: thinking})
+
+		chatThinking.WriteString(thinking)
+		chatContent.WriteString(content)
 	}
 
-	var chatContent strings.Builder
-
-	err := requests.
-		URL(host + "/chat").
-		BodyJSON(&request).
-		AddValidator(nil).
-		Handle(func(response *http.Response) error {
-			defer func() {
-				_ = response.Body.Close()
-			}()
-
-			if response.StatusCode != http.StatusOK {
-				var errResponse ChatResponse
-				if err := json.NewDecoder(response.Body).Decode(&errResponse); err == nil && errResponse.Error != "" {
-					return fmt.Errorf("status %d: %s", response.StatusCode, errResponse.Error)
-				}
+	err := ollama.Retry.do(ctx, func() error {
+		err := requests.
+			URL(ollama.URL + "/chat").
+			BodyJSON(&request).
+			AddValidator(checkStatus).
+			Handle(func(response *http.Response) error {
+				defer func() {
+					_ = response.Body.Close()
+				}()
 
-				return fmt.Errorf("status %d", response.StatusCode)
-			}
+				decoder := json.NewDecoder(response.Body)
 
-			decoder := json.NewDecoder(response.Body)
+				for {
+					var chunk ChatResponse
 
-			for {
-				var chunk ChatResponse
+					if err := decoder.Decode(&chunk); err == io.EOF {
+						break
+					} else if err != nil {
+						return fmt.Errorf("%w: %w", ErrDecodeChunk, err)
+					}
 
-				if err := decoder.Decode(&chunk); err == io.EOF {
-					break
-				} else if err != nil {
-					return fmt.Errorf("%w: %w", ErrDecodeChunk, err)
+					if chunk.Error != "" {
+						return &StatusError{StatusCode: response.StatusCode, Message: chunk.Error}
+					}
+
+					emit(chunk.Message.Thinking, "")
+					emit(parser.parse(chunk.Message.Content))
+
+					toolCalls = append(toolCalls, chunk.Message.ToolCalls...)
+
+					if chunk.Done {
+						metrics = &chunk.Metrics
+					}
 				}
 
-				onChunk(chunk.Message.Content)
+				emit(parser.flush())
 
-				chatContent.WriteString(chunk.Message.Content)
-			}
+				return nil
+			}).
+			Fetch(ctx)
 
-			return nil
-		}).
-		Fetch(ctx)
+		return classifyError(err, request.Model)
+	})
 
 	if err != nil {
-		return handleHTTPErrors(err, model)
+		return ChatMessage{}, err
 	}
 
 	chatMessage := ChatMessage{
-		Role:    RoleAssistant,
-		Content: chatContent.String(),
+		Role:      RoleAssistant,
+		Content:   chatContent.String(),
+		Thinking:  chatThinking.String(),
+		ToolCalls: toolCalls,
+		Metrics:   metrics,
 	}
 
 	return chatMessage, nil
 }
-
-func handleHTTPErrors(err error, model string) (ChatMessage, error) {
-	errStr := err.Error()
-
-	if strings.Contains(errStr, "404") {
-		return ChatMessage{}, fmt.Errorf("%w: %s", ErrModelNotFound, model)
-	}
-
-	if strings.Contains(errStr, ErrDecodeChunk.Error()) {
-		return ChatMessage{}, err
-	}
-
-	return ChatMessage{}, fmt.Errorf("%w: %w", ErrUnexpectedStatus, err)
-}
@@ -7,6 +7,9 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestStreamChat(t *testing.T) {
@@ -18,6 +21,9 @@ func TestStreamChat(t *testing.T) {
 		mockResponse   string
 		wantContent    string
 		wantChunkCount int
+		wantThinking   string
+		wantToolCalls  int
+		wantMetrics    *Metrics
 		wantErr        bool
 		err            error
 	}{
@@ -35,6 +41,65 @@ func TestStreamChat(t *testing.T) {
 			wantContent:    "Hello there!",
 			wantChunkCount: 3,
 		},
+		{
+			name:  "streaming chat with metrics in final chunk",
+			model: "test:model",
+			messages: []ChatMessage{
+				{Role: RoleUser, Content: "Hello"},
+			},
+			mockStatusCode: http.StatusOK,
+			mockResponse: `{"message":{"role":"assistant","content":"Hello!"},"done":false}
+{"message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":12,"eval_count":3,"total_duration":2000000000,"eval_duration":1000000000}
+`,
+			wantContent:    "Hello!",
+			wantChunkCount: 1,
+			wantMetrics:    &Metrics{PromptEvalCount: 12, EvalCount: 3, TotalDuration: 2 * time.Second, EvalDuration: time.Second},
+		},
+		{
+			name:  "streaming chat with thinking field",
+			model: "test:model",
+			messages: []ChatMessage{
+				{Role: RoleUser, Content: "Hello"},
+			},
+			mockStatusCode: http.StatusOK,
+			mockResponse: `{"message":{"role":"assistant","content":"","thinking":"greet "}}
+{"message":{"role":"assistant","content":"","thinking":"them"}}
+{"message":{"role":"assistant","content":"Hello!"}}
+`,
+			wantContent:    "Hello!",
+			wantChunkCount: 1,
+			wantThinking:   "greet them",
+		},
+		{
+			name:  "streaming chat with inline think tags",
+			model: "test:model",
+			messages: []ChatMessage{
+				{Role: RoleUser, Content: "Hello"},
+			},
+			mockStatusCode: http.StatusOK,
+			mockResponse: `{"message":{"role":"assistant","content":"<think>greet"}}
+{"message":{"role":"assistant","content":" them</think>\n\n"}}
+{"message":{"role":"assistant","content":"Hello!"}}
+`,
+			wantContent:    "Hello!",
+			wantChunkCount: 1,
+			wantThinking:   "greet them",
+		},
+		{
+			name:  "streaming chat with tool calls",
+			model: "test:model",
+			messages: []ChatMessage{
+				{Role: RoleUser, Content: "Search for Go news"},
+			},
+			mockStatusCode: http.StatusOK,
+			mockResponse: `{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"web_search","arguments":{"query":"Go news"}}}]}}
+{"message":{"role":"assistant","content":""},"done":true}
+`,
+			wantContent:    "",
+			wantChunkCount: 0,
+			wantToolCalls:  1,
+			wantMetrics:    &Metrics{},
+		},
 		{
 			name:  "returns error for model not found",
 			model: "test:model",
@@ -45,6 +110,17 @@ func TestStreamChat(t *testing.T) {
 			wantErr:        true,
 			err:            ErrModelNotFound,
 		},
+		{
+			name:  "returns error for model not supporting tools",
+			model: "test:model",
+			messages: []ChatMessage{
+				{Role: RoleUser, Content: "Hello"},
+			},
+			mockStatusCode: http.StatusBadRequest,
+			mockResponse:   `{"error":"dolphin-mistral does not support tools"}`,
+			wantErr:        true,
+			err:            ErrToolSupport,
+		},
 		{
 			name:  "returns error for unexpected status",
 			model: "test:model",
@@ -81,11 +157,16 @@ func TestStreamChat(t *testing.T) {
 			defer server.Close()
 
 			var chunks []string
-			onChunk := func(content string) {
-				chunks = append(chunks, content)
+			var thinking strings.Builder
+			onChunk := func(chunk ChatMessage) {
+				thinking.WriteString(chunk.Thinking)
+
+				if chunk.Content != "" {
+					chunks = append(chunks, chunk.Content)
+				}
 			}
 
-			got, err := StreamChat(context.Background(), server.URL, tt.model, tt.messages, nil, onChunk)
+			got, err := NewOllama(server.URL).StreamChat(context.Background(), ChatRequest{Model: tt.model, Messages: tt.messages}, onChunk)
 
 			if tt.wantErr {
 				if err == nil {
@@ -118,6 +199,22 @@ func TestStreamChat(t *testing.T) {
 			if concatenated != tt.wantContent {
 				t.Errorf("Chat() concatenated chunks = %v, want %v", concatenated, tt.wantContent)
 			}
+
+			if got.Thinking != tt.wantThinking {
+				t.Errorf("Chat() thinking = %q, want %q", got.Thinking, tt.wantThinking)
+			}
+
+			if thinking.String() != tt.wantThinking {
+				t.Errorf("Chat() streamed thinking = %q, want %q", thinking.String(), tt.wantThinking)
+			}
+
+			if len(got.ToolCalls) != tt.wantToolCalls {
+				t.Errorf("Chat() tool_calls count = %v, want %v", len(got.ToolCalls), tt.wantToolCalls)
+			}
+
+			if !cmp.Equal(got.Metrics, tt.wantMetrics) {
+				t.Errorf("Chat() metrics = %+v, want %+v", got.Metrics, tt.wantMetrics)
+			}
 		})
 	}
 }
@@ -216,7 +313,7 @@ func TestChat(t *testing.T) {
 			}))
 			defer server.Close()
 
-			got, err := Chat(context.Background(), server.URL, tt.model, tt.messages, tt.tools)
+			got, err := NewOllama(server.URL).Chat(context.Background(), ChatRequest{Model: tt.model, Messages: tt.messages, Tools: tt.tools})
 
 			if tt.wantErr {
 				if err == nil {
@@ -301,7 +398,7 @@ func TestAnalyzeImages(t *testing.T) {
 			}))
 			defer server.Close()
 
-			got, err := AnalyzeImages(context.Background(), server.URL, tt.model, tt.messages)
+			got, err := NewOllama(server.URL).AnalyzeImages(context.Background(), ChatRequest{Model: tt.model, Messages: tt.messages})
 
 			if tt.wantErr {
 				if err == nil {
@@ -0,0 +1,500 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/carlmjohnson/requests"
+)
+
+// openAIRequest holds the information for the chat completions endpoint.
+// Options are sent as top level sampling parameters, num_ctx is set on the
+// server.
+type openAIRequest struct {
+	Model          string                `json:"model"`
+	Stream         bool                  `json:"stream"`
+	Messages       []openAIMessage       `json:"messages"`
+	Tools          []Tool                `json:"tools,omitempty"`
+	Temperature    *float64              `json:"temperature,omitempty"`
+	Seed           *int                  `json:"seed,omitempty"`
+	TopP           *float64              `json:"top_p,omitempty"`
+	RepeatPenalty  *float64              `json:"repeat_penalty,omitempty"`
+	Stop           []string              `json:"stop,omitempty"`
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+	StreamOptions  *openAIStreamOptions  `json:"stream_options,omitempty"`
+}
+
+// openAIResponseFormat constrains the response to JSON or a JSON Schema.
+type openAIResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *openAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+type openAIJSONSchema struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+}
+
+// openAIMessage holds a single message in the OpenAI chat format.
+// Content is a string or a slice of openAIContentPart for messages with images.
+type openAIMessage struct {
+	Role       Role             `json:"role"`
+	Content    any              `json:"content"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+type openAIContentPart struct {
+	Type     string          `json:"type"`
+	Text     string          `json:"text,omitempty"`
+	ImageURL *openAIImageURL `json:"image_url,omitempty"`
+}
+
+type openAIImageURL struct {
+	URL string `json:"url"`
+}
+
+// openAIToolCall is a tool call in the OpenAI format, arguments are a JSON
+// encoded string instead of an object.
+type openAIToolCall struct {
+	Index    int    `json:"index"`
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// openAIDelta holds the message or streamed delta of a choice.
+// Reasoning models served by llama-server and vLLM return thinking in
+// reasoning_content.
+type openAIDelta struct {
+	Content          string           `json:"content"`
+	ReasoningContent string           `json:"reasoning_content"`
+	ToolCalls        []openAIToolCall `json:"tool_calls,omitempty"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIDelta `json:"message"`
+		Delta   openAIDelta `json:"delta"`
+	} `json:"choices"`
+	Usage *openAIUsage `json:"usage,omitempty"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// openAIUsage holds the token usage of a response. When streaming it's sent in
+// the final chunk.
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// OpenAI is the Provider for OpenAI compatible APIs such as llama.cpp's
+// llama-server and vLLM.
+type OpenAI struct {
+	URL    string
+	APIKey string
+	Retry  Retry
+}
+
+// NewOpenAI creates and returns a new OpenAI provider for the API at url.
+// apiKey is sent as a bearer token if not empty.
+func NewOpenAI(url, apiKey string) OpenAI {
+	return OpenAI{URL: url, APIKey: apiKey}
+}
+
+// AnalyzeImages sends a request to the chat completions endpoint with images to
+// analyze and returns the response message.
+func (openAI OpenAI) AnalyzeImages(ctx context.Context, request ChatRequest) (ChatMessage, error) {
+	request.Tools = nil
+
+	message, err := openAI.Chat(ctx, request)
+	if err != nil {
+		return ChatMessage{}, err
+	}
+
+	chatMessage := ChatMessage{
+		Role:    RoleAssistant,
+		Content: message.Content,
+		Metrics: message.Metrics,
+	}
+
+	return chatMessage, nil
+}
+
+// Chat sends a non-streaming request to the chat completions endpoint with tools
+// and returns the response message.
+func (openAI OpenAI) Chat(ctx context.Context, request ChatRequest) (ChatMessage, error) {
+	var chatResponse openAIResponse
+
+	start := time.Now()
+
+	err := openAI.Retry.do(ctx, func() error {
+		err := openAI.builder(request, false).
+			Handle(func(response *http.Response) error {
+				defer func() {
+					_ = response.Body.Close()
+				}()
+
+				if err := json.NewDecoder(response.Body).Decode(&chatResponse); err != nil {
+					return fmt.Errorf("%w: %w", ErrDecodeChunk, err)
+				}
+
+				return checkOpenAIResponse(chatResponse)
+			}).
+			Fetch(ctx)
+
+		return classifyError(err, request.Model)
+	})
+
+	if err != nil {
+		return ChatMessage{}, err
+	}
+
+	if len(chatResponse.Choices) == 0 {
+		return ChatMessage{}, fmt.Errorf("%w: no choices in response", ErrUnexpectedStatus)
+	}
+
+	message := chatResponse.Choices[0].Message
+	thinking, content := splitThinking(message.Content)
+
+	chatMessage := ChatMessage{
+		Role:      RoleAssistant,
+		Content:   content,
+		Thinking:  message.ReasoningContent + thinking,
+		ToolCalls: fromOpenAIToolCalls(message.ToolCalls),
+		Metrics:   toMetrics(chatResponse.Usage, time.Since(start)),
+	}
+
+	return chatMessage, nil
+}
+
+// StreamChat sends a streaming request to the chat completions endpoint and
+// returns the response message.
+// onChunk is called for each streamed chunk of content and thinking.
+func (openAI OpenAI) StreamChat(ctx context.Context, request ChatRequest, onChunk func(ChatMessage)) (ChatMessage, error) {
+	var chatContent, chatThinking strings.Builder
+	var parser thinkingParser
+	var usage *openAIUsage
+	toolCalls := map[int]*openAIToolCall{}
+
+	start := time.Now()
+
+	emit := func(thinking, content string) {
+		if thinking == "" && content == "" {
+			return
+		}
+
+		onChunk(ChatMessage{Role: RoleAssistant, Content: content, Thinking: thinking})
+
+		chatThinking.WriteString(thinking)
+		chatContent.WriteString(content)
+	}
+
+	err := openAI.Retry.do(ctx, func() error {
+		err := openAI.builder(request, true).
+			Handle(func(response *http.Response) error {
+				defer func() {
+					_ = response.Body.Close()
+				}()
+
+				scanner := bufio.NewScanner(response.Body)
+				scanner.Buffer(make([]byte, 64*1024), 10<<20)
+
+				for scanner.Scan() {
+					line := strings.TrimSpace(scanner.Text())
+
+					data, ok := strings.CutPrefix(line, "data:")
+					if !ok {
+						continue
+					}
+
+					data = strings.TrimSpace(data)
+					if data == "[DONE]" {
+						break
+					}
+
+					var chunk openAIResponse
+					if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+						return fmt.Errorf("%w: %w", ErrDecodeChunk, err)
+					}
+
+					if err := checkOpenAIResponse(chunk); err != nil {
+						return err
+					}
+
+					if chunk.Usage != nil {
+						usage = chunk.Usage
+					}
+
+					if len(chunk.Choices) == 0 {
+						continue
+					}
+
+					delta := chunk.Choices[0].Delta
+
+					emit(delta.ReasoningContent, "")
+					emit(parser.parse(delta.Content))
+
+					// Tool calls are streamed in fragments keyed by index.
+					for _, fragment := range delta.ToolCalls {
+						toolCall, ok := toolCalls[fragment.Index]
+						if !ok {
+							toolCall = &openAIToolCall{Index: fragment.Index}
+							toolCalls[fragment.Index] = toolCall
+						}
+
+						if fragment.ID != "" {
+							toolCall.ID = fragment.ID
+						}
+
+						if fragment.Function.Name != "" {
+							toolCall.Function.Name = fragment.Function.Name
+						}
+
+						toolCall.Function.Arguments += fragment.Function.Arguments
+					}
+				}
+
+				if err := scanner.Err(); err != nil {
+					return fmt.Errorf("%w: %w", ErrDecodeChunk, err)
+				}
+
+				emit(parser.flush())
+
+				return nil
+			}).
+			Fetch(ctx)
+
+		return classifyError(err, request.Model)
+	})
+
+	if err != nil {
+		return ChatMessage{}, err
+	}
+
+	var streamedCalls []openAIToolCall
+	for _, toolCall := range toolCalls {
+		streamedCalls = append(streamedCalls, *toolCall)
+	}
+
+	sort.Slice(streamedCalls, func(x, y int) bool {
+		return streamedCalls[x].Index < streamedCalls[y].Index
+	})
+
+	chatMessage := ChatMessage{
+		Role:      RoleAssistant,
+		Content:   chatContent.String(),
+		Thinking:  chatThinking.String(),
+		ToolCalls: fromOpenAIToolCalls(streamedCalls),
+		Metrics:   toMetrics(usage, time.Since(start)),
+	}
+
+	return chatMessage, nil
+}
+
+// builder returns the request builder for the chat completions endpoint.
+func (openAI OpenAI) builder(request ChatRequest, stream bool) *requests.Builder {
+	body := openAIRequest{
+		Model:          request.Model,
+		Stream:         stream,
+		Messages:       toOpenAIMessages(request.Messages),
+		Tools:          request.Tools,
+		Temperature:    request.Options.Temperature,
+		Seed:           request.Options.Seed,
+		TopP:           request.Options.TopP,
+		RepeatPenalty:  request.Options.RepeatPenalty,
+		Stop:           request.Options.Stop,
+		ResponseFormat: toOpenAIResponseFormat(request.Format),
+	}
+
+	if stream {
+		body.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+	}
+
+	builder := requests.
+		URL(openAI.URL + "/chat/completions").
+		BodyJSON(&body).
+		AddValidator(checkStatus)
+
+	if openAI.APIKey != "" {
+		builder = builder.Bearer(openAI.APIKey)
+	}
+
+	return builder
+}
+
+// toOpenAIResponseFormat converts an Ollama format to a response format.
+// Returns nil if format is empty.
+func toOpenAIResponseFormat(format json.RawMessage) *openAIResponseFormat {
+	if len(format) == 0 {
+		return nil
+	}
+
+	if string(format) == `"json"` {
+		return &openAIResponseFormat{Type: "json_object"}
+	}
+
+	return &openAIResponseFormat{
+		Type:       "json_schema",
+		JSONSchema: &openAIJSONSchema{Name: "response", Schema: format},
+	}
+}
+
+// toMetrics converts usage to metrics with the elapsed time as the total
+// duration, the generation time isn't reported.
+// Returns nil if the server didn't report usage.
+func toMetrics(usage *openAIUsage, elapsed time.Duration) *Metrics {
+	if usage == nil {
+		return nil
+	}
+
+	return &Metrics{
+		PromptEvalCount: usage.PromptTokens,
+		EvalCount:       usage.CompletionTokens,
+		TotalDuration:   elapsed,
+	}
+}
+
+// checkOpenAIResponse returns an error if the response carries an error object.
+func checkOpenAIResponse(response openAIResponse) error {
+	if response.Error != nil {
+		return &StatusError{StatusCode: http.StatusOK, Message: response.Error.Message}
+	}
+
+	return nil
+}
+
+// toOpenAIMessages converts the message history to the OpenAI format.
+// Tool calls and results keep their IDs, those without one are matched to the
+// preceding assistant tool calls by position.
+func toOpenAIMessages(messages []ChatMessage) []openAIMessage {
+	var converted []openAIMessage
+	var pendingIDs []string
+	callCount := 0
+
+	for _, message := range messages {
+		openAIMsg := openAIMessage{
+			Role:    message.Role,
+			Content: message.Content,
+		}
+
+		if len(message.Images) > 0 {
+			parts := []openAIContentPart{{Type: "text", Text: message.Content}}
+
+			for _, image := range message.Images {
+				parts = append(parts, openAIContentPart{
+					Type:     "image_url",
+					ImageURL: &openAIImageURL{URL: imageDataURL(image)},
+				})
+			}
+
+			openAIMsg.Content = parts
+		}
+
+		if len(message.ToolCalls) > 0 {
+			pendingIDs = nil
+
+			for i, toolCall := range message.ToolCalls {
+				id := toolCall.ID
+				if id == "" {
+					callCount++
+					id = fmt.Sprintf("call_%d", callCount)
+				}
+
+				pendingIDs = append(pendingIDs, id)
+
+				openAIToolCall := openAIToolCall{Index: i, ID: id, Type: "function"}
+				openAIToolCall.Function.Name = toolCall.Function.Name
+				openAIToolCall.Function.Arguments = string(toolCall.Function.Arguments)
+
+				if openAIToolCall.Function.Arguments == "" {
+					openAIToolCall.Function.Arguments = "{}"
+				}
+
+				openAIMsg.ToolCalls = append(openAIMsg.ToolCalls, openAIToolCall)
+			}
+		}
+
+		if message.Role == RoleTool {
+			openAIMsg.ToolCallID = message.ToolCallID
+
+			if index := slices.Index(pendingIDs, message.ToolCallID); index >= 0 {
+				pendingIDs = slices.Delete(pendingIDs, index, index+1)
+			} else if openAIMsg.ToolCallID == "" && len(pendingIDs) > 0 {
+				openAIMsg.ToolCallID = pendingIDs[0]
+				pendingIDs = pendingIDs[1:]
+			}
+		}
+
+		converted = append(converted, openAIMsg)
+	}
+
+	return converted
+}
+
+// fromOpenAIToolCalls converts OpenAI tool calls, decoding the string arguments
+// into JSON.
+func fromOpenAIToolCalls(openAIToolCalls []openAIToolCall) []ToolCall {
+	var toolCalls []ToolCall
+
+	for _, openAIToolCall := range openAIToolCalls {
+		var toolCall ToolCall
+		toolCall.ID = openAIToolCall.ID
+		toolCall.Function.Name = openAIToolCall.Function.Name
+
+		arguments := strings.TrimSpace(openAIToolCall.Function.Arguments)
+
+		switch {
+		case arguments == "":
+			toolCall.Function.Arguments = json.RawMessage("{}")
+
+		case json.Valid([]byte(arguments)):
+			toolCall.Function.Arguments = json.RawMessage(arguments)
+
+		default:
+			// Pass invalid arguments through as a string so the tool reports the
+			// parse error back to the model.
+			encoded, _ := json.Marshal(arguments)
+			toolCall.Function.Arguments = encoded
+		}
+
+		toolCalls = append(toolCalls, toolCall)
+	}
+
+	return toolCalls
+}
+
+// imageDataURL returns a data URL for a base64 encoded image.
+func imageDataURL(encodedImage string) string {
+	mediaType := "image/png"
+
+	// Only the header bytes are needed to sniff the type.
+	header := encodedImage[:min(len(encodedImage), 684)]
+
+	decoded, err := base64.StdEncoding.DecodeString(header)
+	if err == nil {
+		detected := strings.SplitN(http.DetectContentType(decoded), ";", 2)[0]
+		if strings.HasPrefix(detected, "image/") {
+			mediaType = detected
+		}
+	}
+
+	return fmt.Sprintf("data:%s;base64,%s", mediaType, encodedImage)
+}
@@ -0,0 +1,411 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAIStreamChat(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockStatusCode int
+		mockResponse   string
+		wantContent    string
+		wantChunkCount int
+		wantThinking   string
+		wantUsage      *Metrics
+		wantToolCalls  []string
+		wantArguments  string
+		wantErr        bool
+		err            error
+	}{
+		{
+			name:           "successful streaming chat",
+			mockStatusCode: http.StatusOK,
+			mockResponse: `data: {"choices":[{"delta":{"role":"assistant","content":"Hello"}}]}
+
+data: {"choices":[{"delta":{"content":" there"}}]}
+
+data: {"choices":[{"delta":{"content":"!"}}]}
+
+data: [DONE]
+`,
+			wantContent:    "Hello there!",
+			wantChunkCount: 3,
+		},
+		{
+			name:           "reads usage from final chunk",
+			mockStatusCode: http.StatusOK,
+			mockResponse: `data: {"choices":[{"delta":{"content":"Hello!"}}]}
+
+data: {"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":3}}
+
+data: [DONE]
+`,
+			wantContent:    "Hello!",
+			wantChunkCount: 1,
+			wantUsage:      &Metrics{PromptEvalCount: 12, EvalCount: 3},
+		},
+		{
+			name:           "streams reasoning content as thinking",
+			mockStatusCode: http.StatusOK,
+			mockResponse: `data: {"choices":[{"delta":{"reasoning_content":"greet them"}}]}
+
+data: {"choices":[{"delta":{"content":"Hello!"}}]}
+
+data: [DONE]
+`,
+			wantContent:    "Hello!",
+			wantChunkCount: 1,
+			wantThinking:   "greet them",
+		},
+		{
+			name:           "accumulates streamed tool call fragments",
+			mockStatusCode: http.StatusOK,
+			mockResponse: `data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_a","type":"function","function":{"name":"web_search","arguments":""}}]}}]}
+
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"query\":"}}]}}]}
+
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Go news\"}"}}]}}]}
+
+data: [DONE]
+`,
+			wantToolCalls: []string{"web_search"},
+			wantArguments: `{"query":"Go news"}`,
+		},
+		{
+			name:           "returns error for model not found",
+			mockStatusCode: http.StatusNotFound,
+			mockResponse:   `{"error":{"message":"model not found"}}`,
+			wantErr:        true,
+			err:            ErrModelNotFound,
+		},
+		{
+			name:           "returns error for unexpected status",
+			mockStatusCode: http.StatusInternalServerError,
+			mockResponse:   `{"error":{"message":"internal server error"}}`,
+			wantErr:        true,
+			err:            ErrUnexpectedStatus,
+		},
+		{
+			name:           "returns error for malformed chunk",
+			mockStatusCode: http.StatusOK,
+			mockResponse:   `data: {"invalid json`,
+			wantErr:        true,
+			err:            ErrDecodeChunk,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/chat/completions" {
+					t.Errorf("unexpected path: %s", r.URL.Path)
+				}
+
+				w.WriteHeader(tt.mockStatusCode)
+				_, _ = w.Write([]byte(tt.mockResponse))
+			}))
+			defer server.Close()
+
+			var chunks []string
+			var thinking strings.Builder
+			onChunk := func(chunk ChatMessage) {
+				thinking.WriteString(chunk.Thinking)
+
+				if chunk.Content != "" {
+					chunks = append(chunks, chunk.Content)
+				}
+			}
+
+			request := ChatRequest{Model: "test-model", Messages: []ChatMessage{{Role: RoleUser, Content: "Hello"}}}
+
+			got, err := NewOpenAI(server.URL, "").StreamChat(context.Background(), request, onChunk)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("StreamChat() err = nil, want error")
+				}
+
+				if !errors.Is(err, tt.err) {
+					t.Errorf("StreamChat() err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("StreamChat() error = %v, want no error", err)
+			}
+
+			if got.Content != tt.wantContent {
+				t.Errorf("StreamChat() content = %v, want %v", got.Content, tt.wantContent)
+			}
+
+			if len(chunks) != tt.wantChunkCount {
+				t.Errorf("StreamChat() chunk count = %v, want %v", len(chunks), tt.wantChunkCount)
+			}
+
+			if got.Thinking != tt.wantThinking || thinking.String() != tt.wantThinking {
+				t.Errorf("StreamChat() thinking = %q, streamed %q, want %q", got.Thinking, thinking.String(), tt.wantThinking)
+			}
+
+			if tt.wantUsage == nil && got.Metrics != nil {
+				t.Errorf("StreamChat() metrics = %+v, want nil", got.Metrics)
+			}
+
+			if tt.wantUsage != nil && (got.Metrics == nil || got.Metrics.PromptEvalCount != tt.wantUsage.PromptEvalCount || got.Metrics.EvalCount != tt.wantUsage.EvalCount) {
+				t.Errorf("StreamChat() metrics = %+v, want usage %+v", got.Metrics, tt.wantUsage)
+			}
+
+			if len(got.ToolCalls) != len(tt.wantToolCalls) {
+				t.Fatalf("StreamChat() tool_calls count = %v, want %v", len(got.ToolCalls), len(tt.wantToolCalls))
+			}
+
+			for i, name := range tt.wantToolCalls {
+				if got.ToolCalls[i].Function.Name != name {
+					t.Errorf("StreamChat() tool call name = %v, want %v", got.ToolCalls[i].Function.Name, name)
+				}
+
+				if got.ToolCalls[i].ID == "" {
+					t.Errorf("StreamChat() tool call ID is empty, want the streamed ID")
+				}
+
+				if string(got.ToolCalls[i].Function.Arguments) != tt.wantArguments {
+					t.Errorf("StreamChat() tool call arguments = %s, want %s", got.ToolCalls[i].Function.Arguments, tt.wantArguments)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenAIChat(t *testing.T) {
+	tests := []struct {
+		name           string
+		apiKey         string
+		mockStatusCode int
+		mockResponse   string
+		wantContent    string
+		wantToolCalls  int
+		wantErr        bool
+		err            error
+	}{
+		{
+			name:           "successful chat",
+			mockStatusCode: http.StatusOK,
+			mockResponse:   `{"choices":[{"message":{"role":"assistant","content":"Hello there!"}}]}`,
+			wantContent:    "Hello there!",
+		},
+		{
+			name:           "sends api key as bearer token",
+			apiKey:         "secret",
+			mockStatusCode: http.StatusOK,
+			mockResponse:   `{"choices":[{"message":{"role":"assistant","content":"Hello there!"}}]}`,
+			wantContent:    "Hello there!",
+		},
+		{
+			name:           "chat with tool call response",
+			mockStatusCode: http.StatusOK,
+			mockResponse:   `{"choices":[{"message":{"role":"assistant","content":null,"tool_calls":[{"id":"call_a","type":"function","function":{"name":"web_search","arguments":"{\"query\":\"Go news\"}"}}]}}]}`,
+			wantToolCalls:  1,
+		},
+		{
+			name:           "returns error for model not found",
+			mockStatusCode: http.StatusNotFound,
+			mockResponse:   `{"error":{"message":"model not found"}}`,
+			wantErr:        true,
+			err:            ErrModelNotFound,
+		},
+		{
+			name:           "returns error for empty choices",
+			mockStatusCode: http.StatusOK,
+			mockResponse:   `{"choices":[]}`,
+			wantErr:        true,
+			err:            ErrUnexpectedStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				wantAuth := ""
+				if tt.apiKey != "" {
+					wantAuth = "Bearer " + tt.apiKey
+				}
+
+				if got := r.Header.Get("Authorization"); got != wantAuth {
+					t.Errorf("Authorization header = %q, want %q", got, wantAuth)
+				}
+
+				w.WriteHeader(tt.mockStatusCode)
+				_, _ = w.Write([]byte(tt.mockResponse))
+			}))
+			defer server.Close()
+
+			request := ChatRequest{Model: "test-model", Messages: []ChatMessage{{Role: RoleUser, Content: "Hello"}}}
+
+			got, err := NewOpenAI(server.URL, tt.apiKey).Chat(context.Background(), request)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Chat() err = nil, want error")
+				}
+
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Chat() err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Chat() error = %v, want no error", err)
+			}
+
+			if got.Content != tt.wantContent {
+				t.Errorf("Chat() content = %v, want %v", got.Content, tt.wantContent)
+			}
+
+			if len(got.ToolCalls) != tt.wantToolCalls {
+				t.Errorf("Chat() tool_calls count = %v, want %v", len(got.ToolCalls), tt.wantToolCalls)
+			}
+		})
+	}
+}
+
+func TestOpenAIAnalyzeImages(t *testing.T) {
+	var body struct {
+		Messages []struct {
+			Content []openAIContentPart `json:"content"`
+		} `json:"messages"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"This image shows a cat."}}]}`))
+	}))
+	defer server.Close()
+
+	request := ChatRequest{
+		Model: "test-model",
+		Messages: []ChatMessage{
+			{Role: RoleUser, Content: "Describe this image", Images: []string{"iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNk+M9QDwADhgGAWjR9awAAAABJRU5ErkJggg=="}},
+		},
+	}
+
+	got, err := NewOpenAI(server.URL, "").AnalyzeImages(context.Background(), request)
+	if err != nil {
+		t.Fatalf("AnalyzeImages() error = %v, want no error", err)
+	}
+
+	if got.Content != "This image shows a cat." {
+		t.Errorf("AnalyzeImages() content = %v, want %v", got.Content, "This image shows a cat.")
+	}
+
+	if len(body.Messages) != 1 || len(body.Messages[0].Content) != 2 {
+		t.Fatalf("AnalyzeImages() request content parts = %+v, want text and image", body.Messages)
+	}
+
+	imagePart := body.Messages[0].Content[1]
+	if imagePart.Type != "image_url" || !strings.HasPrefix(imagePart.ImageURL.URL, "data:image/png;base64,") {
+		t.Errorf("AnalyzeImages() image part = %+v, want png data url", imagePart)
+	}
+}
+
+func TestToOpenAIMessages(t *testing.T) {
+	var toolCall ToolCall
+	toolCall.Function.Name = "web_search"
+	toolCall.Function.Arguments = json.RawMessage(`{"query":"Go news"}`)
+
+	messages := []ChatMessage{
+		{Role: RoleUser, Content: "Search for Go news"},
+		{Role: RoleAssistant, ToolCalls: []ToolCall{toolCall, toolCall}},
+		{Role: RoleTool, Content: "first result"},
+		{Role: RoleTool, Content: "second result"},
+	}
+
+	got := toOpenAIMessages(messages)
+
+	if len(got) != len(messages) {
+		t.Fatalf("toOpenAIMessages() count = %d, want %d", len(got), len(messages))
+	}
+
+	calls := got[1].ToolCalls
+	if len(calls) != 2 {
+		t.Fatalf("toOpenAIMessages() tool_calls count = %d, want 2", len(calls))
+	}
+
+	if calls[0].Function.Arguments != `{"query":"Go news"}` {
+		t.Errorf("toOpenAIMessages() arguments = %s, want string encoded JSON", calls[0].Function.Arguments)
+	}
+
+	if got[2].ToolCallID != calls[0].ID || got[3].ToolCallID != calls[1].ID {
+		t.Errorf("toOpenAIMessages() tool_call_ids = %q, %q, want %q, %q", got[2].ToolCallID, got[3].ToolCallID, calls[0].ID, calls[1].ID)
+	}
+}
+
+func TestToOpenAIMessages_ToolCallIDs(t *testing.T) {
+	var search, fetch ToolCall
+	search.ID = "call_search"
+	search.Function.Name = "web_search"
+	fetch.ID = "call_fetch"
+	fetch.Function.Name = "fetch_url"
+
+	messages := []ChatMessage{
+		{Role: RoleAssistant, ToolCalls: []ToolCall{search, fetch}},
+		{Role: RoleTool, Content: "page", ToolName: "fetch_url", ToolCallID: "call_fetch"},
+		{Role: RoleTool, Content: "results", ToolName: "web_search", ToolCallID: "call_search"},
+	}
+
+	got := toOpenAIMessages(messages)
+
+	if got[0].ToolCalls[0].ID != "call_search" || got[0].ToolCalls[1].ID != "call_fetch" {
+		t.Errorf("toOpenAIMessages() tool call IDs = %q, %q, want the calls' IDs", got[0].ToolCalls[0].ID, got[0].ToolCalls[1].ID)
+	}
+
+	if got[1].ToolCallID != "call_fetch" || got[2].ToolCallID != "call_search" {
+		t.Errorf("toOpenAIMessages() tool_call_ids = %q, %q, want the results' IDs", got[1].ToolCallID, got[2].ToolCallID)
+	}
+}
+
+func TestToOpenAIResponseFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		format json.RawMessage
+		want   string
+	}{
+		{
+			name: "returns nil for no format",
+			want: "null",
+		},
+		{
+			name:   "returns json object for json format",
+			format: json.RawMessage(`"json"`),
+			want:   `{"type":"json_object"}`,
+		},
+		{
+			name:   "returns json schema for schema format",
+			format: json.RawMessage(`{"type":"object"}`),
+			want:   `{"type":"json_schema","json_schema":{"name":"response","schema":{"type":"object"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(toOpenAIResponseFormat(tt.format))
+			if err != nil {
+				t.Fatalf("Marshal() err = %v", err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("toOpenAIResponseFormat() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
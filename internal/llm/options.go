@@ -0,0 +1,130 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidOption = errors.New("invalid model option")
+
+// OptionNames lists the option names accepted by Options.Set in display order.
+var OptionNames = []string{"temperature", "num-ctx", "seed", "top-p", "repeat-penalty", "stop"}
+
+// Options holds the model parameters sent with a request.
+// Nil fields are left to the model's defaults.
+type Options struct {
+	Temperature   *float64 `json:"temperature,omitempty"`
+	NumCtx        *int     `json:"num_ctx,omitempty"`
+	Seed          *int     `json:"seed,omitempty"`
+	TopP          *float64 `json:"top_p,omitempty"`
+	RepeatPenalty *float64 `json:"repeat_penalty,omitempty"`
+	Stop          []string `json:"stop,omitempty"`
+}
+
+// Set parses value and sets the option matching name.
+// An empty value resets the option to the model's default.
+// Stop sequences are separated by commas.
+// Returns ErrInvalidOption if the name is unknown or the value can't be parsed.
+func (options *Options) Set(name, value string) error {
+	value = strings.TrimSpace(value)
+
+	var err error
+
+	switch name {
+	case "temperature":
+		options.Temperature, err = parseFloat(value)
+
+	case "num-ctx":
+		options.NumCtx, err = parseInt(value)
+
+	case "seed":
+		options.Seed, err = parseInt(value)
+
+	case "top-p":
+		options.TopP, err = parseFloat(value)
+
+	case "repeat-penalty":
+		options.RepeatPenalty, err = parseFloat(value)
+
+	case "stop":
+		options.Stop = nil
+
+		for stop := range strings.SplitSeq(value, ",") {
+			if stop != "" {
+				options.Stop = append(options.Stop, stop)
+			}
+		}
+
+	default:
+		return fmt.Errorf("%w: unknown option %s", ErrInvalidOption, name)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidOption, name, err)
+	}
+
+	return nil
+}
+
+// String returns the options that are set as space separated name=value pairs.
+func (options Options) String() string {
+	var pairs []string
+
+	if options.Temperature != nil {
+		pairs = append(pairs, fmt.Sprintf("temperature=%g", *options.Temperature))
+	}
+
+	if options.NumCtx != nil {
+		pairs = append(pairs, fmt.Sprintf("num-ctx=%d", *options.NumCtx))
+	}
+
+	if options.Seed != nil {
+		pairs = append(pairs, fmt.Sprintf("seed=%d", *options.Seed))
+	}
+
+	if options.TopP != nil {
+		pairs = append(pairs, fmt.Sprintf("top-p=%g", *options.TopP))
+	}
+
+	if options.RepeatPenalty != nil {
+		pairs = append(pairs, fmt.Sprintf("repeat-penalty=%g", *options.RepeatPenalty))
+	}
+
+	if len(options.Stop) > 0 {
+		pairs = append(pairs, fmt.Sprintf("stop=%s", strings.Join(options.Stop, ",")))
+	}
+
+	if len(pairs) == 0 {
+		return "defaults"
+	}
+
+	return strings.Join(pairs, " ")
+}
+
+func parseFloat(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parsed, nil
+}
+
+func parseInt(value string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parsed, nil
+}
@@ -0,0 +1,107 @@
+package llm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOptionsSet(t *testing.T) {
+	tests := []struct {
+		name       string
+		initial    Options
+		option     string
+		value      string
+		wantString string
+		wantErr    bool
+		err        error
+	}{
+		{
+			name:       "sets temperature",
+			option:     "temperature",
+			value:      "0.7",
+			wantString: "temperature=0.7",
+		},
+		{
+			name:       "sets num-ctx",
+			option:     "num-ctx",
+			value:      "8192",
+			wantString: "num-ctx=8192",
+		},
+		{
+			name:       "sets seed",
+			option:     "seed",
+			value:      "42",
+			wantString: "seed=42",
+		},
+		{
+			name:       "sets top-p",
+			option:     "top-p",
+			value:      "0.9",
+			wantString: "top-p=0.9",
+		},
+		{
+			name:       "sets repeat-penalty",
+			option:     "repeat-penalty",
+			value:      "1.1",
+			wantString: "repeat-penalty=1.1",
+		},
+		{
+			name:       "sets stop sequences",
+			option:     "stop",
+			value:      "END,STOP",
+			wantString: "stop=END,STOP",
+		},
+		{
+			name:       "empty value resets option",
+			initial:    Options{Seed: intPtr(42)},
+			option:     "seed",
+			value:      "",
+			wantString: "defaults",
+		},
+		{
+			name:    "returns error for unknown option",
+			option:  "butts",
+			value:   "1",
+			wantErr: true,
+			err:     ErrInvalidOption,
+		},
+		{
+			name:    "returns error for invalid number",
+			option:  "num-ctx",
+			value:   "lots",
+			wantErr: true,
+			err:     ErrInvalidOption,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := tt.initial
+
+			err := options.Set(tt.option, tt.value)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Set() err = nil, want error")
+				}
+
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Set() err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Set() error = %v, want no error", err)
+			}
+
+			if options.String() != tt.wantString {
+				t.Errorf("Set() options = %q, want %q", options.String(), tt.wantString)
+			}
+		})
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
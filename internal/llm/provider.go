@@ -0,0 +1,62 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const (
+	ProviderOllama = "ollama"
+	ProviderOpenAI = "openai"
+
+	DefaultOllamaURL = "http://localhost:11434/api" // Used by Ollama when no URL is set.
+	DefaultOpenAIURL = "https://api.openai.com/v1"  // Used by OpenAI when no URL is set.
+)
+
+var ErrUnknownProvider = errors.New("unknown neural network provider: valid options are ollama or openai")
+
+// Provider is the interface all LLM backends must implement.
+type Provider interface {
+	// Chat sends a non-streaming request and returns the response message.
+	Chat(ctx context.Context, request ChatRequest) (ChatMessage, error)
+
+	// StreamChat sends a streaming request and returns the response message.
+	// onChunk is called for each streamed chunk of content and thinking.
+	StreamChat(ctx context.Context, request ChatRequest, onChunk func(ChatMessage)) (ChatMessage, error)
+
+	// AnalyzeImages sends a request with images to analyze and returns the
+	// response message.
+	AnalyzeImages(ctx context.Context, request ChatRequest) (ChatMessage, error)
+}
+
+// NewProvider creates and returns the Provider matching name.
+// An empty name returns the Ollama provider and an empty url the provider's
+// default URL. Transient errors are retried according to retry.
+// Returns ErrUnknownProvider if name doesn't match a provider.
+func NewProvider(name, url, apiKey string, retry Retry) (Provider, error) {
+	switch name {
+	case "", ProviderOllama:
+		if url == "" {
+			url = DefaultOllamaURL
+		}
+
+		ollama := NewOllama(url)
+		ollama.Retry = retry
+
+		return ollama, nil
+
+	case ProviderOpenAI:
+		if url == "" {
+			url = DefaultOpenAIURL
+		}
+
+		openAI := NewOpenAI(url, apiKey)
+		openAI.Retry = retry
+
+		return openAI, nil
+
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+	}
+}
@@ -0,0 +1,86 @@
+package llm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		name         string
+		providerName string
+		url          string
+		retry        Retry
+		want         Provider
+		wantErr      bool
+		err          error
+	}{
+		{
+			name:         "returns ollama for empty name",
+			providerName: "",
+			url:          "http://localhost",
+			want:         Ollama{URL: "http://localhost"},
+		},
+		{
+			name:         "returns ollama",
+			providerName: "ollama",
+			url:          "http://localhost",
+			want:         Ollama{URL: "http://localhost"},
+		},
+		{
+			name:         "returns openai",
+			providerName: "openai",
+			url:          "http://localhost",
+			want:         OpenAI{URL: "http://localhost", APIKey: "key"},
+		},
+		{
+			name:         "uses the ollama URL by default",
+			providerName: "ollama",
+			want:         Ollama{URL: DefaultOllamaURL},
+		},
+		{
+			name:         "uses the openai URL by default",
+			providerName: "openai",
+			want:         OpenAI{URL: DefaultOpenAIURL, APIKey: "key"},
+		},
+		{
+			name:         "sets retry policy",
+			providerName: "ollama",
+			url:          "http://localhost",
+			retry:        Retry{Attempts: 2, Delay: time.Second},
+			want:         Ollama{URL: "http://localhost", Retry: Retry{Attempts: 2, Delay: time.Second}},
+		},
+		{
+			name:         "returns error for unknown provider",
+			providerName: "butts",
+			wantErr:      true,
+			err:          ErrUnknownProvider,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewProvider(tt.providerName, tt.url, "key", tt.retry)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NewProvider() err = nil, want error")
+				}
+
+				if !errors.Is(err, tt.err) {
+					t.Errorf("NewProvider() err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("NewProvider() error = %v, want no error", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("NewProvider() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/carlmjohnson/requests"
+)
+
+// ModelPuller is implemented by providers that can download models.
+type ModelPuller interface {
+	// PullModel downloads the model name.
+	// onProgress is called for each progress update.
+	PullModel(ctx context.Context, name string, onProgress func(PullProgress)) error
+}
+
+// PullProgress holds a progress update while pulling a model.
+// Total and Completed are set while a layer is downloading.
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Percent returns the downloaded fraction of the current layer from 0 to 1.
+func (progress PullProgress) Percent() float64 {
+	if progress.Total <= 0 {
+		return 0
+	}
+
+	return float64(progress.Completed) / float64(progress.Total)
+}
+
+// PullModel downloads the model name to the Ollama server, streaming progress
+// to onProgress.
+func (ollama Ollama) PullModel(ctx context.Context, name string, onProgress func(PullProgress)) error {
+	request := map[string]any{"model": name, "stream": true}
+
+	err := requests.
+		URL(ollama.URL + "/pull").
+		BodyJSON(request).
+		AddValidator(checkStatus).
+		Handle(func(response *http.Response) error {
+			defer func() {
+				_ = response.Body.Close()
+			}()
+
+			decoder := json.NewDecoder(response.Body)
+
+			for {
+				var progress PullProgress
+
+				if err := decoder.Decode(&progress); err == io.EOF {
+					return nil
+				} else if err != nil {
+					return fmt.Errorf("%w: %w", ErrDecodeChunk, err)
+				}
+
+				if progress.Error != "" {
+					return &StatusError{StatusCode: response.StatusCode, Message: progress.Error}
+				}
+
+				onProgress(progress)
+			}
+		}).
+		Fetch(ctx)
+
+	return classifyError(err, name)
+}
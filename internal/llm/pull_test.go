@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPullModel(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockStatusCode int
+		mockResponse   string
+		wantUpdates    int
+		wantPercent    float64
+		wantErr        bool
+		err            error
+	}{
+		{
+			name:           "streams progress",
+			mockStatusCode: http.StatusOK,
+			mockResponse: `{"status":"pulling manifest"}
+{"status":"pulling abc","digest":"sha256:abc","total":200,"completed":50}
+{"status":"pulling abc","digest":"sha256:abc","total":200,"completed":200}
+{"status":"success"}
+`,
+			wantUpdates: 4,
+			wantPercent: 1,
+		},
+		{
+			name:           "returns error from stream",
+			mockStatusCode: http.StatusOK,
+			mockResponse: `{"status":"pulling manifest"}
+{"error":"pull model manifest: file does not exist"}
+`,
+			wantUpdates: 1,
+			wantErr:     true,
+			err:         ErrUnexpectedStatus,
+		},
+		{
+			name:           "returns error for unexpected status",
+			mockStatusCode: http.StatusInternalServerError,
+			mockResponse:   `{"error":"internal server error"}`,
+			wantErr:        true,
+			err:            ErrUnexpectedStatus,
+		},
+		{
+			name:           "returns error for malformed progress",
+			mockStatusCode: http.StatusOK,
+			mockResponse:   `{"status":`,
+			wantErr:        true,
+			err:            ErrDecodeChunk,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/pull" {
+					t.Errorf("unexpected path: %s", r.URL.Path)
+				}
+
+				w.WriteHeader(tt.mockStatusCode)
+				_, _ = w.Write([]byte(tt.mockResponse))
+			}))
+			defer server.Close()
+
+			var updates []PullProgress
+			onProgress := func(progress PullProgress) {
+				updates = append(updates, progress)
+			}
+
+			err := NewOllama(server.URL).PullModel(context.Background(), "llama3", onProgress)
+
+			if len(updates) != tt.wantUpdates {
+				t.Errorf("PullModel() updates = %d, want %d", len(updates), tt.wantUpdates)
+			}
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("PullModel() err = %v, want %v", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("PullModel() err = %v, want nil", err)
+			}
+
+			if got := updates[2].Percent(); got != tt.wantPercent {
+				t.Errorf("Percent() = %v, want %v", got, tt.wantPercent)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package llm
+
+import (
+	"context"
+	"time"
+)
+
+// Retry configures retries of transient errors with exponential backoff.
+// The zero value doesn't retry.
+type Retry struct {
+	Attempts int           // Retries after the first attempt.
+	Delay    time.Duration // Delay before the first retry, doubled for each retry after.
+}
+
+// do calls fn until it succeeds, returns an error that isn't transient, or
+// runs out of attempts.
+// Errors reading a stream aren't transient so streamed chunks are never sent
+// twice.
+func (retry Retry) do(ctx context.Context, fn func() error) error {
+	delay := retry.Delay
+
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= retry.Attempts || !IsTransient(err) {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return err
+
+		case <-timer.C:
+		}
+
+		delay *= 2
+	}
+}
@@ -0,0 +1,100 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		retry        Retry
+		failures     int
+		failStatus   int
+		wantRequests int
+		wantErr      bool
+		err          error
+	}{
+		{
+			name:         "retries until the server is available",
+			retry:        Retry{Attempts: 2, Delay: time.Millisecond},
+			failures:     2,
+			failStatus:   http.StatusServiceUnavailable,
+			wantRequests: 3,
+		},
+		{
+			name:         "returns error when retries run out",
+			retry:        Retry{Attempts: 1, Delay: time.Millisecond},
+			failures:     3,
+			failStatus:   http.StatusServiceUnavailable,
+			wantRequests: 2,
+			wantErr:      true,
+			err:          ErrServerUnavailable,
+		},
+		{
+			name:         "doesn't retry errors that aren't transient",
+			retry:        Retry{Attempts: 2, Delay: time.Millisecond},
+			failures:     1,
+			failStatus:   http.StatusNotFound,
+			wantRequests: 1,
+			wantErr:      true,
+			err:          ErrModelNotFound,
+		},
+		{
+			name:         "zero value doesn't retry",
+			failures:     1,
+			failStatus:   http.StatusServiceUnavailable,
+			wantRequests: 1,
+			wantErr:      true,
+			err:          ErrServerUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+
+				if requests <= tt.failures {
+					w.WriteHeader(tt.failStatus)
+
+					return
+				}
+
+				_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":"online"},"done":true}`))
+			}))
+			defer server.Close()
+
+			ollama := NewOllama(server.URL)
+			ollama.Retry = tt.retry
+
+			got, err := ollama.Chat(context.Background(), ChatRequest{Model: "llama3"})
+
+			if requests != tt.wantRequests {
+				t.Errorf("Chat() requests = %d, want %d", requests, tt.wantRequests)
+			}
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Chat() err = %v, want %v", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Chat() err = %v, want nil", err)
+			}
+
+			if got.Content != "online" {
+				t.Errorf("Chat() content = %q, want %q", got.Content, "online")
+			}
+		})
+	}
+}
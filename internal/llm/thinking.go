@@ -0,0 +1,102 @@
+package llm
+
+import (
+	"strings"
+)
+
+const (
+	thinkOpenTag  = "<think>"
+	thinkCloseTag = "</think>"
+)
+
+// thinkingParser splits inline <think> tags out of streamed content for models
+// that don't return thinking in its own field.
+type thinkingParser struct {
+	inThinking bool
+	trimLeft   bool   // Trim whitespace following a tag
+	pending    string // Possible partial tag held back from the last chunk
+}
+
+// parse returns the thinking and content in chunk.
+// Text that could be the start of a tag is held back until the next chunk.
+func (parser *thinkingParser) parse(chunk string) (string, string) {
+	var thinking, content strings.Builder
+
+	text := parser.pending + chunk
+	parser.pending = ""
+
+	for text != "" {
+		tag := thinkOpenTag
+		if parser.inThinking {
+			tag = thinkCloseTag
+		}
+
+		if i := strings.Index(text, tag); i >= 0 {
+			parser.write(&thinking, &content, text[:i])
+			text = text[i+len(tag):]
+			parser.inThinking = !parser.inThinking
+			parser.trimLeft = true
+
+			continue
+		}
+
+		keep := partialSuffix(text, tag)
+		parser.write(&thinking, &content, text[:len(text)-keep])
+		parser.pending = text[len(text)-keep:]
+
+		break
+	}
+
+	return thinking.String(), content.String()
+}
+
+// flush returns any text held back at the end of the stream.
+func (parser *thinkingParser) flush() (string, string) {
+	var thinking, content strings.Builder
+
+	parser.write(&thinking, &content, parser.pending)
+	parser.pending = ""
+
+	return thinking.String(), content.String()
+}
+
+func (parser *thinkingParser) write(thinking, content *strings.Builder, text string) {
+	if parser.trimLeft {
+		text = strings.TrimLeft(text, " \t\r\n")
+		if text == "" {
+			return
+		}
+
+		parser.trimLeft = false
+	}
+
+	if parser.inThinking {
+		thinking.WriteString(text)
+
+		return
+	}
+
+	content.WriteString(text)
+}
+
+// splitThinking returns the thinking and content of a complete response.
+func splitThinking(text string) (string, string) {
+	var parser thinkingParser
+
+	thinking, content := parser.parse(text)
+	flushedThinking, flushedContent := parser.flush()
+
+	return thinking + flushedThinking, content + flushedContent
+}
+
+// partialSuffix returns the length of the longest suffix of text that is a
+// prefix of tag.
+func partialSuffix(text, tag string) int {
+	for n := min(len(text), len(tag)-1); n > 0; n-- {
+		if strings.HasSuffix(text, tag[:n]) {
+			return n
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,67 @@
+package llm
+
+import (
+	"testing"
+)
+
+func TestThinkingParser(t *testing.T) {
+	tests := []struct {
+		name         string
+		chunks       []string
+		wantThinking string
+		wantContent  string
+	}{
+		{
+			name:        "passes through content without tags",
+			chunks:      []string{"Hello", " there!"},
+			wantContent: "Hello there!",
+		},
+		{
+			name:         "splits thinking in a single chunk",
+			chunks:       []string{"<think>hmm</think>\n\nHello"},
+			wantThinking: "hmm",
+			wantContent:  "Hello",
+		},
+		{
+			name:         "splits tags across chunk boundaries",
+			chunks:       []string{"<thi", "nk>let me ", "think</th", "ink>Hello", " there"},
+			wantThinking: "let me think",
+			wantContent:  "Hello there",
+		},
+		{
+			name:        "flushes partial tag that never completes",
+			chunks:      []string{"a <", "b"},
+			wantContent: "a <b",
+		},
+		{
+			name:         "handles unterminated thinking",
+			chunks:       []string{"<think>still going"},
+			wantThinking: "still going",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var parser thinkingParser
+			var gotThinking, gotContent string
+
+			for _, chunk := range tt.chunks {
+				thinking, content := parser.parse(chunk)
+				gotThinking += thinking
+				gotContent += content
+			}
+
+			thinking, content := parser.flush()
+			gotThinking += thinking
+			gotContent += content
+
+			if gotThinking != tt.wantThinking {
+				t.Errorf("parse() thinking = %q, want %q", gotThinking, tt.wantThinking)
+			}
+
+			if gotContent != tt.wantContent {
+				t.Errorf("parse() content = %q, want %q", gotContent, tt.wantContent)
+			}
+		})
+	}
+}
@@ -24,14 +24,31 @@ type ToolParameters struct {
 	Properties map[string]ToolProperty `json:"properties"`
 }
 
-// ToolProperty describes a single parameter that a tool accepts.
+// ToolProperty describes a single parameter that a tool accepts as a JSON
+// Schema. Items and Properties describe the elements of arrays and the fields
+// of objects.
 type ToolProperty struct {
-	Type        string `json:"type"`
-	Description string `json:"description"`
+	Type        string                  `json:"type,omitempty"`
+	Description string                  `json:"description,omitempty"`
+	Enum        []any                   `json:"enum,omitempty"`
+	Default     any                     `json:"default,omitempty"`
+	Items       *ToolProperty           `json:"items,omitempty"`
+	Properties  map[string]ToolProperty `json:"properties,omitempty"`
+	Required    []string                `json:"required,omitempty"`
+	AnyOf       []ToolProperty          `json:"anyOf,omitempty"`
+	Minimum     *float64                `json:"minimum,omitempty"`
+	Maximum     *float64                `json:"maximum,omitempty"`
+	MinLength   *int                    `json:"minLength,omitempty"`
+	MaxLength   *int                    `json:"maxLength,omitempty"`
+	Pattern     string                  `json:"pattern,omitempty"`
+	MinItems    *int                    `json:"minItems,omitempty"`
+	MaxItems    *int                    `json:"maxItems,omitempty"`
 }
 
 // ToolCall represents the LLM's request to invoke a tool.
+// ID links the call to its result, it's set by the provider or the tool loop.
 type ToolCall struct {
+	ID       string `json:"id,omitempty"`
 	Function struct {
 		Name      string          `json:"name"`
 		Arguments json.RawMessage `json:"arguments"`
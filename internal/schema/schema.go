@@ -0,0 +1,520 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrSchemaLoad    = errors.New("failed to load schema blueprint")
+	ErrSchemaInvalid = errors.New("schema blueprint corrupted")
+	ErrValidation    = errors.New("data failed schema validation")
+)
+
+// Schema is a JSON Schema used to constrain and validate JSON data.
+// It supports the type, enum, const, object, array, string, number, and
+// combinator keywords, and $ref to the root or to $defs and definitions.
+// Other unsupported keywords are ignored.
+type Schema struct {
+	Raw json.RawMessage `json:"-"` // Schema as it was loaded
+
+	Ref                  string             `json:"$ref,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+	Type                 typeList           `json:"type,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Const                any                `json:"const,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *additional        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+
+	pattern *regexp.Regexp
+	ref     *Schema // Schema Ref points to
+}
+
+// typeList holds the allowed types, the type keyword can be a string or an
+// array of strings.
+type typeList []string
+
+func (types *typeList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*types = typeList{single}
+
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("type must be a string or array of strings: %w", err)
+	}
+
+	*types = multiple
+
+	return nil
+}
+
+func (types typeList) MarshalJSON() ([]byte, error) {
+	if len(types) == 1 {
+		return json.Marshal(types[0])
+	}
+
+	return json.Marshal([]string(types))
+}
+
+// additional holds the additionalProperties keyword, which can be a boolean or
+// a schema.
+type additional struct {
+	Allowed bool
+	Schema  *Schema
+}
+
+func (additional *additional) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &additional.Allowed); err == nil {
+		return nil
+	}
+
+	additional.Allowed = true
+
+	return json.Unmarshal(data, &additional.Schema)
+}
+
+func (additional additional) MarshalJSON() ([]byte, error) {
+	if additional.Schema != nil {
+		return json.Marshal(additional.Schema)
+	}
+
+	return json.Marshal(additional.Allowed)
+}
+
+// Load reads and parses the schema file at path.
+func Load(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSchemaLoad, err)
+	}
+
+	return Parse(data)
+}
+
+// Parse parses data as a JSON Schema.
+func Parse(data []byte) (*Schema, error) {
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSchemaInvalid, err)
+	}
+
+	if err := schema.compile(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSchemaInvalid, err)
+	}
+
+	if err := schema.resolve(&schema); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSchemaInvalid, err)
+	}
+
+	schema.Raw = json.RawMessage(bytes.TrimSpace(data))
+
+	return &schema, nil
+}
+
+// compile compiles the patterns in the schema and its subschemas.
+func (schema *Schema) compile() error {
+	if schema.Pattern != "" {
+		pattern, err := regexp.Compile(schema.Pattern)
+		if err != nil {
+			return err
+		}
+
+		schema.pattern = pattern
+	}
+
+	for _, subschema := range schema.subschemas() {
+		if err := subschema.compile(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolve points the references in the schema and its subschemas at the
+// schemas they name in root.
+// Returns an error for references outside the document or to schemas root
+// doesn't have, and for references that only lead back to themselves.
+func (schema *Schema) resolve(root *Schema) error {
+	if schema.Ref != "" {
+		target, err := root.lookup(schema.Ref)
+		if err != nil {
+			return err
+		}
+
+		schema.ref = target
+	}
+
+	for _, subschema := range schema.subschemas() {
+		if err := subschema.resolve(root); err != nil {
+			return err
+		}
+	}
+
+	// A chain of references that loops never reaches a schema to validate
+	// against.
+	seen := map[*Schema]bool{}
+	for target := schema; target.ref != nil; target = target.ref {
+		if seen[target] {
+			return fmt.Errorf("$ref %q is circular", schema.Ref)
+		}
+
+		seen[target] = true
+	}
+
+	return nil
+}
+
+// lookup returns the schema ref points to in the schema. Only the schema and
+// its $defs and definitions can be referenced.
+func (schema *Schema) lookup(ref string) (*Schema, error) {
+	if ref == "#" {
+		return schema, nil
+	}
+
+	for _, prefix := range []string{"#/$defs/", "#/definitions/"} {
+		name, ok := strings.CutPrefix(ref, prefix)
+		if !ok {
+			continue
+		}
+
+		// Names are JSON pointer tokens.
+		name = strings.NewReplacer("~1", "/", "~0", "~").Replace(name)
+
+		definitions := schema.Defs
+		if prefix == "#/definitions/" {
+			definitions = schema.Definitions
+		}
+
+		if target, ok := definitions[name]; ok {
+			return target, nil
+		}
+
+		return nil, fmt.Errorf("$ref %q not found", ref)
+	}
+
+	return nil, fmt.Errorf("unsupported $ref %q: only references to the root, $defs, and definitions are supported", ref)
+}
+
+// subschemas returns the schemas nested in the schema, not the ones it
+// references.
+func (schema *Schema) subschemas() []*Schema {
+	var subschemas []*Schema
+
+	if schema.Items != nil {
+		subschemas = append(subschemas, schema.Items)
+	}
+
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		subschemas = append(subschemas, schema.AdditionalProperties.Schema)
+	}
+
+	for _, definitions := range []map[string]*Schema{schema.Properties, schema.Defs, schema.Definitions} {
+		for _, subschema := range definitions {
+			if subschema != nil {
+				subschemas = append(subschemas, subschema)
+			}
+		}
+	}
+
+	for _, combinator := range [][]*Schema{schema.AnyOf, schema.OneOf, schema.AllOf} {
+		for _, subschema := range combinator {
+			if subschema != nil {
+				subschemas = append(subschemas, subschema)
+			}
+		}
+	}
+
+	return subschemas
+}
+
+// Validate decodes data as JSON and validates it against the schema.
+// Returns ErrValidation with the location of the first failure.
+func (schema *Schema) Validate(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("%w: invalid JSON: %w", ErrValidation, err)
+	}
+
+	return schema.ValidateValue(value)
+}
+
+// ValidateValue validates a decoded JSON value against the schema.
+// Returns ErrValidation with the location of the first failure.
+func (schema *Schema) ValidateValue(value any) error {
+	if err := schema.validate(value, "$"); err != nil {
+		return fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	return nil
+}
+
+func (schema *Schema) validate(value any, path string) error {
+	if schema.ref != nil {
+		if err := schema.ref.validate(value, path); err != nil {
+			return err
+		}
+	}
+
+	if len(schema.Type) > 0 && !slices.ContainsFunc(schema.Type, func(t string) bool { return isType(value, t) }) {
+		return fmt.Errorf("%s: expected %s, got %s", path, strings.Join(schema.Type, " or "), typeOf(value))
+	}
+
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(e any) bool { return equal(e, value) }) {
+		return fmt.Errorf("%s: value must be one of %s", path, marshal(schema.Enum))
+	}
+
+	if schema.Const != nil && !equal(schema.Const, value) {
+		return fmt.Errorf("%s: value must be %s", path, marshal(schema.Const))
+	}
+
+	var err error
+
+	switch value := value.(type) {
+	case map[string]any:
+		err = schema.validateObject(value, path)
+
+	case []any:
+		err = schema.validateArray(value, path)
+
+	case string:
+		err = schema.validateString(value, path)
+
+	case float64:
+		err = schema.validateNumber(value, path)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return schema.validateCombinators(value, path)
+}
+
+func (schema *Schema) validateObject(object map[string]any, path string) error {
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			return fmt.Errorf("%s: missing required property %q", path, name)
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	for _, name := range names {
+		propertyPath := path + "." + name
+
+		if property, ok := schema.Properties[name]; ok {
+			if err := property.validate(object[name], propertyPath); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if schema.AdditionalProperties == nil {
+			continue
+		}
+
+		if !schema.AdditionalProperties.Allowed {
+			return fmt.Errorf("%s: additional property not allowed", propertyPath)
+		}
+
+		if schema.AdditionalProperties.Schema != nil {
+			if err := schema.AdditionalProperties.Schema.validate(object[name], propertyPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (schema *Schema) validateArray(array []any, path string) error {
+	if schema.MinItems != nil && len(array) < *schema.MinItems {
+		return fmt.Errorf("%s: expected at least %d items, got %d", path, *schema.MinItems, len(array))
+	}
+
+	if schema.MaxItems != nil && len(array) > *schema.MaxItems {
+		return fmt.Errorf("%s: expected at most %d items, got %d", path, *schema.MaxItems, len(array))
+	}
+
+	if schema.Items == nil {
+		return nil
+	}
+
+	for i, item := range array {
+		if err := schema.Items.validate(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (schema *Schema) validateString(value, path string) error {
+	length := len([]rune(value))
+
+	if schema.MinLength != nil && length < *schema.MinLength {
+		return fmt.Errorf("%s: expected at least %d characters, got %d", path, *schema.MinLength, length)
+	}
+
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		return fmt.Errorf("%s: expected at most %d characters, got %d", path, *schema.MaxLength, length)
+	}
+
+	if schema.pattern != nil && !schema.pattern.MatchString(value) {
+		return fmt.Errorf("%s: value does not match pattern %q", path, schema.Pattern)
+	}
+
+	return nil
+}
+
+func (schema *Schema) validateNumber(value float64, path string) error {
+	if schema.Minimum != nil && value < *schema.Minimum {
+		return fmt.Errorf("%s: expected minimum %g, got %g", path, *schema.Minimum, value)
+	}
+
+	if schema.Maximum != nil && value > *schema.Maximum {
+		return fmt.Errorf("%s: expected maximum %g, got %g", path, *schema.Maximum, value)
+	}
+
+	if schema.ExclusiveMinimum != nil && value <= *schema.ExclusiveMinimum {
+		return fmt.Errorf("%s: expected greater than %g, got %g", path, *schema.ExclusiveMinimum, value)
+	}
+
+	if schema.ExclusiveMaximum != nil && value >= *schema.ExclusiveMaximum {
+		return fmt.Errorf("%s: expected less than %g, got %g", path, *schema.ExclusiveMaximum, value)
+	}
+
+	return nil
+}
+
+func (schema *Schema) validateCombinators(value any, path string) error {
+	for _, subschema := range schema.AllOf {
+		if err := subschema.validate(value, path); err != nil {
+			return err
+		}
+	}
+
+	if len(schema.AnyOf) > 0 && !slices.ContainsFunc(schema.AnyOf, func(s *Schema) bool { return s.validate(value, path) == nil }) {
+		return fmt.Errorf("%s: value does not match any allowed schema", path)
+	}
+
+	if len(schema.OneOf) > 0 {
+		matches := 0
+		for _, subschema := range schema.OneOf {
+			if subschema.validate(value, path) == nil {
+				matches++
+			}
+		}
+
+		if matches != 1 {
+			return fmt.Errorf("%s: value must match exactly one schema, matched %d", path, matches)
+		}
+	}
+
+	return nil
+}
+
+// isType returns true if value is the JSON Schema type t.
+func isType(value any, t string) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+
+	case "array":
+		_, ok := value.([]any)
+		return ok
+
+	case "string":
+		_, ok := value.(string)
+		return ok
+
+	case "number":
+		_, ok := value.(float64)
+		return ok
+
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+
+	case "null":
+		return value == nil
+	}
+
+	return false
+}
+
+// typeOf returns the JSON Schema type name of value.
+func typeOf(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+
+	case []any:
+		return "array"
+
+	case string:
+		return "string"
+
+	case float64:
+		return "number"
+
+	case bool:
+		return "boolean"
+	}
+
+	return "null"
+}
+
+// equal compares JSON values by their encoding, map keys are encoded in sorted
+// order so objects compare equal regardless of key order.
+func equal(a, b any) bool {
+	return marshal(a) == marshal(b)
+}
+
+func marshal(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(data)
+}
@@ -0,0 +1,344 @@
+package schema
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const personSchema = `{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string", "minLength": 1},
+		"age": {"type": "integer", "minimum": 0},
+		"role": {"enum": ["netrunner", "fixer", "solo"]},
+		"implants": {"type": "array", "items": {"type": "string"}, "maxItems": 2},
+		"handle": {"type": ["string", "null"], "pattern": "^[a-z]+$"}
+	},
+	"required": ["name", "age"],
+	"additionalProperties": false
+}`
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{
+			name: "valid object",
+			data: `{"name": "Case", "age": 24, "role": "netrunner", "implants": ["deck"], "handle": "case"}`,
+		},
+		{
+			name: "allows null for multiple types",
+			data: `{"name": "Case", "age": 24, "handle": null}`,
+		},
+		{
+			name:    "returns error for invalid JSON",
+			data:    `{"name": `,
+			wantErr: "invalid JSON",
+		},
+		{
+			name:    "returns error for wrong type",
+			data:    `[]`,
+			wantErr: "$: expected object, got array",
+		},
+		{
+			name:    "returns error for missing required property",
+			data:    `{"name": "Case"}`,
+			wantErr: `$: missing required property "age"`,
+		},
+		{
+			name:    "returns error for non integer",
+			data:    `{"name": "Case", "age": 24.5}`,
+			wantErr: "$.age: expected integer, got number",
+		},
+		{
+			name:    "returns error for number below minimum",
+			data:    `{"name": "Case", "age": -1}`,
+			wantErr: "$.age: expected minimum 0, got -1",
+		},
+		{
+			name:    "returns error for value not in enum",
+			data:    `{"name": "Case", "age": 24, "role": "corpo"}`,
+			wantErr: `$.role: value must be one of ["netrunner","fixer","solo"]`,
+		},
+		{
+			name:    "returns error for invalid array item",
+			data:    `{"name": "Case", "age": 24, "implants": [1]}`,
+			wantErr: "$.implants[0]: expected string, got number",
+		},
+		{
+			name:    "returns error for too many items",
+			data:    `{"name": "Case", "age": 24, "implants": ["a", "b", "c"]}`,
+			wantErr: "$.implants: expected at most 2 items, got 3",
+		},
+		{
+			name:    "returns error for short string",
+			data:    `{"name": "", "age": 24}`,
+			wantErr: "$.name: expected at least 1 characters, got 0",
+		},
+		{
+			name:    "returns error for pattern mismatch",
+			data:    `{"name": "Case", "age": 24, "handle": "Case"}`,
+			wantErr: `$.handle: value does not match pattern "^[a-z]+$"`,
+		},
+		{
+			name:    "returns error for additional property",
+			data:    `{"name": "Case", "age": 24, "corp": "Sense/Net"}`,
+			wantErr: "$.corp: additional property not allowed",
+		},
+	}
+
+	schema, err := Parse([]byte(personSchema))
+	if err != nil {
+		t.Fatalf("Parse() err = %v, want nil", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Validate([]byte(tt.data))
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() err = %v, want nil", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, ErrValidation) {
+				t.Fatalf("Validate() err = %v, want %v", err, ErrValidation)
+			}
+
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() err = %q, want message containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCombinators(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		data    string
+		wantErr bool
+	}{
+		{
+			name:   "anyOf matches one",
+			schema: `{"anyOf": [{"type": "string"}, {"type": "number"}]}`,
+			data:   `42`,
+		},
+		{
+			name:    "anyOf matches none",
+			schema:  `{"anyOf": [{"type": "string"}, {"type": "number"}]}`,
+			data:    `true`,
+			wantErr: true,
+		},
+		{
+			name:    "oneOf matches both",
+			schema:  `{"oneOf": [{"type": "number"}, {"type": "integer"}]}`,
+			data:    `42`,
+			wantErr: true,
+		},
+		{
+			name:    "allOf fails one",
+			schema:  `{"allOf": [{"type": "number"}, {"maximum": 10}]}`,
+			data:    `42`,
+			wantErr: true,
+		},
+		{
+			name:   "additionalProperties schema",
+			schema: `{"type": "object", "additionalProperties": {"type": "boolean"}}`,
+			data:   `{"online": true}`,
+		},
+		{
+			name:   "const matches object regardless of key order",
+			schema: `{"const": {"a": 1, "b": 2}}`,
+			data:   `{"b": 2, "a": 1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := Parse([]byte(tt.schema))
+			if err != nil {
+				t.Fatalf("Parse() err = %v, want nil", err)
+			}
+
+			err = schema.Validate([]byte(tt.data))
+
+			if tt.wantErr && !errors.Is(err, ErrValidation) {
+				t.Errorf("Validate() err = %v, want %v", err, ErrValidation)
+			}
+
+			if !tt.wantErr && err != nil {
+				t.Errorf("Validate() err = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestValidateRefs(t *testing.T) {
+	const treeSchema = `{
+		"$defs": {"node": {"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}, "children": {"type": "array", "items": {"$ref": "#/$defs/node"}}}}},
+		"$ref": "#/$defs/node"
+	}`
+
+	tests := []struct {
+		name    string
+		schema  string
+		data    string
+		wantErr bool
+	}{
+		{
+			name:   "$defs reference",
+			schema: `{"type": "object", "properties": {"rank": {"$ref": "#/$defs/rank"}}, "$defs": {"rank": {"enum": ["street", "corpo"]}}}`,
+			data:   `{"rank": "corpo"}`,
+		},
+		{
+			name:    "$defs reference fails",
+			schema:  `{"type": "object", "properties": {"rank": {"$ref": "#/$defs/rank"}}, "$defs": {"rank": {"enum": ["street", "corpo"]}}}`,
+			data:    `{"rank": "nomad"}`,
+			wantErr: true,
+		},
+		{
+			name:    "definitions reference fails",
+			schema:  `{"type": "array", "items": {"$ref": "#/definitions/port"}, "definitions": {"port": {"type": "integer", "maximum": 65535}}}`,
+			data:    `[80, 70000]`,
+			wantErr: true,
+		},
+		{
+			name:   "recursive reference",
+			schema: treeSchema,
+			data:   `{"name": "root", "children": [{"name": "leaf", "children": []}]}`,
+		},
+		{
+			name:    "recursive reference fails deep",
+			schema:  treeSchema,
+			data:    `{"name": "root", "children": [{"children": []}]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := Parse([]byte(tt.schema))
+			if err != nil {
+				t.Fatalf("Parse() err = %v, want nil", err)
+			}
+
+			err = schema.Validate([]byte(tt.data))
+
+			if tt.wantErr && !errors.Is(err, ErrValidation) {
+				t.Errorf("Validate() err = %v, want %v", err, ErrValidation)
+			}
+
+			if !tt.wantErr && err != nil {
+				t.Errorf("Validate() err = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr string
+	}{
+		{
+			name:    "returns error for remote reference",
+			schema:  `{"$ref": "https://example.com/person.json"}`,
+			wantErr: "unsupported $ref",
+		},
+		{
+			name:    "returns error for reference into properties",
+			schema:  `{"properties": {"a": {"type": "string"}, "b": {"$ref": "#/properties/a"}}}`,
+			wantErr: "unsupported $ref",
+		},
+		{
+			name:    "returns error for missing definition",
+			schema:  `{"$ref": "#/$defs/missing"}`,
+			wantErr: "not found",
+		},
+		{
+			name:    "returns error for circular reference",
+			schema:  `{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"$ref": "#/$defs/a"}}}`,
+			wantErr: "circular",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.schema))
+
+			if !errors.Is(err, ErrSchemaInvalid) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() err = %v, want %v containing %q", err, ErrSchemaInvalid, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	validPath := filepath.Join(dir, "valid.json")
+	if err := os.WriteFile(validPath, []byte(personSchema), 0600); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+
+	invalidPath := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalidPath, []byte(`{"pattern": "["}`), 0600); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+		err     error
+	}{
+		{
+			name: "loads schema",
+			path: validPath,
+		},
+		{
+			name:    "returns error for missing file",
+			path:    filepath.Join(dir, "missing.json"),
+			wantErr: true,
+			err:     ErrSchemaLoad,
+		},
+		{
+			name:    "returns error for invalid pattern",
+			path:    invalidPath,
+			wantErr: true,
+			err:     ErrSchemaInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(tt.path)
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Load() err = %v, want %v", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Load() err = %v, want nil", err)
+			}
+
+			if string(got.Raw) != personSchema {
+				t.Errorf("Load() raw = %s, want %s", got.Raw, personSchema)
+			}
+		})
+	}
+}
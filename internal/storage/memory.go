@@ -0,0 +1,207 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+var ErrMemoryNotFound = errors.New("memory not found in memory banks")
+
+// Memory is a note kept between threads.
+type Memory struct {
+	ID        string    `json:"id"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MemoryStore manages the memories file.
+type MemoryStore struct {
+	path string
+	mu   sync.RWMutex
+}
+
+// NewMemoryStore returns a store for the memories file in the base directory.
+// The file is created when the first memory is saved.
+func NewMemoryStore(baseDir string) *MemoryStore {
+	return &MemoryStore{path: filepath.Join(baseDir, "memories.json")}
+}
+
+// readMemories returns the memories in the file, none if it doesn't exist.
+// Assumes the caller has acquired the lock.
+func (store *MemoryStore) readMemories() ([]Memory, error) {
+	bytes, err := os.ReadFile(store.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Memory{}, nil
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrStorageAccess, err)
+	}
+
+	var memories []Memory
+	if err := json.Unmarshal(bytes, &memories); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptedData, err)
+	}
+
+	return memories, nil
+}
+
+// writeMemories writes the memories to the file.
+// Assumes the caller has acquired the lock.
+func (store *MemoryStore) writeMemories(memories []Memory) error {
+	bytes, err := json.MarshalIndent(memories, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCorruptedData, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(store.path), 0750); err != nil {
+		return fmt.Errorf("%w: %w", ErrStorageAccess, err)
+	}
+
+	if err := os.WriteFile(store.path, bytes, 0640); err != nil {
+		return fmt.Errorf("%w: %w", ErrStorageAccess, err)
+	}
+
+	return nil
+}
+
+// Add saves a new Memory with content.
+func (store *MemoryStore) Add(content string) (*Memory, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	memories, err := store.readMemories()
+	if err != nil {
+		return nil, err
+	}
+
+	memory := Memory{
+		ID:        newMemoryID(memories),
+		Content:   strings.TrimSpace(content),
+		CreatedAt: time.Now(),
+	}
+
+	if err := store.writeMemories(append(memories, memory)); err != nil {
+		return nil, err
+	}
+
+	return &memory, nil
+}
+
+// List returns all memories, oldest first.
+func (store *MemoryStore) List() ([]Memory, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	return store.readMemories()
+}
+
+// Search returns up to limit memories with words starting with the words of
+// query, those matching the most words first and the newest first among equals.
+// Returns the newest memories if query has no words, 0 limit returns all.
+func (store *MemoryStore) Search(query string, limit int) ([]Memory, error) {
+	memories, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Reverse(memories)
+
+	words := searchWords(query)
+	if len(words) > 0 {
+		scores := map[string]int{}
+		var matches []Memory
+
+		for _, memory := range memories {
+			content := searchWords(memory.Content)
+
+			for _, word := range words {
+				if slices.ContainsFunc(content, func(contentWord string) bool { return strings.HasPrefix(contentWord, word) }) {
+					scores[memory.ID]++
+				}
+			}
+
+			if scores[memory.ID] > 0 {
+				matches = append(matches, memory)
+			}
+		}
+
+		slices.SortStableFunc(matches, func(a, b Memory) int {
+			return scores[b.ID] - scores[a.ID]
+		})
+
+		memories = matches
+	}
+
+	if limit > 0 && len(memories) > limit {
+		memories = memories[:limit]
+	}
+
+	return memories, nil
+}
+
+// Delete removes the Memory with id.
+// Returns ErrMemoryNotFound if there's no memory with id.
+func (store *MemoryStore) Delete(id string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	memories, err := store.readMemories()
+	if err != nil {
+		return err
+	}
+
+	index := slices.IndexFunc(memories, func(memory Memory) bool { return memory.ID == id })
+	if index < 0 {
+		return fmt.Errorf("%w: %s", ErrMemoryNotFound, id)
+	}
+
+	return store.writeMemories(slices.Delete(memories, index, index+1))
+}
+
+// newMemoryID returns a short ID not used by memories.
+func newMemoryID(memories []Memory) string {
+	for {
+		id := uuid.New().String()[:8]
+
+		if !slices.ContainsFunc(memories, func(memory Memory) bool { return memory.ID == id }) {
+			return id
+		}
+	}
+}
+
+// stopWords are common words left out of searches so they don't match every
+// memory.
+var stopWords = []string{
+	"about", "and", "are", "but", "can", "could", "for", "from", "have", "how",
+	"into", "its", "not", "our", "should", "that", "the", "their", "them",
+	"there", "they", "this", "was", "were", "what", "when", "which", "who",
+	"why", "will", "with", "would", "you", "your",
+}
+
+// searchWords returns the lower cased words in text, skipping stop words and
+// words shorter than three letters.
+func searchWords(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var words []string
+	for _, field := range fields {
+		if len([]rune(field)) >= 3 && !slices.Contains(stopWords, field) {
+			words = append(words, field)
+		}
+	}
+
+	return words
+}
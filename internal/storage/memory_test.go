@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore(t.TempDir())
+
+	memories, err := store.List()
+	if err != nil || len(memories) != 0 {
+		t.Fatalf("List() = %v, %v, want no memories before the first save", memories, err)
+	}
+
+	for _, content := range []string{"The team deploys with Argo CD", "  Prefer cobra and viper for Go CLIs  ", "Repos live under the netrunners org"} {
+		if _, err := store.Add(content); err != nil {
+			t.Fatalf("Add() err = %v, want nil", err)
+		}
+	}
+
+	memories, err = store.List()
+	if err != nil {
+		t.Fatalf("List() err = %v, want nil", err)
+	}
+
+	if len(memories) != 3 || memories[1].Content != "Prefer cobra and viper for Go CLIs" || memories[1].ID == "" {
+		t.Fatalf("List() = %+v, want 3 trimmed memories with IDs, oldest first", memories)
+	}
+
+	if err := store.Delete(memories[0].ID); err != nil {
+		t.Fatalf("Delete() err = %v, want nil", err)
+	}
+
+	if err := store.Delete(memories[0].ID); !errors.Is(err, ErrMemoryNotFound) {
+		t.Errorf("Delete() err = %v, want %v", err, ErrMemoryNotFound)
+	}
+
+	memories, err = store.List()
+	if err != nil || len(memories) != 2 {
+		t.Errorf("List() = %v, %v, want 2 memories after delete", memories, err)
+	}
+}
+
+func TestMemoryStore_Search(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []string
+	}{
+		{
+			name:  "returns matching memories with the most shared words first",
+			query: "which Go libraries for a CLI tool?",
+			want:  []string{"Prefer cobra and viper for Go CLI tools", "Use the CLI from the platform repo"},
+		},
+		{
+			name:  "ignores case and punctuation",
+			query: "ARGO",
+			want:  []string{"The team deploys with Argo CD"},
+		},
+		{
+			name:  "returns newest memories for a query without words",
+			query: "?",
+			limit: 2,
+			want:  []string{"Use the CLI from the platform repo", "Prefer cobra and viper for Go CLI tools"},
+		},
+		{
+			name:  "returns nothing when no words match",
+			query: "braindance",
+		},
+		{
+			name:  "ignores stop words",
+			query: "what is the plan for today",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore(t.TempDir())
+
+			for _, content := range []string{"The team deploys with Argo CD", "Prefer cobra and viper for Go CLI tools", "Use the CLI from the platform repo"} {
+				if _, err := store.Add(content); err != nil {
+					t.Fatalf("Add() err = %v, want nil", err)
+				}
+			}
+
+			got, err := store.Search(tt.query, tt.limit)
+			if err != nil {
+				t.Fatalf("Search() err = %v, want nil", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Search() = %+v, want %v", got, tt.want)
+			}
+
+			for i, want := range tt.want {
+				if got[i].Content != want {
+					t.Errorf("Search()[%d] = %q, want %q", i, got[i].Content, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStore_CorruptedFile(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "memories.json"), []byte("{not json"), 0640); err != nil {
+		t.Fatalf("failed to write memories file: %v", err)
+	}
+
+	if _, err := NewMemoryStore(dir).List(); !errors.Is(err, ErrCorruptedData) {
+		t.Errorf("List() err = %v, want %v", err, ErrCorruptedData)
+	}
+}
@@ -29,15 +29,28 @@ type Thread struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// Metadata holds details about how a message was generated so runs can be
+// reproduced.
+type Metadata struct {
+	Options     *llm.Options  `json:"options,omitempty"`     // Model options used for the request
+	Metrics     *llm.Metrics  `json:"metrics,omitempty"`     // Token usage and timing of the response
+	Interrupted bool          `json:"interrupted,omitempty"` // True if the response was cancelled before it finished
+	Duration    time.Duration `json:"duration,omitempty"`    // Time a tool call took, set on tool results
+}
+
 // Message wraps llm.ChatMessage with storage metadata.
 type Message struct {
-	ID        string         `json:"id"`        // UUID
-	ThreadID  string         `json:"thread_id"` // Foreign key to Thread
-	Role      llm.Role       `json:"role"`
-	Content   string         `json:"content"`
-	Images    []string       `json:"images,omitempty"`
-	ToolCalls []llm.ToolCall `json:"tool_calls,omitempty"`
-	CreatedAt time.Time      `json:"created_at"`
+	ID         string         `json:"id"`        // UUID
+	ThreadID   string         `json:"thread_id"` // Foreign key to Thread
+	Role       llm.Role       `json:"role"`
+	Content    string         `json:"content"`
+	Thinking   string         `json:"thinking,omitempty"`
+	Images     []string       `json:"images,omitempty"`
+	ToolCalls  []llm.ToolCall `json:"tool_calls,omitempty"`
+	ToolName   string         `json:"tool_name,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+	Metadata
+	CreatedAt time.Time `json:"created_at"`
 }
 
 // Conversation is the wrapper that bundles a thread with its messages.
@@ -233,8 +246,8 @@ func (store *Store) ListThreads() ([]Thread, error) {
 	return threads, nil
 }
 
-// AddMessage adds a new Message to a Conversation.
-func (store *Store) AddMessage(threadID string, chatMsg llm.ChatMessage) (*Message, error) {
+// AddMessage adds a new Message with its metadata to a Conversation.
+func (store *Store) AddMessage(threadID string, chatMsg llm.ChatMessage, metadata Metadata) (*Message, error) {
 	store.mu.Lock()
 	defer store.mu.Unlock()
 
@@ -246,13 +259,17 @@ func (store *Store) AddMessage(threadID string, chatMsg llm.ChatMessage) (*Messa
 	now := time.Now()
 
 	message := Message{
-		ID:        uuid.New().String(),
-		ThreadID:  threadID,
-		Role:      chatMsg.Role,
-		Content:   chatMsg.Content,
-		Images:    chatMsg.Images,
-		ToolCalls: chatMsg.ToolCalls,
-		CreatedAt: now,
+		ID:         uuid.New().String(),
+		ThreadID:   threadID,
+		Role:       chatMsg.Role,
+		Content:    chatMsg.Content,
+		Thinking:   chatMsg.Thinking,
+		Images:     chatMsg.Images,
+		ToolCalls:  chatMsg.ToolCalls,
+		ToolName:   chatMsg.ToolName,
+		ToolCallID: chatMsg.ToolCallID,
+		Metadata:   metadata,
+		CreatedAt:  now,
 	}
 
 	conversation.Messages = append(conversation.Messages, message)
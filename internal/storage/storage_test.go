@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/theantichris/ghost/v3/internal/llm"
 )
 
@@ -393,10 +394,13 @@ func TestAddMessage(t *testing.T) {
 	originalUpdatedAt := thread.UpdatedAt
 	time.Sleep(10 * time.Millisecond)
 
+	temperature := 0.7
+
 	tests := []struct {
 		name     string
 		threadID string
 		chatMsg  llm.ChatMessage
+		metadata Metadata
 		wantErr  bool
 		err      error
 	}{
@@ -425,6 +429,23 @@ func TestAddMessage(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:     "adds tool result with tool name and call ID",
+			threadID: thread.ID,
+			chatMsg:  llm.ChatMessage{Role: llm.RoleTool, Content: "search results", ToolName: "search", ToolCallID: "call_a"},
+			metadata: Metadata{Duration: time.Second},
+		},
+		{
+			name:     "adds message with thinking",
+			threadID: thread.ID,
+			chatMsg:  llm.ChatMessage{Role: llm.RoleAssistant, Content: "Hello, runner", Thinking: "greet them"},
+		},
+		{
+			name:     "adds message with options metadata",
+			threadID: thread.ID,
+			chatMsg:  llm.ChatMessage{Role: llm.RoleAssistant, Content: "Hello, runner"},
+			metadata: Metadata{Options: &llm.Options{Temperature: &temperature, Stop: []string{"END"}}},
+		},
 		{
 			name:     "returns error for nonexistent thread",
 			threadID: "nonexistent-id",
@@ -436,7 +457,7 @@ func TestAddMessage(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			msg, err := store.AddMessage(tt.threadID, tt.chatMsg)
+			msg, err := store.AddMessage(tt.threadID, tt.chatMsg, tt.metadata)
 
 			if tt.wantErr {
 				if err == nil {
@@ -470,6 +491,14 @@ func TestAddMessage(t *testing.T) {
 				t.Errorf("AddMessage() Content = %s, want %s", msg.Content, tt.chatMsg.Content)
 			}
 
+			if msg.Thinking != tt.chatMsg.Thinking {
+				t.Errorf("AddMessage() Thinking = %s, want %s", msg.Thinking, tt.chatMsg.Thinking)
+			}
+
+			if msg.ToolName != tt.chatMsg.ToolName || msg.ToolCallID != tt.chatMsg.ToolCallID {
+				t.Errorf("AddMessage() tool = %q %q, want %q %q", msg.ToolName, msg.ToolCallID, tt.chatMsg.ToolName, tt.chatMsg.ToolCallID)
+			}
+
 			if msg.CreatedAt.IsZero() {
 				t.Error("AddMessage() CreatedAt is zero")
 			}
@@ -482,6 +511,16 @@ func TestAddMessage(t *testing.T) {
 				t.Errorf("AddMessage() ToolCalls len = %d, want %d", len(msg.ToolCalls), len(tt.chatMsg.ToolCalls))
 			}
 
+			messages, err := store.GetMessages(tt.threadID)
+			if err != nil {
+				t.Fatalf("GetMessages() err = %v", err)
+			}
+
+			stored := messages[len(messages)-1]
+			if diff := cmp.Diff(tt.metadata, stored.Metadata); diff != "" {
+				t.Errorf("AddMessage() stored metadata mismatch (-want +got):\n%s", diff)
+			}
+
 			// Verify thread timestamp updated
 			updated, err := store.GetThread(tt.threadID)
 			if err != nil {
@@ -504,12 +543,12 @@ func TestGetMessages(t *testing.T) {
 	}
 
 	// Add messages for the success case
-	_, err = store.AddMessage(thread.ID, llm.ChatMessage{Role: llm.RoleUser, Content: "Hello"})
+	_, err = store.AddMessage(thread.ID, llm.ChatMessage{Role: llm.RoleUser, Content: "Hello"}, Metadata{})
 	if err != nil {
 		t.Fatalf("AddMessage() err = %v", err)
 	}
 
-	_, err = store.AddMessage(thread.ID, llm.ChatMessage{Role: llm.RoleAssistant, Content: "Hi there!"})
+	_, err = store.AddMessage(thread.ID, llm.ChatMessage{Role: llm.RoleAssistant, Content: "Hi there!"}, Metadata{})
 	if err != nil {
 		t.Fatalf("AddMessage() err = %v", err)
 	}
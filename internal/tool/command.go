@@ -0,0 +1,278 @@
+package tool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/theantichris/ghost/v3/internal/llm"
+)
+
+const commandWaitDelay = time.Second // Time to wait for output after a command is killed.
+
+var (
+	ErrCommandParse  = errors.New("failed to parse command")
+	ErrCommandDenied = errors.New("command denied")
+	ErrCommandStart  = errors.New("failed to start command")
+)
+
+// commandEnv is the environment passed to commands, other variables are
+// scrubbed so secrets like API keys don't leak.
+var commandEnv = []string{"PATH", "HOME", "USER", "LOGNAME", "SHELL", "LANG", "LC_ALL", "LC_CTYPE", "TERM", "TMPDIR", "TZ"}
+
+// RunCommand runs commands for the LLM.
+// Commands matching Deny are refused, commands matching Allow run without
+// approval, and other commands need approval.
+type RunCommand struct {
+	Allow     []string      // Command prefixes that run without approval, like "go test".
+	Deny      []string      // Command prefixes that never run, like "rm".
+	Dir       string        // Working directory, the current directory when empty.
+	Timeout   time.Duration // Time a command can run before it's killed, 0 for none.
+	MaxOutput int           // Max bytes of output kept, 0 for no limit.
+	Env       []string      // Environment variables passed to commands on top of commandEnv.
+}
+
+type commandArgs struct {
+	Command string `json:"command"`
+}
+
+// Definition returns the tool schema.
+func (command RunCommand) Definition() llm.Tool {
+	return llm.Tool{
+		Type: "function",
+		Function: llm.ToolFunction{
+			Name:        "run_command",
+			Description: "Run a command and return its combined stdout and stderr with its exit status. The command isn't run by a shell, so pipes, redirects, and globs aren't supported.",
+			Parameters: llm.ToolParameters{
+				Type:     "object",
+				Required: []string{"command"},
+				Properties: map[string]llm.ToolProperty{
+					"command": {Type: "string", Description: "The command and its arguments, like go test ./..."},
+				},
+			},
+		},
+	}
+}
+
+// CallPolicy denies commands matching Deny, allows commands matching Allow,
+// and asks before running any other command.
+func (command RunCommand) CallPolicy(args json.RawMessage) Policy {
+	var runArgs commandArgs
+	if err := json.Unmarshal(args, &runArgs); err != nil {
+		return PolicyAsk
+	}
+
+	argv, err := splitCommand(runArgs.Command)
+	if err != nil || len(argv) == 0 {
+		return PolicyAsk
+	}
+
+	switch {
+	case matchesCommand(command.Deny, argv, true):
+		return PolicyDeny
+
+	case matchesCommand(command.Allow, argv, false):
+		return PolicyAllow
+
+	default:
+		return PolicyAsk
+	}
+}
+
+// Execute runs the command and returns its output.
+// A command that fails or times out still returns its output, with its exit
+// status noted.
+// Returns ErrCommandDenied if the command matches Deny.
+func (command RunCommand) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var runArgs commandArgs
+	if err := json.Unmarshal(args, &runArgs); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrParseArgs, err)
+	}
+
+	argv, err := splitCommand(runArgs.Command)
+	if err != nil {
+		return "", err
+	}
+
+	if len(argv) == 0 {
+		return "", fmt.Errorf("%w: command is empty", ErrCommandParse)
+	}
+
+	if matchesCommand(command.Deny, argv, true) {
+		return "", fmt.Errorf("%w: %s", ErrCommandDenied, runArgs.Command)
+	}
+
+	if command.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, command.Timeout)
+		defer cancel()
+	}
+
+	output := &cappedBuffer{max: command.MaxOutput}
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Dir = command.Dir
+	cmd.Env = command.environ()
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cmd.WaitDelay = commandWaitDelay
+
+	err = cmd.Run()
+
+	var status string
+
+	var exitErr *exec.ExitError
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		status = fmt.Sprintf("[timed out after %s]", command.Timeout)
+
+	case errors.As(err, &exitErr):
+		status = fmt.Sprintf("[exit status %d]", exitErr.ExitCode())
+
+	case err != nil:
+		return "", fmt.Errorf("%w: %w", ErrCommandStart, err)
+
+	default:
+		status = "[exit status 0]"
+	}
+
+	return output.String() + status, nil
+}
+
+// environ returns the scrubbed environment for commands.
+func (command RunCommand) environ() []string {
+	var env []string
+
+	for _, name := range slices.Concat(commandEnv, command.Env) {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+
+	return env
+}
+
+// matchesCommand returns true if argv starts with one of the command prefixes.
+// With byBase the program is compared by its base name so /bin/rm matches rm,
+// otherwise it has to be written as in the prefix so ./ls doesn't match ls.
+func matchesCommand(prefixes []string, argv []string, byBase bool) bool {
+	program := argv
+	if byBase {
+		program = append([]string{filepath.Base(argv[0])}, argv[1:]...)
+	}
+
+	for _, prefix := range prefixes {
+		words, err := splitCommand(prefix)
+		if err != nil || len(words) == 0 || len(words) > len(program) {
+			continue
+		}
+
+		if slices.Equal(words, program[:len(words)]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitCommand splits command into words on whitespace, honoring single
+// quotes, double quotes, and backslash escapes.
+// Returns ErrCommandParse if a quote isn't closed.
+func splitCommand(command string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+
+	inWord := false
+	var quote rune
+	escaped := false
+
+	for _, char := range command {
+		switch {
+		case escaped:
+			word.WriteRune(char)
+			escaped = false
+
+		case char == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			} else {
+				word.WriteRune(char)
+			}
+
+		case char == '\'' || char == '"':
+			quote = char
+			inWord = true
+
+		case char == ' ' || char == '\t' || char == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+
+		default:
+			word.WriteRune(char)
+			inWord = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("%w: unterminated quote or escape in %q", ErrCommandParse, command)
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
+
+// cappedBuffer keeps the first max bytes written to it and counts the rest.
+type cappedBuffer struct {
+	max     int
+	buffer  bytes.Buffer
+	dropped int
+}
+
+// Write keeps what fits and reports the whole write as written so the
+// command isn't interrupted.
+func (capped *cappedBuffer) Write(p []byte) (int, error) {
+	if capped.max <= 0 {
+		return capped.buffer.Write(p)
+	}
+
+	room := max(capped.max-capped.buffer.Len(), 0)
+	keep := min(room, len(p))
+
+	capped.buffer.Write(p[:keep])
+	capped.dropped += len(p) - keep
+
+	return len(p), nil
+}
+
+// String returns the output kept with a note of how much was dropped.
+func (capped *cappedBuffer) String() string {
+	output := capped.buffer.String()
+	if output != "" && !strings.HasSuffix(output, "\n") {
+		output += "\n"
+	}
+
+	if capped.dropped > 0 {
+		output += fmt.Sprintf("[%d bytes of output dropped]\n", capped.dropped)
+	}
+
+	return output
+}
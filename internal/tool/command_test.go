@@ -0,0 +1,211 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    []string
+		wantErr bool
+	}{
+		{name: "splits on whitespace", command: "go  test\t./...", want: []string{"go", "test", "./..."}},
+		{name: "keeps quoted spaces", command: `git commit -m "jack in"`, want: []string{"git", "commit", "-m", "jack in"}},
+		{name: "keeps single quoted text literally", command: `echo 'a\b'`, want: []string{"echo", `a\b`}},
+		{name: "unescapes backslashes", command: `echo a\ b`, want: []string{"echo", "a b"}},
+		{name: "keeps empty quoted word", command: `echo ""`, want: []string{"echo", ""}},
+		{name: "returns error for open quote", command: `echo "jack`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitCommand(tt.command)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrCommandParse) {
+					t.Errorf("splitCommand() err = %v, want %v", err, ErrCommandParse)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("splitCommand() err = %v, want nil", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("splitCommand() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRunCommand_CallPolicy(t *testing.T) {
+	command := RunCommand{Allow: []string{"go test", "git status"}, Deny: []string{"rm", "git push"}}
+
+	tests := []struct {
+		name    string
+		command string
+		want    Policy
+	}{
+		{name: "allows allowlisted command", command: "go test ./...", want: PolicyAllow},
+		{name: "asks for command only sharing a prefix", command: "go testify", want: PolicyAsk},
+		{name: "asks for allowlisted program at another path", command: "/tmp/x/go test ./...", want: PolicyAsk},
+		{name: "asks for allowlisted program in the working directory", command: "./go test", want: PolicyAsk},
+		{name: "asks for unlisted command", command: "go build", want: PolicyAsk},
+		{name: "denies denylisted command", command: "rm -rf /", want: PolicyDeny},
+		{name: "denies denylisted command by path", command: "/bin/rm -rf /", want: PolicyDeny},
+		{name: "denies denylisted subcommand", command: "git push --force", want: PolicyDeny},
+		{name: "asks for unparsable command", command: `go test "`, want: PolicyAsk},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, _ := json.Marshal(commandArgs{Command: tt.command})
+
+			if got := command.CallPolicy(args); got != tt.want {
+				t.Errorf("CallPolicy(%q) = %q, want %q", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistry_CallPolicy(t *testing.T) {
+	registry := Registry{Tools: map[string]Tool{}, Policies: map[string]Policy{}}
+	registry.Register(RunCommand{Allow: []string{"ls"}, Deny: []string{"rm"}})
+
+	tests := []struct {
+		name    string
+		policy  Policy
+		command string
+		want    Policy
+	}{
+		{name: "uses call policy by default", command: "ls", want: PolicyAllow},
+		{name: "asks for allowed program at another path", command: "/tmp/x/ls", want: PolicyAsk},
+		{name: "asks when tool policy asks", policy: PolicyAsk, command: "ls", want: PolicyAsk},
+		{name: "denies when tool policy denies", policy: PolicyDeny, command: "ls", want: PolicyDeny},
+		{name: "denies when call policy denies", policy: PolicyAllow, command: "rm x", want: PolicyDeny},
+		{name: "asks when call policy asks", policy: PolicyAllow, command: "cat x", want: PolicyAsk},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delete(registry.Policies, "run_command")
+			if tt.policy != "" {
+				registry.Policies["run_command"] = tt.policy
+			}
+
+			args, _ := json.Marshal(commandArgs{Command: tt.command})
+
+			if got := registry.CallPolicy("run_command", args); got != tt.want {
+				t.Errorf("CallPolicy() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunCommand_Execute(t *testing.T) {
+	t.Setenv("GHOST_TEST_SECRET", "tvly-secret")
+	t.Setenv("GHOST_TEST_VISIBLE", "visible")
+
+	tests := []struct {
+		name     string
+		command  RunCommand
+		args     string
+		want     string
+		unwanted string
+		err      error
+	}{
+		{
+			name: "returns combined output and exit status",
+			args: `{"command":"sh -c 'echo out; echo err >&2'"}`,
+			want: "out\nerr\n[exit status 0]",
+		},
+		{
+			name: "returns output of failing command",
+			args: `{"command":"sh -c 'echo broken; exit 3'"}`,
+			want: "broken\n[exit status 3]",
+		},
+		{
+			name:    "runs in working directory",
+			command: RunCommand{Dir: "/"},
+			args:    `{"command":"pwd"}`,
+			want:    "/\n[exit status 0]",
+		},
+		{
+			name:     "scrubs environment",
+			command:  RunCommand{Env: []string{"GHOST_TEST_VISIBLE"}},
+			args:     `{"command":"env"}`,
+			want:     "GHOST_TEST_VISIBLE=visible",
+			unwanted: "tvly-secret",
+		},
+		{
+			name:    "caps output",
+			command: RunCommand{MaxOutput: 4},
+			args:    `{"command":"echo jacked-in"}`,
+			want:    "jack\n[6 bytes of output dropped]\n[exit status 0]",
+		},
+		{
+			name:    "kills command after timeout",
+			command: RunCommand{Timeout: 100 * time.Millisecond},
+			args:    `{"command":"sh -c 'echo started; exec sleep 5'"}`,
+			want:    "started\n[timed out after 100ms]",
+		},
+		{
+			name:    "returns error for denied command",
+			command: RunCommand{Deny: []string{"sh"}},
+			args:    `{"command":"sh -c 'echo no'"}`,
+			err:     ErrCommandDenied,
+		},
+		{
+			name: "returns error for missing program",
+			args: `{"command":"ghost-no-such-program"}`,
+			err:  ErrCommandStart,
+		},
+		{
+			name: "returns error for empty command",
+			args: `{"command":"  "}`,
+			err:  ErrCommandParse,
+		},
+		{
+			name: "returns error for bad arguments",
+			args: `{"command":`,
+			err:  ErrParseArgs,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.command.Execute(context.Background(), json.RawMessage(tt.args))
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Execute() err = %v, want %v", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Execute() err = %v, want nil", err)
+			}
+
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("Execute() = %q, want it to contain %q", got, tt.want)
+			}
+
+			if tt.unwanted != "" && strings.Contains(got, tt.unwanted) {
+				t.Errorf("Execute() = %q, want it to leave out %q", got, tt.unwanted)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package tool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/theantichris/ghost/v3/internal/llm"
+)
+
+const (
+	externalInputStdin = "stdin" // Arguments are written to stdin as JSON.
+	externalInputEnv   = "env"   // Arguments are set as environment variables.
+
+	maxExternalOutput = 1 << 20 // Bytes of stdout kept from an external tool.
+	maxExternalStderr = 4096    // Bytes of stderr kept for error results.
+)
+
+var (
+	ErrExternalConfig = errors.New("invalid external tool config")
+	ErrExternalFailed = errors.New("external tool failed")
+)
+
+// toolName matches the names tools can be called by.
+var toolName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ExternalToolConfig declares a tool that runs a command.
+type ExternalToolConfig struct {
+	Name        string        // Name the model calls the tool by.
+	Description string        // What the tool does, for the model.
+	Parameters  string        // JSON Schema of the arguments, an object with any properties when empty.
+	Command     string        // Command run for each call.
+	Args        []string      // Arguments passed to Command.
+	Input       string        // How arguments are passed: stdin (default) or env.
+	Dir         string        // Working directory, the current directory when empty.
+	Timeout     time.Duration // Time a call can run before it's killed, 0 for none.
+}
+
+// ExternalTool runs a command for each call. The arguments are passed as JSON
+// on stdin, or as GHOST_ARG_<NAME> environment variables, and the command's
+// stdout is the result.
+type ExternalTool struct {
+	config     ExternalToolConfig
+	definition llm.Tool
+	logger     *log.Logger
+}
+
+// NewExternalTool creates an ExternalTool from config.
+// Returns ErrExternalConfig if the name, command, input, or parameters are
+// invalid.
+func NewExternalTool(config ExternalToolConfig, logger *log.Logger) (ExternalTool, error) {
+	if !toolName.MatchString(config.Name) {
+		return ExternalTool{}, fmt.Errorf("%w: name %q must be letters, digits, _ and -", ErrExternalConfig, config.Name)
+	}
+
+	if config.Command == "" {
+		return ExternalTool{}, fmt.Errorf("%w: %s: command is required", ErrExternalConfig, config.Name)
+	}
+
+	config.Input = strings.ToLower(config.Input)
+	if config.Input == "" {
+		config.Input = externalInputStdin
+	}
+
+	if config.Input != externalInputStdin && config.Input != externalInputEnv {
+		return ExternalTool{}, fmt.Errorf("%w: %s: input must be stdin or env", ErrExternalConfig, config.Name)
+	}
+
+	parameters := llm.ToolParameters{Type: "object", Properties: map[string]llm.ToolProperty{}}
+	if strings.TrimSpace(config.Parameters) != "" {
+		if !json.Valid([]byte(config.Parameters)) {
+			return ExternalTool{}, fmt.Errorf("%w: %s: parameters must be a JSON Schema", ErrExternalConfig, config.Name)
+		}
+
+		parameters = toolParameters(json.RawMessage(config.Parameters))
+	}
+
+	return ExternalTool{
+		config: config,
+		definition: llm.Tool{
+			Type: "function",
+			Function: llm.ToolFunction{
+				Name:        config.Name,
+				Description: config.Description,
+				Parameters:  parameters,
+			},
+		},
+		logger: logger,
+	}, nil
+}
+
+// Definition returns the tool schema from the config.
+func (tool ExternalTool) Definition() llm.Tool {
+	return tool.definition
+}
+
+// Execute runs the command with args and returns its stdout.
+// Returns ErrExternalFailed with the end of stderr if the command exits with
+// an error or runs past its timeout.
+func (tool ExternalTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	if len(bytes.TrimSpace(args)) == 0 {
+		args = json.RawMessage("{}")
+	}
+
+	if tool.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, tool.config.Timeout)
+		defer cancel()
+	}
+
+	stdout := &cappedBuffer{max: maxExternalOutput}
+	stderr := &cappedBuffer{max: maxExternalStderr}
+
+	cmd := exec.CommandContext(ctx, tool.config.Command, tool.config.Args...)
+	cmd.Dir = tool.config.Dir
+	cmd.Env = append(os.Environ(), "GHOST_TOOL="+tool.config.Name)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = commandWaitDelay
+
+	if tool.config.Input == externalInputEnv {
+		env, err := argsEnv(args)
+		if err != nil {
+			return "", err
+		}
+
+		cmd.Env = append(cmd.Env, env...)
+	} else {
+		cmd.Stdin = bytes.NewReader(args)
+	}
+
+	err := cmd.Run()
+
+	if stderr.buffer.Len() > 0 {
+		tool.logger.Debug("external tool stderr", "name", tool.config.Name, "stderr", strings.TrimSpace(stderr.buffer.String()))
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("%w: %s timed out after %s", ErrExternalFailed, tool.config.Name, tool.config.Timeout)
+	}
+
+	if err != nil {
+		message := strings.TrimSpace(stderr.buffer.String())
+		if message == "" {
+			return "", fmt.Errorf("%w: %s: %w", ErrExternalFailed, tool.config.Name, err)
+		}
+
+		return "", fmt.Errorf("%w: %s: %w: %s", ErrExternalFailed, tool.config.Name, err, message)
+	}
+
+	return stdout.String(), nil
+}
+
+// argsEnv returns the top level arguments as GHOST_ARG_<NAME> variables with
+// the name upper cased. Strings are passed as is and other values as JSON.
+// All the arguments are also passed as JSON in GHOST_ARGS.
+func argsEnv(args json.RawMessage) ([]string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(args, &fields); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrParseArgs, err)
+	}
+
+	env := []string{"GHOST_ARGS=" + string(args)}
+
+	for name, value := range fields {
+		var text string
+		if err := json.Unmarshal(value, &text); err != nil {
+			text = string(value)
+		}
+
+		name = strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+		env = append(env, "GHOST_ARG_"+name+"="+text)
+	}
+
+	return env, nil
+}
@@ -0,0 +1,144 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/google/go-cmp/cmp"
+	"github.com/theantichris/ghost/v3/internal/llm"
+)
+
+func TestNewExternalTool(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  ExternalToolConfig
+		want    llm.ToolParameters
+		wantErr bool
+	}{
+		{
+			name:   "uses object with any properties without parameters",
+			config: ExternalToolConfig{Name: "uptime", Command: "uptime"},
+			want:   llm.ToolParameters{Type: "object", Properties: map[string]llm.ToolProperty{}},
+		},
+		{
+			name:   "parses parameters",
+			config: ExternalToolConfig{Name: "jira_issue", Command: "jira", Parameters: `{"type":"object","required":["key"],"properties":{"key":{"type":"string","pattern":"^[A-Z]+-[0-9]+$"}}}`},
+			want: llm.ToolParameters{
+				Type:       "object",
+				Required:   []string{"key"},
+				Properties: map[string]llm.ToolProperty{"key": {Type: "string", Pattern: "^[A-Z]+-[0-9]+$"}},
+			},
+		},
+		{name: "returns error for invalid name", config: ExternalToolConfig{Name: "jira issue", Command: "jira"}, wantErr: true},
+		{name: "returns error without command", config: ExternalToolConfig{Name: "jira"}, wantErr: true},
+		{name: "returns error for unknown input", config: ExternalToolConfig{Name: "jira", Command: "jira", Input: "args"}, wantErr: true},
+		{name: "returns error for invalid parameters", config: ExternalToolConfig{Name: "jira", Command: "jira", Parameters: `{"type":`}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewExternalTool(tt.config, log.New(io.Discard))
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrExternalConfig) {
+					t.Errorf("NewExternalTool() err = %v, want %v", err, ErrExternalConfig)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("NewExternalTool() err = %v, want nil", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got.Definition().Function.Parameters); diff != "" {
+				t.Errorf("NewExternalTool() parameters mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExternalTool_Execute(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   ExternalToolConfig
+		args     string
+		want     string
+		wantText string
+		err      error
+	}{
+		{
+			name:   "passes arguments on stdin",
+			config: ExternalToolConfig{Command: "cat"},
+			args:   `{"key":"NC-77"}`,
+			want:   "{\"key\":\"NC-77\"}\n",
+		},
+		{
+			name:   "passes empty object without arguments",
+			config: ExternalToolConfig{Command: "cat"},
+			want:   "{}\n",
+		},
+		{
+			name:   "passes arguments as environment variables",
+			config: ExternalToolConfig{Command: "sh", Args: []string{"-c", `echo "$GHOST_TOOL $GHOST_ARG_ISSUE_KEY $GHOST_ARG_LIMIT $GHOST_ARGS"`}, Input: "env"},
+			args:   `{"issue-key":"NC-77","limit":3}`,
+			want:   "lookup NC-77 3 {\"issue-key\":\"NC-77\",\"limit\":3}\n",
+		},
+		{
+			name:     "returns error with stderr for failed command",
+			config:   ExternalToolConfig{Command: "sh", Args: []string{"-c", "echo partial; echo ticket not found >&2; exit 2"}},
+			err:      ErrExternalFailed,
+			wantText: "exit status 2: ticket not found",
+		},
+		{
+			name:     "returns error after timeout",
+			config:   ExternalToolConfig{Command: "sleep", Args: []string{"5"}, Timeout: 50 * time.Millisecond},
+			err:      ErrExternalFailed,
+			wantText: "timed out after 50ms",
+		},
+		{
+			name:   "returns error for missing command",
+			config: ExternalToolConfig{Command: "ghost-no-such-program"},
+			err:    ErrExternalFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Name = "lookup"
+
+			externalTool, err := NewExternalTool(tt.config, log.New(io.Discard))
+			if err != nil {
+				t.Fatalf("NewExternalTool() err = %v, want nil", err)
+			}
+
+			got, err := externalTool.Execute(context.Background(), json.RawMessage(tt.args))
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("Execute() err = %v, want %v", err, tt.err)
+				}
+
+				if !strings.Contains(err.Error(), tt.wantText) {
+					t.Errorf("Execute() err = %q, want it to contain %q", err, tt.wantText)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Execute() err = %v, want nil", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Execute() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/carlmjohnson/requests"
+	"github.com/theantichris/ghost/v3/internal/llm"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	defaultFetchMaxSize   = 5 << 20          // Bytes of a page read.
+	defaultFetchTimeout   = 20 * time.Second // Time to fetch a page.
+	defaultFetchMaxLength = 12000            // Bytes of page text returned.
+)
+
+var (
+	ErrFetchURL         = errors.New("invalid URL: only http and https URLs can be fetched")
+	ErrFetchFailed      = errors.New("failed to fetch page")
+	ErrFetchContentType = errors.New("unsupported content type")
+	ErrPrivateAddress   = errors.New("private network address blocked")
+)
+
+// sharedAddressSpace is the carrier-grade NAT range, which netip doesn't
+// count as private.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// Fetch holds the page fetch configuration.
+type Fetch struct {
+	MaxSize      int64         // Max bytes of a page read, the rest is dropped.
+	Timeout      time.Duration // Time to fetch a page, 0 for none.
+	MaxLength    int           // Max bytes of page text returned, 0 for no limit.
+	AllowPrivate bool          // Allows loopback, private, and link local addresses.
+}
+
+// NewFetch creates and returns a new fetch config with the default limits.
+func NewFetch(allowPrivate bool) Fetch {
+	return Fetch{
+		MaxSize:      defaultFetchMaxSize,
+		Timeout:      defaultFetchTimeout,
+		MaxLength:    defaultFetchMaxLength,
+		AllowPrivate: allowPrivate,
+	}
+}
+
+// Definition returns the tool schema.
+func (fetch Fetch) Definition() llm.Tool {
+	return llm.Tool{
+		Type: "function",
+		Function: llm.ToolFunction{
+			Name:        "fetch_url",
+			Description: "fetch a web page and return its text as markdown, use it to read the pages search results point to",
+			Parameters: llm.ToolParameters{
+				Type:     "object",
+				Required: []string{"url"},
+				Properties: map[string]llm.ToolProperty{
+					"url": {Type: "string", Description: "the http or https URL of the page", Pattern: "^https?://"},
+				},
+			},
+		},
+	}
+}
+
+// Execute fetches the page and returns its final URL after redirects and its
+// text. HTML is converted to markdown.
+// Returns ErrFetchURL for URLs that aren't http or https, ErrPrivateAddress if
+// the page is on a private network, and ErrFetchContentType if the page isn't
+// text.
+func (fetch Fetch) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var fetchArgs struct {
+		URL string `json:"url"`
+	}
+
+	if err := json.Unmarshal(args, &fetchArgs); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrParseArgs, err)
+	}
+
+	pageURL, err := url.Parse(fetchArgs.URL)
+	if err != nil || (pageURL.Scheme != "http" && pageURL.Scheme != "https") || pageURL.Host == "" {
+		return "", fmt.Errorf("%w: %s", ErrFetchURL, fetchArgs.URL)
+	}
+
+	if fetch.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, fetch.Timeout)
+		defer cancel()
+	}
+
+	var page string
+
+	err = requests.URL(pageURL.String()).
+		Client(fetch.client()).
+		Accept("text/html, application/xhtml+xml, text/plain;q=0.9, */*;q=0.5").
+		UserAgent("ghost").
+		Handle(func(res *http.Response) error {
+			var readErr error
+			page, readErr = fetch.read(res)
+
+			return readErr
+		}).
+		Fetch(ctx)
+	if err != nil {
+		if errors.Is(err, ErrPrivateAddress) || errors.Is(err, ErrFetchContentType) {
+			return "", err
+		}
+
+		return "", fmt.Errorf("%w: %w", ErrFetchFailed, err)
+	}
+
+	return truncate(page, fetch.MaxLength), nil
+}
+
+// read returns the page in res as text, after a URL line with its final URL
+// and a Title line for HTML pages that have one.
+func (fetch Fetch) read(res *http.Response) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/html"
+	}
+
+	isHTML := mediaType == "text/html" || mediaType == "application/xhtml+xml"
+	if !isHTML && !strings.HasPrefix(mediaType, "text/") && mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") && !strings.HasSuffix(mediaType, "xml") {
+		return "", fmt.Errorf("%w: %s", ErrFetchContentType, mediaType)
+	}
+
+	var body io.Reader = res.Body
+	if fetch.MaxSize > 0 {
+		body = io.LimitReader(body, fetch.MaxSize)
+	}
+
+	body, err = charset.NewReader(body, res.Header.Get("Content-Type"))
+	if err != nil {
+		return "", err
+	}
+
+	var page strings.Builder
+	fmt.Fprintf(&page, "URL: %s\n", res.Request.URL)
+
+	if !isHTML {
+		content, err := io.ReadAll(body)
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(&page, "\n%s", content)
+
+		return page.String(), nil
+	}
+
+	title, markdown, err := htmlToMarkdown(body, res.Request.URL)
+	if err != nil {
+		return "", err
+	}
+
+	if title != "" {
+		fmt.Fprintf(&page, "Title: %s\n", title)
+	}
+
+	fmt.Fprintf(&page, "\n%s", markdown)
+
+	return page.String(), nil
+}
+
+// client returns an HTTP client that refuses to connect to private network
+// addresses unless they're allowed. Addresses are checked after they're
+// resolved so redirects and DNS can't reach them either.
+func (fetch Fetch) client() *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+
+	if !fetch.AllowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, address)
+			}
+
+			if isPrivateAddress(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, addrPort.Addr())
+			}
+
+			return nil
+		}
+	}
+
+	// No proxy, a proxy would connect on our behalf and skip the check.
+	return &http.Client{Transport: &http.Transport{DialContext: dialer.DialContext, ForceAttemptHTTP2: true}}
+}
+
+// isPrivateAddress returns true if addr isn't on the public internet.
+func isPrivateAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() || sharedAddressSpace.Contains(addr)
+}
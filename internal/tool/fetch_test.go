@@ -0,0 +1,281 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPage = `<!DOCTYPE html>
+<html>
+<head><title>Night City Net</title><style>body { color: red; }</style></head>
+<body>
+<nav><a href="/home">Home</a> <a href="/about">About</a></nav>
+<main>
+<h1>Netrunning 101</h1>
+<p>Jack in with a <strong>deck</strong> and <a href="/guides/ice">avoid the ICE</a>.</p>
+<script>alert("tracking")</script>
+<ul><li>Breach</li><li>Upload</li></ul>
+<pre>ghost run
+  --quiet</pre>
+</main>
+<footer>Copyright Arasaka</footer>
+</body>
+</html>`
+
+func newTestFetchServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(testPage))
+	})
+
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/page", http.StatusFound)
+	})
+
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("plain intel"))
+	})
+
+	mux.HandleFunc("/image", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("\x89PNG"))
+	})
+
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(strings.Repeat("a", 1000)))
+	})
+
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestFetchExecute(t *testing.T) {
+	server := newTestFetchServer(t)
+
+	tests := []struct {
+		name     string
+		fetch    Fetch
+		path     string
+		url      string
+		want     []string
+		unwanted []string
+		err      error
+	}{
+		{
+			name:     "converts HTML to markdown",
+			fetch:    NewFetch(true),
+			path:     "/page",
+			want:     []string{"URL: " + server.URL + "/page", "Title: Night City Net", "# Netrunning 101", "Jack in with a **deck** and [avoid the ICE](" + server.URL + "/guides/ice).", "- Breach\n- Upload", "```\nghost run\n  --quiet\n```"},
+			unwanted: []string{"Home", "tracking", "color: red", "Arasaka"},
+		},
+		{
+			name:  "returns final URL after redirects",
+			fetch: NewFetch(true),
+			path:  "/redirect",
+			want:  []string{"URL: " + server.URL + "/page\n"},
+		},
+		{
+			name:  "returns plain text as is",
+			fetch: NewFetch(true),
+			path:  "/plain",
+			want:  []string{"plain intel"},
+		},
+		{
+			name:  "reads up to max size",
+			fetch: Fetch{MaxSize: 10, AllowPrivate: true},
+			path:  "/large",
+			want:  []string{"\n" + strings.Repeat("a", 10)},
+		},
+		{
+			name:  "truncates to max length",
+			fetch: Fetch{MaxLength: 50, AllowPrivate: true},
+			path:  "/large",
+			want:  []string{"[truncated"},
+		},
+		{
+			name:  "returns error for unsupported content type",
+			fetch: NewFetch(true),
+			path:  "/image",
+			err:   ErrFetchContentType,
+		},
+		{
+			name:  "returns error for error status",
+			fetch: NewFetch(true),
+			path:  "/missing",
+			err:   ErrFetchFailed,
+		},
+		{
+			name:  "returns error after timeout",
+			fetch: Fetch{Timeout: 50 * time.Millisecond, AllowPrivate: true},
+			path:  "/slow",
+			err:   ErrFetchFailed,
+		},
+		{
+			name:  "blocks private network address",
+			fetch: NewFetch(false),
+			path:  "/page",
+			err:   ErrPrivateAddress,
+		},
+		{
+			name:  "returns error for unsupported scheme",
+			fetch: NewFetch(true),
+			url:   "file:///etc/passwd",
+			err:   ErrFetchURL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pageURL := tt.url
+			if pageURL == "" {
+				pageURL = server.URL + tt.path
+			}
+
+			args, _ := json.Marshal(map[string]string{"url": pageURL})
+
+			got, err := tt.fetch.Execute(context.Background(), args)
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Execute() err = %v, want %v", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Execute() err = %v, want nil", err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Execute() = %q, want it to contain %q", got, want)
+				}
+			}
+
+			for _, unwanted := range tt.unwanted {
+				if strings.Contains(got, unwanted) {
+					t.Errorf("Execute() = %q, want it to leave out %q", got, unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestIsPrivateAddress(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "127.0.0.1", want: true},
+		{addr: "10.1.2.3", want: true},
+		{addr: "192.168.0.1", want: true},
+		{addr: "169.254.169.254", want: true},
+		{addr: "100.64.0.1", want: true},
+		{addr: "0.0.0.0", want: true},
+		{addr: "::1", want: true},
+		{addr: "fd00::1", want: true},
+		{addr: "::ffff:127.0.0.1", want: true},
+		{addr: "93.184.216.34", want: false},
+		{addr: "2606:4700::1111", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := isPrivateAddress(netip.MustParseAddr(tt.addr)); got != tt.want {
+				t.Errorf("isPrivateAddress(%s) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLToMarkdown(t *testing.T) {
+	base, _ := url.Parse("https://night.city/docs/")
+
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "converts headings and paragraphs",
+			html: "<h2>Gear</h2><p>Cyberdeck\n   and   chrome</p>",
+			want: "## Gear\n\nCyberdeck and chrome\n",
+		},
+		{
+			name: "resolves relative links",
+			html: `<p>Read <a href="ice.html">the guide</a> first</p>`,
+			want: "Read [the guide](https://night.city/docs/ice.html) first\n",
+		},
+		{
+			name: "writes in page and script links as text",
+			html: `<p><a href="#top">Top</a> <a href="javascript:void(0)">Menu</a></p>`,
+			want: "Top Menu\n",
+		},
+		{
+			name: "numbers ordered lists",
+			html: "<ol><li>Breach</li><li>Upload</li></ol>",
+			want: "1. Breach\n2. Upload\n",
+		},
+		{
+			name: "quotes blockquotes",
+			html: "<blockquote><p>Wake up</p><p>Samurai</p></blockquote>",
+			want: "> Wake up\n>\n> Samurai\n",
+		},
+		{
+			name: "writes tables as rows",
+			html: "<table><tr><th>Name</th><th>Role</th></tr><tr><td>V</td><td>Merc</td></tr></table>",
+			want: "| Name | Role |\n| --- | --- |\n| V | Merc |\n",
+		},
+		{
+			name: "prefers article content",
+			html: "<div>Sidebar</div><article><p>Story</p></article>",
+			want: "Story\n",
+		},
+		{
+			name: "writes image alt text",
+			html: `<p><img src="x.png" alt="skyline"></p>`,
+			want: "[image: skyline]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got, err := htmlToMarkdown(strings.NewReader(tt.html), base)
+			if err != nil {
+				t.Fatalf("htmlToMarkdown() err = %v, want nil", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("htmlToMarkdown() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,427 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/theantichris/ghost/v3/internal/file"
+	"github.com/theantichris/ghost/v3/internal/llm"
+)
+
+const (
+	maxListEntries = 500 // Max entries list_dir returns.
+	maxGrepMatches = 200 // Max matching lines grep_files returns.
+)
+
+var (
+	ErrPathDenied  = errors.New("access denied: path is outside the allowed roots")
+	ErrPathIgnored = errors.New("access denied: path is ignored by .gitignore")
+	ErrNotText     = errors.New("file is not text")
+)
+
+// Sandbox confines file access to root directories.
+// Paths are resolved through symlinks before they're checked so a link can't
+// escape the roots, and paths ignored by .gitignore are refused.
+type Sandbox struct {
+	roots   []string
+	mu      sync.Mutex              // Guards ignores.
+	ignores map[string][]ignoreRule // .gitignore rules by directory.
+}
+
+// NewSandbox creates a Sandbox confined to roots.
+// Returns an error if a root doesn't exist.
+func NewSandbox(roots []string) (*Sandbox, error) {
+	sandbox := &Sandbox{ignores: map[string][]ignoreRule{}}
+
+	for _, root := range roots {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", file.ErrAccess, err)
+		}
+
+		resolved, err := filepath.EvalSymlinks(abs)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", file.ErrAccess, err)
+		}
+
+		sandbox.roots = append(sandbox.roots, resolved)
+	}
+
+	return sandbox, nil
+}
+
+// Resolve returns the real path of path and the root it's in.
+// Relative paths are resolved against each root in turn.
+// Returns ErrPathDenied if the path is outside the roots and ErrPathIgnored if
+// it's ignored.
+func (sandbox *Sandbox) Resolve(path string) (string, string, error) {
+	candidates := []string{path}
+
+	if !filepath.IsAbs(path) {
+		candidates = nil
+		for _, root := range sandbox.roots {
+			candidates = append(candidates, filepath.Join(root, path))
+		}
+	}
+
+	var resolveErr error
+
+	for _, candidate := range candidates {
+		resolved, err := filepath.EvalSymlinks(candidate)
+		if err != nil {
+			resolveErr = fmt.Errorf("%w: %w", file.ErrAccess, err)
+
+			continue
+		}
+
+		root, ok := sandbox.root(resolved)
+		if !ok {
+			return "", "", fmt.Errorf("%w: %s", ErrPathDenied, path)
+		}
+
+		info, err := os.Stat(resolved)
+		if err != nil {
+			return "", "", fmt.Errorf("%w: %w", file.ErrAccess, err)
+		}
+
+		if sandbox.ignored(root, resolved, info.IsDir()) {
+			return "", "", fmt.Errorf("%w: %s", ErrPathIgnored, path)
+		}
+
+		return resolved, root, nil
+	}
+
+	return "", "", resolveErr
+}
+
+// root returns the root path is in.
+func (sandbox *Sandbox) root(path string) (string, bool) {
+	for _, root := range sandbox.roots {
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return root, true
+		}
+	}
+
+	return "", false
+}
+
+// ignored returns true if path, or a directory between root and path, is
+// ignored by a .gitignore file in root or below it. The .git directory is
+// always ignored.
+func (sandbox *Sandbox) ignored(root, path string, isDir bool) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return false
+	}
+
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+
+	for i := range parts {
+		if parts[i] == ".git" {
+			return true
+		}
+
+		partIsDir := isDir || i < len(parts)-1
+		ignored := false
+
+		// Rules in deeper directories override rules above them.
+		for j := 0; j <= i; j++ {
+			dir := filepath.Join(root, filepath.Join(parts[:j]...))
+			target := strings.Join(parts[j:i+1], "/")
+
+			for _, rule := range sandbox.rules(dir) {
+				if rule.matches(target, partIsDir) {
+					ignored = !rule.negate
+				}
+			}
+		}
+
+		if ignored {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (sandbox *Sandbox) rules(dir string) []ignoreRule {
+	sandbox.mu.Lock()
+	defer sandbox.mu.Unlock()
+
+	rules, ok := sandbox.ignores[dir]
+	if !ok {
+		rules = loadIgnoreRules(dir)
+		sandbox.ignores[dir] = rules
+	}
+
+	return rules
+}
+
+// displayPath returns path relative to root for results, or path if it's the
+// root.
+func displayPath(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return path
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+type pathArgs struct {
+	Path string `json:"path"`
+}
+
+// ListDir lists the entries of a directory in the sandbox.
+type ListDir struct {
+	Sandbox *Sandbox
+}
+
+// Definition returns the tool schema.
+func (tool ListDir) Definition() llm.Tool {
+	return llm.Tool{
+		Type: "function",
+		Function: llm.ToolFunction{
+			Name:        "list_dir",
+			Description: "List the files and directories in a directory. Directories end with /.",
+			Parameters: llm.ToolParameters{
+				Type: "object",
+				Properties: map[string]llm.ToolProperty{
+					"path": {Type: "string", Description: "Directory to list, relative to the working directory. Defaults to the working directory."},
+				},
+			},
+		},
+	}
+}
+
+// Execute lists the directory, leaving out ignored entries.
+func (tool ListDir) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var listArgs pathArgs
+	if err := json.Unmarshal(args, &listArgs); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrParseArgs, err)
+	}
+
+	if listArgs.Path == "" {
+		listArgs.Path = "."
+	}
+
+	dir, root, err := tool.Sandbox.Resolve(listArgs.Path)
+	if err != nil {
+		return "", err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", file.ErrAccess, err)
+	}
+
+	var listing strings.Builder
+	fmt.Fprintf(&listing, "[DIR: %s]\n", displayPath(root, dir))
+
+	count := 0
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if tool.Sandbox.ignored(root, path, entry.IsDir()) {
+			continue
+		}
+
+		if count == maxListEntries {
+			fmt.Fprintf(&listing, "[stopped after %d entries]\n", maxListEntries)
+
+			break
+		}
+
+		count++
+
+		if entry.IsDir() {
+			fmt.Fprintf(&listing, "%s/\n", entry.Name())
+
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		fmt.Fprintf(&listing, "%s (%d bytes)\n", entry.Name(), info.Size())
+	}
+
+	return listing.String(), nil
+}
+
+// ReadFile reads a text file in the sandbox.
+type ReadFile struct {
+	Sandbox *Sandbox
+}
+
+// Definition returns the tool schema.
+func (tool ReadFile) Definition() llm.Tool {
+	return llm.Tool{
+		Type: "function",
+		Function: llm.ToolFunction{
+			Name:        "read_file",
+			Description: "Read the contents of a text file.",
+			Parameters: llm.ToolParameters{
+				Type:     "object",
+				Required: []string{"path"},
+				Properties: map[string]llm.ToolProperty{
+					"path": {Type: "string", Description: "File to read, relative to the working directory."},
+				},
+			},
+		},
+	}
+}
+
+// Execute reads the file.
+// Returns ErrNotText if the file isn't text.
+func (tool ReadFile) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var readArgs pathArgs
+	if err := json.Unmarshal(args, &readArgs); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrParseArgs, err)
+	}
+
+	path, _, err := tool.Sandbox.Resolve(readArgs.Path)
+	if err != nil {
+		return "", err
+	}
+
+	fileType, err := file.DetectType(path)
+	if err != nil {
+		return "", err
+	}
+
+	switch fileType {
+	case file.TypeDir:
+		return "", file.ErrIsDir
+
+	case file.TypeText:
+		return file.ReadText(path)
+
+	default:
+		return "", fmt.Errorf("%w: %s is %s", ErrNotText, readArgs.Path, fileType)
+	}
+}
+
+type grepArgs struct {
+	Pattern string `json:"pattern"`
+	Path    string `json:"path"`
+}
+
+// GrepFiles searches the text files under a directory in the sandbox.
+type GrepFiles struct {
+	Sandbox *Sandbox
+}
+
+// Definition returns the tool schema.
+func (tool GrepFiles) Definition() llm.Tool {
+	return llm.Tool{
+		Type: "function",
+		Function: llm.ToolFunction{
+			Name:        "grep_files",
+			Description: "Search text files for lines matching a regular expression. Returns matches as path:line: text.",
+			Parameters: llm.ToolParameters{
+				Type:     "object",
+				Required: []string{"pattern"},
+				Properties: map[string]llm.ToolProperty{
+					"pattern": {Type: "string", Description: "Regular expression in Go RE2 syntax."},
+					"path":    {Type: "string", Description: "File or directory to search, relative to the working directory. Defaults to the working directory."},
+				},
+			},
+		},
+	}
+}
+
+// Execute walks the directory and returns the matching lines.
+// Ignored paths, symlinks, and files that aren't text are skipped.
+func (tool GrepFiles) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var searchArgs grepArgs
+	if err := json.Unmarshal(args, &searchArgs); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrParseArgs, err)
+	}
+
+	pattern, err := regexp.Compile(searchArgs.Pattern)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrParseArgs, err)
+	}
+
+	if searchArgs.Path == "" {
+		searchArgs.Path = "."
+	}
+
+	start, root, err := tool.Sandbox.Resolve(searchArgs.Path)
+	if err != nil {
+		return "", err
+	}
+
+	var matches strings.Builder
+
+	count := 0
+
+	err = filepath.WalkDir(start, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if path != start && tool.Sandbox.ignored(root, path, entry.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if entry.IsDir() || !entry.Type().IsRegular() {
+			return nil
+		}
+
+		if fileType, err := file.DetectType(path); err != nil || fileType != file.TypeText {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+
+		for number, line := range strings.Split(string(content), "\n") {
+			if !pattern.MatchString(line) {
+				continue
+			}
+
+			if count == maxGrepMatches {
+				fmt.Fprintf(&matches, "[stopped after %d matches]\n", maxGrepMatches)
+
+				return filepath.SkipAll
+			}
+
+			count++
+			fmt.Fprintf(&matches, "%s:%d: %s\n", displayPath(root, path), number+1, line)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if count == 0 {
+		return "no matches", nil
+	}
+
+	return matches.String(), nil
+}
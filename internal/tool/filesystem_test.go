@@ -0,0 +1,262 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/theantichris/ghost/v3/internal/file"
+)
+
+// newTestSandbox creates a root directory with files and a sandbox confined
+// to it. Returns the sandbox and the root.
+func newTestSandbox(t *testing.T) (*Sandbox, string) {
+	t.Helper()
+
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	files := map[string]string{
+		".gitignore":          "*.log\nbuild/\n!keep.log\n",
+		"main.go":             "package main\n\nfunc main() {\n\tjackIn()\n}\n",
+		"notes.md":            "jackIn the matrix\n",
+		"debug.log":           "jackIn debug\n",
+		"keep.log":            "jackIn kept\n",
+		"build/out.txt":       "jackIn built\n",
+		"src/.gitignore":      "generated.go\n",
+		"src/generated.go":    "jackIn generated\n",
+		"src/handler.go":      "package src\n",
+		".git/config":         "jackIn git\n",
+		"image.png":           "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR",
+		"nested/deep/file.md": "deep\n",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("jackIn secret\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if err := os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(root, "escape.txt")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	if err := os.Symlink(filepath.Join(root, "notes.md"), filepath.Join(root, "link.md")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	sandbox, err := NewSandbox([]string{root})
+	if err != nil {
+		t.Fatalf("NewSandbox() err = %v, want nil", err)
+	}
+
+	return sandbox, root
+}
+
+func TestNewSandbox(t *testing.T) {
+	_, err := NewSandbox([]string{filepath.Join(t.TempDir(), "missing")})
+	if !errors.Is(err, file.ErrAccess) {
+		t.Errorf("NewSandbox() err = %v, want %v", err, file.ErrAccess)
+	}
+}
+
+func TestSandbox_Resolve(t *testing.T) {
+	sandbox, root := newTestSandbox(t)
+
+	tests := []struct {
+		name string
+		path string
+		want string
+		err  error
+	}{
+		{name: "resolves relative path", path: "main.go", want: "main.go"},
+		{name: "resolves absolute path in root", path: filepath.Join(root, "notes.md"), want: "notes.md"},
+		{name: "resolves symlink within root", path: "link.md", want: "notes.md"},
+		{name: "allows negated ignore rule", path: "keep.log", want: "keep.log"},
+		{name: "denies parent path", path: "../secret.txt", err: file.ErrAccess},
+		{name: "denies absolute path outside root", path: "/etc/passwd", err: ErrPathDenied},
+		{name: "denies symlink escaping root", path: "escape.txt", err: ErrPathDenied},
+		{name: "denies directory symlink escaping root", path: "escape/secret.txt", err: ErrPathDenied},
+		{name: "denies ignored file", path: "debug.log", err: ErrPathIgnored},
+		{name: "denies file in ignored directory", path: "build/out.txt", err: ErrPathIgnored},
+		{name: "denies file ignored by nested gitignore", path: "src/generated.go", err: ErrPathIgnored},
+		{name: "denies git directory", path: ".git/config", err: ErrPathIgnored},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := sandbox.Resolve(tt.path)
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Resolve(%q) err = %v, want %v", tt.path, err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Resolve(%q) err = %v, want nil", tt.path, err)
+			}
+
+			if want := filepath.Join(sandbox.roots[0], tt.want); got != want {
+				t.Errorf("Resolve(%q) = %q, want %q", tt.path, got, want)
+			}
+		})
+	}
+}
+
+func TestReadFile_Execute(t *testing.T) {
+	sandbox, _ := newTestSandbox(t)
+	readFile := ReadFile{Sandbox: sandbox}
+
+	tests := []struct {
+		name string
+		args string
+		want string
+		err  error
+	}{
+		{name: "reads text file", args: `{"path":"notes.md"}`, want: "jackIn the matrix"},
+		{name: "returns error for directory", args: `{"path":"src"}`, err: file.ErrIsDir},
+		{name: "returns error for image", args: `{"path":"image.png"}`, err: ErrNotText},
+		{name: "returns error for escaping symlink", args: `{"path":"escape.txt"}`, err: ErrPathDenied},
+		{name: "returns error for ignored file", args: `{"path":"debug.log"}`, err: ErrPathIgnored},
+		{name: "returns error for bad arguments", args: `{"path":`, err: ErrParseArgs},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readFile.Execute(context.Background(), json.RawMessage(tt.args))
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Execute() err = %v, want %v", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Execute() err = %v, want nil", err)
+			}
+
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("Execute() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListDir_Execute(t *testing.T) {
+	sandbox, _ := newTestSandbox(t)
+
+	got, err := ListDir{Sandbox: sandbox}.Execute(context.Background(), json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("Execute() err = %v, want nil", err)
+	}
+
+	for _, want := range []string{"main.go (", "src/", "nested/", "keep.log ("} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Execute() = %q, want it to contain %q", got, want)
+		}
+	}
+
+	for _, unwanted := range []string{"debug.log", "build/", ".git/"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("Execute() = %q, want it to leave out %q", got, unwanted)
+		}
+	}
+}
+
+func TestGrepFiles_Execute(t *testing.T) {
+	sandbox, _ := newTestSandbox(t)
+	grepFiles := GrepFiles{Sandbox: sandbox}
+
+	tests := []struct {
+		name      string
+		args      string
+		want      []string
+		unwanted  []string
+		err       error
+		wantExact string
+	}{
+		{
+			name:     "returns matches outside ignored paths",
+			args:     `{"pattern":"jackIn"}`,
+			want:     []string{"main.go:4: \tjackIn()", "notes.md:1: jackIn the matrix", "keep.log:1: jackIn kept"},
+			unwanted: []string{"debug.log", "build/", "generated.go", ".git", "secret"},
+		},
+		{
+			name:     "searches within path",
+			args:     `{"pattern":"jackIn","path":"notes.md"}`,
+			want:     []string{"notes.md:1:"},
+			unwanted: []string{"main.go"},
+		},
+		{
+			name:      "returns no matches",
+			args:      `{"pattern":"^zzz$"}`,
+			wantExact: "no matches",
+		},
+		{
+			name: "returns error for invalid pattern",
+			args: `{"pattern":"("}`,
+			err:  ErrParseArgs,
+		},
+		{
+			name: "returns error for path outside root",
+			args: `{"pattern":"jackIn","path":"escape"}`,
+			err:  ErrPathDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := grepFiles.Execute(context.Background(), json.RawMessage(tt.args))
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Execute() err = %v, want %v", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Execute() err = %v, want nil", err)
+			}
+
+			if tt.wantExact != "" && got != tt.wantExact {
+				t.Errorf("Execute() = %q, want %q", got, tt.wantExact)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Execute() = %q, want it to contain %q", got, want)
+				}
+			}
+
+			for _, unwanted := range tt.unwanted {
+				if strings.Contains(got, unwanted) {
+					t.Errorf("Execute() = %q, want it to leave out %q", got, unwanted)
+				}
+			}
+		})
+	}
+}
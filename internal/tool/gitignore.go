@@ -0,0 +1,137 @@
+package tool
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ignoreRule is a pattern from a .gitignore file.
+type ignoreRule struct {
+	pattern  *regexp.Regexp
+	negate   bool // Re-includes paths an earlier rule ignored.
+	dirOnly  bool // Only matches directories.
+	anchored bool // Matches the path from the .gitignore directory, not just the name.
+}
+
+// loadIgnoreRules reads the rules of the .gitignore file in dir.
+// Returns no rules if there's no file.
+func loadIgnoreRules(dir string) []ignoreRule {
+	file, err := os.Open(dir + string(os.PathSeparator) + ".gitignore")
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = file.Close() }()
+
+	var rules []ignoreRule
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreRule(scanner.Text()); ok {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
+
+// parseIgnoreRule parses a line of a .gitignore file.
+// Returns false for blank lines and comments.
+func parseIgnoreRule(line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	var rule ignoreRule
+
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+
+	line = strings.TrimPrefix(line, `\`)
+
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+
+	if strings.Contains(line, "/") {
+		rule.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+
+	pattern, err := regexp.Compile("^" + globToRegexp(line) + "$")
+	if err != nil {
+		return ignoreRule{}, false
+	}
+
+	rule.pattern = pattern
+
+	return rule, true
+}
+
+// globToRegexp converts a .gitignore glob to a regular expression.
+func globToRegexp(glob string) string {
+	var expr strings.Builder
+
+	for i := 0; i < len(glob); i++ {
+		switch char := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			expr.WriteString("(.*/)?")
+			i += 2
+
+		case strings.HasPrefix(glob[i:], "/**"):
+			expr.WriteString("/.*")
+			i += 2
+
+		case strings.HasPrefix(glob[i:], "**"):
+			expr.WriteString(".*")
+			i++
+
+		case char == '*':
+			expr.WriteString("[^/]*")
+
+		case char == '?':
+			expr.WriteString("[^/]")
+
+		case char == '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				expr.WriteString(`\[`)
+
+				continue
+			}
+
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			expr.WriteString("[" + class + "]")
+			i += end
+
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+
+	return expr.String()
+}
+
+// matches returns true if the rule matches rel, the slash separated path
+// from the rule's .gitignore directory.
+func (rule ignoreRule) matches(rel string, isDir bool) bool {
+	if rule.dirOnly && !isDir {
+		return false
+	}
+
+	if rule.anchored {
+		return rule.pattern.MatchString(rel)
+	}
+
+	return rule.pattern.MatchString(path.Base(rel))
+}
@@ -0,0 +1,65 @@
+package tool
+
+import "testing"
+
+func TestIgnoreRule_Matches(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{name: "matches name in any directory", line: "*.log", rel: "logs/run.log", want: true},
+		{name: "skips other names", line: "*.log", rel: "run.txt", want: false},
+		{name: "matches directory only rule", line: "build/", rel: "build", isDir: true, want: true},
+		{name: "skips file for directory only rule", line: "build/", rel: "build", want: false},
+		{name: "matches anchored path", line: "/dist", rel: "dist", isDir: true, want: true},
+		{name: "skips nested path for anchored rule", line: "/dist", rel: "src/dist", isDir: true, want: false},
+		{name: "matches leading double star", line: "**/secrets.txt", rel: "a/b/secrets.txt", want: true},
+		{name: "matches trailing double star", line: "vendor/**", rel: "vendor/pkg/mod.go", want: true},
+		{name: "matches question mark", line: "file?.txt", rel: "file1.txt", want: true},
+		{name: "matches character class", line: "[ab].md", rel: "b.md", want: true},
+		{name: "skips negated character class", line: "[!ab].md", rel: "a.md", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, ok := parseIgnoreRule(tt.line)
+			if !ok {
+				t.Fatalf("parseIgnoreRule(%q) ok = false, want true", tt.line)
+			}
+
+			if got := rule.matches(tt.rel, tt.isDir); got != tt.want {
+				t.Errorf("matches(%q, %v) = %v, want %v", tt.rel, tt.isDir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIgnoreRule(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		wantOK     bool
+		wantNegate bool
+	}{
+		{name: "skips blank lines", line: "   ", wantOK: false},
+		{name: "skips comments", line: "# build output", wantOK: false},
+		{name: "parses negation", line: "!keep.log", wantOK: true, wantNegate: true},
+		{name: "parses escaped bang as name", line: `\!important`, wantOK: true, wantNegate: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, ok := parseIgnoreRule(tt.line)
+			if ok != tt.wantOK {
+				t.Fatalf("parseIgnoreRule(%q) ok = %v, want %v", tt.line, ok, tt.wantOK)
+			}
+
+			if rule.negate != tt.wantNegate {
+				t.Errorf("parseIgnoreRule(%q) negate = %v, want %v", tt.line, rule.negate, tt.wantNegate)
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package tool
+
+import (
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	whitespace = regexp.MustCompile(`\s+`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// skippedElements hold navigation, scripts, and other content that isn't part
+// of the page's text.
+var skippedElements = map[atom.Atom]bool{
+	atom.Head:     true,
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Nav:      true,
+	atom.Aside:    true,
+	atom.Footer:   true,
+	atom.Form:     true,
+	atom.Button:   true,
+	atom.Iframe:   true,
+	atom.Svg:      true,
+	atom.Canvas:   true,
+}
+
+// htmlToMarkdown returns the title and text of the HTML page in r as markdown.
+// Links are resolved against base. The page's main or article element is used
+// when it has one.
+func htmlToMarkdown(r io.Reader, base *url.URL) (string, string, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", "", err
+	}
+
+	var title string
+	if node := findElement(doc, atom.Title); node != nil {
+		title = strings.TrimSpace(whitespace.ReplaceAllString(textContent(node), " "))
+	}
+
+	root := findElement(doc, atom.Main)
+	if root == nil {
+		root = findElement(doc, atom.Article)
+	}
+
+	if root == nil {
+		root = doc
+	}
+
+	writer := &markdownWriter{base: base}
+	writer.children(root)
+
+	return title, writer.String(), nil
+}
+
+// findElement returns the first element under node with the tag a.
+func findElement(node *html.Node, a atom.Atom) *html.Node {
+	for descendant := range node.Descendants() {
+		if descendant.Type == html.ElementNode && descendant.DataAtom == a {
+			return descendant
+		}
+	}
+
+	return nil
+}
+
+// textContent returns the text under node.
+func textContent(node *html.Node) string {
+	var text strings.Builder
+
+	for descendant := range node.Descendants() {
+		if descendant.Type == html.TextNode {
+			text.WriteString(descendant.Data)
+		}
+	}
+
+	return text.String()
+}
+
+// markdownWriter writes HTML nodes as markdown.
+type markdownWriter struct {
+	base *url.URL
+	out  strings.Builder
+	pre  bool // Inside a pre element, whitespace is kept.
+}
+
+// String returns the markdown with blank lines collapsed and trailing
+// whitespace trimmed.
+func (writer *markdownWriter) String() string {
+	lines := strings.Split(writer.out.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")) + "\n"
+}
+
+// render renders node into a new writer and returns its markdown.
+func (writer *markdownWriter) render(node *html.Node) string {
+	inner := &markdownWriter{base: writer.base, pre: writer.pre}
+	inner.children(node)
+
+	return strings.TrimSpace(inner.String())
+}
+
+func (writer *markdownWriter) children(node *html.Node) {
+	for child := range node.ChildNodes() {
+		writer.node(child)
+	}
+}
+
+// block starts a new paragraph.
+func (writer *markdownWriter) block() {
+	if writer.out.Len() > 0 {
+		writer.out.WriteString("\n\n")
+	}
+}
+
+// text writes text with its whitespace collapsed.
+func (writer *markdownWriter) text(text string) {
+	if writer.pre {
+		writer.out.WriteString(text)
+
+		return
+	}
+
+	text = whitespace.ReplaceAllString(text, " ")
+
+	written := writer.out.String()
+	if written == "" || strings.HasSuffix(written, "\n") || strings.HasSuffix(written, " ") {
+		text = strings.TrimLeft(text, " ")
+	}
+
+	writer.out.WriteString(text)
+}
+
+func (writer *markdownWriter) node(node *html.Node) {
+	switch node.Type {
+	case html.TextNode:
+		writer.text(node.Data)
+
+		return
+
+	case html.ElementNode:
+
+	default:
+		writer.children(node)
+
+		return
+	}
+
+	if skippedElements[node.DataAtom] {
+		return
+	}
+
+	switch node.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level, _ := strconv.Atoi(node.Data[1:])
+		if text := strings.ReplaceAll(writer.render(node), "\n", " "); text != "" {
+			writer.block()
+			writer.out.WriteString(strings.Repeat("#", level) + " " + text)
+			writer.block()
+		}
+
+	case atom.P, atom.Div, atom.Section, atom.Header, atom.Main, atom.Article, atom.Figure, atom.Dl, atom.Dd, atom.Dt:
+		writer.block()
+		writer.children(node)
+		writer.block()
+
+	case atom.Br:
+		writer.out.WriteString("\n")
+
+	case atom.Hr:
+		writer.block()
+		writer.out.WriteString("---")
+		writer.block()
+
+	case atom.Pre:
+		writer.block()
+		writer.out.WriteString("```\n" + strings.Trim(textContent(node), "\n") + "\n```")
+		writer.block()
+
+	case atom.Code:
+		if text := textContent(node); text != "" {
+			writer.out.WriteString("`" + text + "`")
+		}
+
+	case atom.Strong, atom.B:
+		writer.wrap(node, "**")
+
+	case atom.Em, atom.I:
+		writer.wrap(node, "_")
+
+	case atom.A:
+		writer.link(node)
+
+	case atom.Img:
+		if alt := strings.TrimSpace(attribute(node, "alt")); alt != "" {
+			writer.text("[image: " + alt + "]")
+		}
+
+	case atom.Ul, atom.Ol:
+		writer.list(node)
+
+	case atom.Blockquote:
+		if text := writer.render(node); text != "" {
+			writer.block()
+			writer.out.WriteString("> " + strings.ReplaceAll(text, "\n", "\n> "))
+			writer.block()
+		}
+
+	case atom.Table:
+		writer.table(node)
+
+	default:
+		writer.children(node)
+	}
+}
+
+// wrap writes the text of node between marker.
+func (writer *markdownWriter) wrap(node *html.Node, marker string) {
+	if text := writer.render(node); text != "" {
+		writer.text(" ")
+		writer.out.WriteString(marker + text + marker)
+	}
+}
+
+// link writes a link with its URL resolved against the page. Links within the
+// page and to scripts are written as text.
+func (writer *markdownWriter) link(node *html.Node) {
+	text := strings.ReplaceAll(writer.render(node), "\n", " ")
+	if text == "" {
+		return
+	}
+
+	href := strings.TrimSpace(attribute(node, "href"))
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		writer.text(text)
+
+		return
+	}
+
+	if target, err := url.Parse(href); err == nil && writer.base != nil {
+		href = writer.base.ResolveReference(target).String()
+	}
+
+	writer.text(" ")
+	writer.out.WriteString("[" + text + "](" + href + ")")
+}
+
+// list writes the items of a ul or ol element.
+func (writer *markdownWriter) list(node *html.Node) {
+	writer.block()
+
+	number := 1
+	for item := range node.ChildNodes() {
+		if item.Type != html.ElementNode || item.DataAtom != atom.Li {
+			continue
+		}
+
+		marker := "- "
+		if node.DataAtom == atom.Ol {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+
+		text := writer.render(item)
+		indent := strings.Repeat(" ", len(marker))
+
+		writer.out.WriteString(marker + strings.ReplaceAll(text, "\n", "\n"+indent) + "\n")
+	}
+
+	writer.block()
+}
+
+// table writes each row of a table as cells separated by pipes.
+func (writer *markdownWriter) table(node *html.Node) {
+	writer.block()
+
+	header := true
+
+	for row := range node.Descendants() {
+		if row.Type != html.ElementNode || row.DataAtom != atom.Tr {
+			continue
+		}
+
+		var cells []string
+		isHeader := false
+
+		for cell := range row.ChildNodes() {
+			if cell.Type != html.ElementNode || (cell.DataAtom != atom.Td && cell.DataAtom != atom.Th) {
+				continue
+			}
+
+			isHeader = isHeader || cell.DataAtom == atom.Th
+			cells = append(cells, strings.ReplaceAll(writer.render(cell), "\n", " "))
+		}
+
+		if len(cells) == 0 {
+			continue
+		}
+
+		writer.out.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+
+		if header && isHeader {
+			writer.out.WriteString(strings.Repeat("| --- ", len(cells)) + "|\n")
+		}
+
+		header = false
+	}
+
+	writer.block()
+}
+
+// attribute returns the value of node's attribute key.
+func attribute(node *html.Node, key string) string {
+	for _, attr := range node.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,408 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/charmbracelet/log"
+	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/internal/schema"
+)
+
+// mcpProtocolVersion is the MCP revision the client speaks.
+const mcpProtocolVersion = "2025-06-18"
+
+var (
+	ErrMCPConfig  = errors.New("MCP server needs a command or a url")
+	ErrMCPConnect = errors.New("MCP server link failed")
+	ErrMCPClosed  = errors.New("MCP server link closed")
+	ErrMCPExited  = errors.New("MCP server exited mid-call")
+	ErrMCPCall    = errors.New("MCP tool call failed")
+)
+
+// MCPServer configures an MCP server, launched over stdio when Command is set
+// or reached over streamable HTTP at URL.
+type MCPServer struct {
+	Name    string            // Name the server is logged by.
+	Command string            // Command that starts the server.
+	Args    []string          // Arguments passed to Command.
+	Env     map[string]string // Environment added to the server's.
+	URL     string            // URL of a streamable HTTP server.
+	Headers map[string]string // Headers sent with each HTTP request.
+}
+
+// mcpTransport sends JSON-RPC messages to an MCP server.
+type mcpTransport interface {
+	// call sends request and returns the result of its response.
+	// Returns ErrMCPClosed if the server is gone before the request reaches it,
+	// so it's safe to send again.
+	call(ctx context.Context, request mcpRequest) (json.RawMessage, error)
+
+	// notify sends request without waiting for a response.
+	notify(ctx context.Context, request mcpRequest) error
+
+	close() error
+}
+
+type mcpRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int64  `json:"id,omitempty"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+type mcpResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Result json.RawMessage `json:"result"`
+	Error  *mcpError       `json:"error"`
+}
+
+type mcpError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (err *mcpError) Error() string {
+	return fmt.Sprintf("%s (code %d)", err.Message, err.Code)
+}
+
+type mcpToolList struct {
+	Tools []struct {
+		Name        string          `json:"name"`
+		Description string          `json:"description"`
+		InputSchema json.RawMessage `json:"inputSchema"`
+	} `json:"tools"`
+	NextCursor string `json:"nextCursor"`
+}
+
+type mcpToolResult struct {
+	Content []struct {
+		Type     string `json:"type"`
+		Text     string `json:"text"`
+		Resource struct {
+			URI  string `json:"uri"`
+			Text string `json:"text"`
+		} `json:"resource"`
+	} `json:"content"`
+	IsError bool `json:"isError"`
+}
+
+// MCPClient is connected to an MCP server.
+// A server that dies or drops its session is reconnected on the next call.
+type MCPClient struct {
+	server    MCPServer
+	logger    *log.Logger
+	mu        sync.Mutex // Guards transport.
+	transport mcpTransport
+	nextID    atomic.Int64
+}
+
+// ConnectMCP starts or connects to the server and initializes the session.
+// Returns ErrMCPConfig if the server has no command or URL and ErrMCPConnect if
+// it can't be reached.
+func ConnectMCP(ctx context.Context, server MCPServer, logger *log.Logger) (*MCPClient, error) {
+	if server.Command == "" && server.URL == "" {
+		return nil, fmt.Errorf("%w: %s", ErrMCPConfig, server.Name)
+	}
+
+	client := &MCPClient{server: server, logger: logger}
+
+	transport, err := client.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	client.transport = transport
+
+	return client, nil
+}
+
+// connect opens a transport to the server and runs the initialize handshake.
+func (client *MCPClient) connect(ctx context.Context) (mcpTransport, error) {
+	var transport mcpTransport
+
+	if client.server.Command != "" {
+		stdio, err := startStdio(client.server, client.logger)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrMCPConnect, client.server.Name, err)
+		}
+
+		transport = stdio
+	} else {
+		transport = newHTTPTransport(client.server)
+	}
+
+	params := map[string]any{
+		"protocolVersion": mcpProtocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]string{"name": "ghost", "version": "3"},
+	}
+
+	if _, err := transport.call(ctx, client.request("initialize", params)); err != nil {
+		_ = transport.close()
+
+		return nil, fmt.Errorf("%w: %s: %w", ErrMCPConnect, client.server.Name, err)
+	}
+
+	if err := transport.notify(ctx, mcpRequest{JSONRPC: "2.0", Method: "notifications/initialized"}); err != nil {
+		_ = transport.close()
+
+		return nil, fmt.Errorf("%w: %s: %w", ErrMCPConnect, client.server.Name, err)
+	}
+
+	client.logger.Debug("MCP server connected", "server", client.server.Name)
+
+	return transport, nil
+}
+
+func (client *MCPClient) request(method string, params any) mcpRequest {
+	return mcpRequest{JSONRPC: "2.0", ID: client.nextID.Add(1), Method: method, Params: params}
+}
+
+// call sends the request over the current transport, reconnecting once if the
+// server is gone. A call the server may have seen isn't sent again.
+func (client *MCPClient) call(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	client.mu.Lock()
+	transport := client.transport
+	client.mu.Unlock()
+
+	result, err := transport.call(ctx, client.request(method, params))
+	if !errors.Is(err, ErrMCPClosed) {
+		return result, err
+	}
+
+	client.logger.Warn("MCP server link lost, reconnecting", "server", client.server.Name, "error", err)
+
+	client.mu.Lock()
+	// Another call may have reconnected already.
+	if client.transport == transport {
+		_ = transport.close()
+
+		transport, err = client.connect(ctx)
+		if err != nil {
+			client.mu.Unlock()
+
+			return nil, err
+		}
+
+		client.transport = transport
+	}
+	transport = client.transport
+	client.mu.Unlock()
+
+	return transport.call(ctx, client.request(method, params))
+}
+
+// Tools returns the server's tools.
+func (client *MCPClient) Tools(ctx context.Context) ([]MCPTool, error) {
+	var tools []MCPTool
+
+	cursor := ""
+
+	for {
+		params := map[string]any{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+
+		result, err := client.call(ctx, "tools/list", params)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrMCPConnect, client.server.Name, err)
+		}
+
+		var list mcpToolList
+		if err := json.Unmarshal(result, &list); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrMCPConnect, client.server.Name, err)
+		}
+
+		for _, remote := range list.Tools {
+			// Schemas the validator can't read leave validation to the parameters.
+			inputSchema, err := schema.Parse(remote.InputSchema)
+			if err != nil {
+				client.logger.Debug("MCP tool input schema not validated", "server", client.server.Name, "tool", remote.Name, "error", err)
+			}
+
+			tools = append(tools, MCPTool{
+				client:      client,
+				inputSchema: inputSchema,
+				definition: llm.Tool{
+					Type: "function",
+					Function: llm.ToolFunction{
+						Name:        remote.Name,
+						Description: remote.Description,
+						Parameters:  toolParameters(remote.InputSchema),
+					},
+				},
+			})
+		}
+
+		if list.NextCursor == "" {
+			return tools, nil
+		}
+
+		cursor = list.NextCursor
+	}
+}
+
+// CallTool calls the tool name with args and returns its text content.
+// Returns ErrMCPCall if the call fails or the tool reports an error.
+func (client *MCPClient) CallTool(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+
+	result, err := client.call(ctx, "tools/call", map[string]any{"name": name, "arguments": args})
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", ErrMCPCall, name, err)
+	}
+
+	var toolResult mcpToolResult
+	if err := json.Unmarshal(result, &toolResult); err != nil {
+		return "", fmt.Errorf("%w: %s: %w", ErrMCPCall, name, err)
+	}
+
+	var content []string
+	for _, item := range toolResult.Content {
+		switch item.Type {
+		case "text":
+			content = append(content, item.Text)
+
+		case "resource":
+			content = append(content, fmt.Sprintf("[resource %s]\n%s", item.Resource.URI, item.Resource.Text))
+
+		default:
+			content = append(content, fmt.Sprintf("[%s content omitted]", item.Type))
+		}
+	}
+
+	text := strings.Join(content, "\n")
+
+	if toolResult.IsError {
+		return "", fmt.Errorf("%w: %s: %s", ErrMCPCall, name, text)
+	}
+
+	return text, nil
+}
+
+// Close disconnects from the server, stopping it if it was launched.
+func (client *MCPClient) Close() error {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	return client.transport.close()
+}
+
+// MCPTool is a tool served by an MCP server.
+type MCPTool struct {
+	client      *MCPClient
+	definition  llm.Tool
+	inputSchema *schema.Schema // Schema as served, nil if it can't be parsed
+}
+
+// Definition returns the tool schema reported by the server.
+func (tool MCPTool) Definition() llm.Tool {
+	return tool.definition
+}
+
+// InputSchema returns the input schema reported by the server, which keeps the
+// types the definition drops.
+func (tool MCPTool) InputSchema() *schema.Schema {
+	return tool.inputSchema
+}
+
+// Execute forwards the call to the server.
+func (tool MCPTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	return tool.client.CallTool(ctx, tool.definition.Function.Name, args)
+}
+
+// toolParameters converts an MCP input schema to the tool parameters sent to
+// providers.
+// Properties with a list of types keep their first type that isn't null, the
+// arguments are still validated against the input schema.
+func toolParameters(inputSchema json.RawMessage) llm.ToolParameters {
+	parameters := llm.ToolParameters{Type: "object", Properties: map[string]llm.ToolProperty{}}
+
+	var decoded any
+	if err := json.Unmarshal(inputSchema, &decoded); err != nil {
+		return parameters
+	}
+
+	normalized, err := json.Marshal(singleTypes(decoded))
+	if err != nil {
+		return parameters
+	}
+
+	if err := json.Unmarshal(normalized, &parameters); err != nil {
+		return llm.ToolParameters{Type: "object", Properties: map[string]llm.ToolProperty{}}
+	}
+
+	parameters.Type = "object"
+	if parameters.Properties == nil {
+		parameters.Properties = map[string]llm.ToolProperty{}
+	}
+
+	return parameters
+}
+
+// singleTypes replaces the type lists in a decoded schema with their first
+// type that isn't null.
+func singleTypes(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, child := range value {
+			types, ok := child.([]any)
+			if key != "type" || !ok {
+				value[key] = singleTypes(child)
+
+				continue
+			}
+
+			delete(value, key)
+
+			for _, t := range types {
+				if name, ok := t.(string); ok && name != "null" {
+					value[key] = name
+
+					break
+				}
+			}
+		}
+
+	case []any:
+		for i, child := range value {
+			value[i] = singleTypes(child)
+		}
+	}
+
+	return value
+}
+
+// RegisterMCP registers the tools served by client.
+// Tools named like one already registered are skipped.
+func (registry *Registry) RegisterMCP(ctx context.Context, client *MCPClient, logger *log.Logger) error {
+	tools, err := client.Tools(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, tool := range tools {
+		name := tool.Definition().Function.Name
+
+		if _, ok := registry.Tools[name]; ok {
+			logger.Warn("MCP tool name taken, skipping", "server", client.server.Name, "name", name)
+
+			continue
+		}
+
+		registry.Register(tool)
+		logger.Debug("tool registered", "name", name, "server", client.server.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,400 @@
+package tool
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/google/go-cmp/cmp"
+	"github.com/theantichris/ghost/v3/internal/llm"
+)
+
+// testMCPServerEnv makes the test binary run as a stdio MCP server.
+const testMCPServerEnv = "GHOST_TEST_MCP_SERVER"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(testMCPServerEnv) == "1" {
+		runTestMCPServer(os.Stdin, os.Stdout)
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+type testMCPRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params struct {
+		Name      string            `json:"name"`
+		Arguments map[string]string `json:"arguments"`
+	} `json:"params"`
+}
+
+// runTestMCPServer serves MCP requests read from in until it closes.
+// The crash tool exits without responding.
+func runTestMCPServer(in io.Reader, out io.Writer) {
+	fmt.Fprintln(os.Stderr, "test server ready")
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		var request testMCPRequest
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil || request.ID == nil {
+			continue
+		}
+
+		if request.Method == "tools/call" && request.Params.Name == "crash" {
+			os.Exit(1)
+		}
+
+		response, _ := json.Marshal(testMCPResponse(request))
+		fmt.Fprintln(out, string(response))
+	}
+}
+
+// testMCPResponse returns the JSON-RPC response to request.
+func testMCPResponse(request testMCPRequest) map[string]any {
+	response := map[string]any{"jsonrpc": "2.0", "id": request.ID}
+
+	switch request.Method {
+	case "initialize":
+		response["result"] = map[string]any{"protocolVersion": mcpProtocolVersion, "capabilities": map[string]any{"tools": map[string]any{}}}
+
+	case "tools/list":
+		response["result"] = map[string]any{
+			"tools": []map[string]any{
+				{
+					"name":        "echo",
+					"description": "echoes text",
+					"inputSchema": map[string]any{
+						"type":     "object",
+						"required": []string{"text"},
+						"properties": map[string]any{
+							"text": map[string]any{"type": "string", "description": "text to echo"},
+							"note": map[string]any{"type": []string{"string", "null"}},
+						},
+					},
+				},
+				{"name": "fail", "description": "always fails", "inputSchema": map[string]any{"type": "object"}},
+			},
+		}
+
+	case "tools/call":
+		if request.Params.Name == "fail" {
+			response["result"] = map[string]any{"content": []map[string]any{{"type": "text", "text": "tracker offline"}}, "isError": true}
+
+			break
+		}
+
+		response["result"] = map[string]any{"content": []map[string]any{{"type": "text", "text": request.Params.Arguments["text"]}}}
+
+	default:
+		response["error"] = map[string]any{"code": -32601, "message": "method not found"}
+	}
+
+	return response
+}
+
+func newTestStdioServer(t *testing.T) MCPServer {
+	t.Helper()
+
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable() err = %v", err)
+	}
+
+	return MCPServer{Name: "test", Command: executable, Env: map[string]string{testMCPServerEnv: "1"}}
+}
+
+func TestMCPClient_Tools(t *testing.T) {
+	logger := log.New(io.Discard)
+
+	client, err := ConnectMCP(context.Background(), newTestStdioServer(t), logger)
+	if err != nil {
+		t.Fatalf("ConnectMCP() err = %v, want nil", err)
+	}
+	defer client.Close()
+
+	tools, err := client.Tools(context.Background())
+	if err != nil {
+		t.Fatalf("Tools() err = %v, want nil", err)
+	}
+
+	var got []llm.Tool
+	for _, tool := range tools {
+		got = append(got, tool.Definition())
+	}
+
+	want := []llm.Tool{
+		{
+			Type: "function",
+			Function: llm.ToolFunction{
+				Name:        "echo",
+				Description: "echoes text",
+				Parameters: llm.ToolParameters{
+					Type:     "object",
+					Required: []string{"text"},
+					Properties: map[string]llm.ToolProperty{
+						"text": {Type: "string", Description: "text to echo"},
+						"note": {Type: "string"},
+					},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: llm.ToolFunction{
+				Name:        "fail",
+				Description: "always fails",
+				Parameters:  llm.ToolParameters{Type: "object", Properties: map[string]llm.ToolProperty{}},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Tools() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMCPClient_CallTool(t *testing.T) {
+	tests := []struct {
+		name    string
+		tool    string
+		args    string
+		want    string
+		wantErr bool
+		err     error
+	}{
+		{
+			name: "returns text content",
+			tool: "echo",
+			args: `{"text":"jacked in"}`,
+			want: "jacked in",
+		},
+		{
+			name:    "returns error when tool reports one",
+			tool:    "fail",
+			args:    `{}`,
+			wantErr: true,
+			err:     ErrMCPCall,
+		},
+		{
+			name:    "returns error when server exits mid-call",
+			tool:    "crash",
+			args:    `{}`,
+			wantErr: true,
+			err:     ErrMCPExited,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := ConnectMCP(context.Background(), newTestStdioServer(t), log.New(io.Discard))
+			if err != nil {
+				t.Fatalf("ConnectMCP() err = %v, want nil", err)
+			}
+			defer client.Close()
+
+			got, err := client.CallTool(context.Background(), tt.tool, json.RawMessage(tt.args))
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("CallTool() err = %v, want %v", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("CallTool() err = %v, want nil", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("CallTool() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMCPClient_Reconnect(t *testing.T) {
+	client, err := ConnectMCP(context.Background(), newTestStdioServer(t), log.New(io.Discard))
+	if err != nil {
+		t.Fatalf("ConnectMCP() err = %v, want nil", err)
+	}
+	defer client.Close()
+
+	if _, err := client.CallTool(context.Background(), "crash", nil); !errors.Is(err, ErrMCPExited) {
+		t.Fatalf("CallTool(crash) err = %v, want %v", err, ErrMCPExited)
+	}
+
+	got, err := client.CallTool(context.Background(), "echo", json.RawMessage(`{"text":"back online"}`))
+	if err != nil {
+		t.Fatalf("CallTool() after crash err = %v, want nil", err)
+	}
+
+	if got != "back online" {
+		t.Errorf("CallTool() after crash = %q, want %q", got, "back online")
+	}
+}
+
+func TestMCPClient_HTTP(t *testing.T) {
+	var sessions atomic.Int32
+	var expired atomic.Bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			return
+		}
+
+		var request testMCPRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		if request.Method == "initialize" {
+			w.Header().Set("Mcp-Session-Id", fmt.Sprintf("session-%d", sessions.Add(1)))
+		} else if r.Header.Get("Mcp-Session-Id") == "" || (expired.Load() && r.Header.Get("Mcp-Session-Id") == "session-1") {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		if request.ID == nil {
+			w.WriteHeader(http.StatusAccepted)
+
+			return
+		}
+
+		response, _ := json.Marshal(testMCPResponse(request))
+
+		// Tool calls are answered as an event stream.
+		if request.Method == "tools/call" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\ndata: %s\n\n", response)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(response)
+	}))
+	defer server.Close()
+
+	client, err := ConnectMCP(context.Background(), MCPServer{Name: "test", URL: server.URL}, log.New(io.Discard))
+	if err != nil {
+		t.Fatalf("ConnectMCP() err = %v, want nil", err)
+	}
+	defer client.Close()
+
+	got, err := client.CallTool(context.Background(), "echo", json.RawMessage(`{"text":"streamed"}`))
+	if err != nil {
+		t.Fatalf("CallTool() err = %v, want nil", err)
+	}
+
+	if got != "streamed" {
+		t.Errorf("CallTool() = %q, want %q", got, "streamed")
+	}
+
+	expired.Store(true)
+
+	if _, err := client.CallTool(context.Background(), "echo", json.RawMessage(`{"text":"again"}`)); err != nil {
+		t.Fatalf("CallTool() after session expired err = %v, want nil", err)
+	}
+
+	if sessions.Load() != 2 {
+		t.Errorf("sessions = %d, want 2", sessions.Load())
+	}
+}
+
+func TestConnectMCP_Config(t *testing.T) {
+	_, err := ConnectMCP(context.Background(), MCPServer{Name: "empty"}, log.New(io.Discard))
+	if !errors.Is(err, ErrMCPConfig) {
+		t.Errorf("ConnectMCP() err = %v, want %v", err, ErrMCPConfig)
+	}
+}
+
+func TestRegisterMCP(t *testing.T) {
+	logger := log.New(io.Discard)
+
+	client, err := ConnectMCP(context.Background(), newTestStdioServer(t), logger)
+	if err != nil {
+		t.Fatalf("ConnectMCP() err = %v, want nil", err)
+	}
+	defer client.Close()
+
+	registry := NewRegistry(nil, 0, nil, logger)
+	registry.Register(MockTool{Name: "fail", Result: "local"})
+
+	if err := registry.RegisterMCP(context.Background(), client, logger); err != nil {
+		t.Fatalf("RegisterMCP() err = %v, want nil", err)
+	}
+
+	got, err := registry.Execute(context.Background(), "echo", json.RawMessage(`{"text":"via registry"}`))
+	if err != nil || got != "via registry" {
+		t.Errorf("Execute(echo) = %q, %v, want %q", got, err, "via registry")
+	}
+
+	// The definition drops null from the note's types, validation keeps it.
+	if _, err := registry.Execute(context.Background(), "echo", json.RawMessage(`{"text":"via registry","note":null}`)); err != nil {
+		t.Errorf("Execute(echo) err = %v, want nil for a null note", err)
+	}
+
+	if _, err := registry.Execute(context.Background(), "echo", json.RawMessage(`{"text":"via registry","note":7}`)); !errors.Is(err, ErrInvalidArgs) {
+		t.Errorf("Execute(echo) err = %v, want %v", err, ErrInvalidArgs)
+	}
+
+	if _, ok := registry.Tools["fail"].(MockTool); !ok {
+		t.Error("RegisterMCP() replaced a registered tool, want it skipped")
+	}
+}
+
+func TestToolParameters(t *testing.T) {
+	minimum := 1.0
+
+	tests := []struct {
+		name        string
+		inputSchema string
+		want        llm.ToolParameters
+	}{
+		{
+			name:        "keeps nested schemas",
+			inputSchema: `{"type":"object","required":["ids"],"properties":{"ids":{"type":"array","items":{"type":"integer","minimum":1}},"sort":{"type":"string","enum":["asc","desc"],"default":"asc"}}}`,
+			want: llm.ToolParameters{
+				Type:     "object",
+				Required: []string{"ids"},
+				Properties: map[string]llm.ToolProperty{
+					"ids":  {Type: "array", Items: &llm.ToolProperty{Type: "integer", Minimum: &minimum}},
+					"sort": {Type: "string", Enum: []any{"asc", "desc"}, Default: "asc"},
+				},
+			},
+		},
+		{
+			name:        "keeps first type that isn't null",
+			inputSchema: `{"type":"object","properties":{"note":{"type":["null","string"]}}}`,
+			want:        llm.ToolParameters{Type: "object", Properties: map[string]llm.ToolProperty{"note": {Type: "string"}}},
+		},
+		{
+			name:        "returns empty object for invalid schema",
+			inputSchema: `{"properties":"none"}`,
+			want:        llm.ToolParameters{Type: "object", Properties: map[string]llm.ToolProperty{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := toolParameters(json.RawMessage(tt.inputSchema))
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("toolParameters() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
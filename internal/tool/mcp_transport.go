@@ -0,0 +1,367 @@
+package tool
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carlmjohnson/requests"
+	"github.com/charmbracelet/log"
+)
+
+// mcpStopTimeout is how long a stdio server gets to exit after its input is
+// closed before it's killed.
+const mcpStopTimeout = 2 * time.Second
+
+// stdioTransport talks to a server launched as a child process, one JSON-RPC
+// message per line.
+type stdioTransport struct {
+	name    string
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	logger  *log.Logger
+	writeMu sync.Mutex // Serializes writes to stdin.
+	mu      sync.Mutex // Guards pending and err.
+	pending map[int64]chan mcpResponse
+	err     error         // Set once the server's output closes.
+	done    chan struct{} // Closed once the server's output closes.
+}
+
+// startStdio launches the server and starts reading its output.
+// The server's stderr is logged.
+func startStdio(server MCPServer, logger *log.Logger) (*stdioTransport, error) {
+	cmd := exec.Command(server.Command, server.Args...)
+
+	cmd.Env = os.Environ()
+	for key, value := range server.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	transport := &stdioTransport{
+		name:    server.Name,
+		cmd:     cmd,
+		stdin:   stdin,
+		logger:  logger,
+		pending: map[int64]chan mcpResponse{},
+		done:    make(chan struct{}),
+	}
+
+	go transport.logStderr(stderr)
+	go transport.read(stdout)
+
+	return transport, nil
+}
+
+func (transport *stdioTransport) logStderr(stderr io.Reader) {
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		transport.logger.Debug("MCP server stderr", "server", transport.name, "line", scanner.Text())
+	}
+}
+
+// read dispatches responses to the calls waiting on them until the server's
+// output closes.
+func (transport *stdioTransport) read(stdout io.Reader) {
+	reader := bufio.NewReader(stdout)
+
+	var err error
+
+	for {
+		var line []byte
+
+		line, err = reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			transport.dispatch(line)
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	transport.mu.Lock()
+	transport.err = fmt.Errorf("%w: %s: %w", ErrMCPClosed, transport.name, err)
+	transport.mu.Unlock()
+
+	close(transport.done)
+}
+
+func (transport *stdioTransport) dispatch(line []byte) {
+	var response mcpResponse
+	if err := json.Unmarshal(line, &response); err != nil {
+		transport.logger.Debug("MCP server sent invalid message", "server", transport.name, "error", err)
+
+		return
+	}
+
+	// Requests and notifications from the server aren't supported.
+	if response.Method != "" {
+		transport.logger.Debug("MCP server message ignored", "server", transport.name, "method", response.Method)
+
+		return
+	}
+
+	id, err := strconv.ParseInt(string(response.ID), 10, 64)
+	if err != nil {
+		return
+	}
+
+	transport.mu.Lock()
+	ch, ok := transport.pending[id]
+	delete(transport.pending, id)
+	transport.mu.Unlock()
+
+	if ok {
+		ch <- response
+	}
+}
+
+func (transport *stdioTransport) call(ctx context.Context, request mcpRequest) (json.RawMessage, error) {
+	ch := make(chan mcpResponse, 1)
+
+	transport.mu.Lock()
+	if transport.err != nil {
+		err := transport.err
+		transport.mu.Unlock()
+
+		return nil, err
+	}
+
+	transport.pending[request.ID] = ch
+	transport.mu.Unlock()
+
+	if err := transport.notify(ctx, request); err != nil {
+		transport.forget(request.ID)
+
+		return nil, err
+	}
+
+	select {
+	case response := <-ch:
+		if response.Error != nil {
+			return nil, response.Error
+		}
+
+		return response.Result, nil
+
+	case <-transport.done:
+		transport.forget(request.ID)
+
+		return nil, fmt.Errorf("%w: %s", ErrMCPExited, transport.name)
+
+	case <-ctx.Done():
+		transport.forget(request.ID)
+
+		cancel := mcpRequest{JSONRPC: "2.0", Method: "notifications/cancelled", Params: map[string]any{"requestId": request.ID}}
+		_ = transport.notify(context.Background(), cancel)
+
+		return nil, ctx.Err()
+	}
+}
+
+func (transport *stdioTransport) forget(id int64) {
+	transport.mu.Lock()
+	delete(transport.pending, id)
+	transport.mu.Unlock()
+}
+
+func (transport *stdioTransport) notify(ctx context.Context, request mcpRequest) error {
+	message, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	transport.writeMu.Lock()
+	defer transport.writeMu.Unlock()
+
+	if _, err := transport.stdin.Write(append(message, '\n')); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrMCPClosed, transport.name, err)
+	}
+
+	return nil
+}
+
+// close closes the server's input and waits for it to exit, killing it if it
+// doesn't.
+func (transport *stdioTransport) close() error {
+	_ = transport.stdin.Close()
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- transport.cmd.Wait()
+	}()
+
+	select {
+	case <-exited:
+		return nil
+
+	case <-time.After(mcpStopTimeout):
+		transport.logger.Warn("MCP server didn't exit, killing it", "server", transport.name)
+
+		return transport.cmd.Process.Kill()
+	}
+}
+
+// httpTransport talks to a server over streamable HTTP.
+type httpTransport struct {
+	server    MCPServer
+	mu        sync.Mutex // Guards sessionID.
+	sessionID string
+}
+
+func newHTTPTransport(server MCPServer) *httpTransport {
+	return &httpTransport{server: server}
+}
+
+// post sends request, passes the response to handle, and returns the response
+// headers.
+// Returns ErrMCPClosed if the server no longer knows the session.
+func (transport *httpTransport) post(ctx context.Context, request mcpRequest, handle requests.ResponseHandler) (http.Header, error) {
+	transport.mu.Lock()
+	sessionID := transport.sessionID
+	transport.mu.Unlock()
+
+	headers := http.Header{}
+
+	builder := requests.
+		URL(transport.server.URL).
+		BodyJSON(request).
+		Accept("application/json, text/event-stream").
+		Header("MCP-Protocol-Version", mcpProtocolVersion).
+		HeaderOptional("Mcp-Session-Id", sessionID).
+		CheckStatus(http.StatusOK, http.StatusAccepted).
+		CopyHeaders(headers).
+		Handle(handle)
+
+	for key, value := range transport.server.Headers {
+		builder.Header(key, value)
+	}
+
+	err := builder.Fetch(ctx)
+	if sessionID != "" && requests.HasStatusErr(err, http.StatusNotFound) {
+		return headers, fmt.Errorf("%w: %s: session expired", ErrMCPClosed, transport.server.Name)
+	}
+
+	return headers, err
+}
+
+func (transport *httpTransport) call(ctx context.Context, request mcpRequest) (json.RawMessage, error) {
+	var response mcpResponse
+
+	headers, err := transport.post(ctx, request, func(httpResponse *http.Response) error {
+		if strings.HasPrefix(httpResponse.Header.Get("Content-Type"), "text/event-stream") {
+			return readEventStream(httpResponse.Body, request.ID, &response)
+		}
+
+		return json.NewDecoder(httpResponse.Body).Decode(&response)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if sessionID := headers.Get("Mcp-Session-Id"); sessionID != "" {
+		transport.mu.Lock()
+		transport.sessionID = sessionID
+		transport.mu.Unlock()
+	}
+
+	if response.Error != nil {
+		return nil, response.Error
+	}
+
+	return response.Result, nil
+}
+
+func (transport *httpTransport) notify(ctx context.Context, request mcpRequest) error {
+	_, err := transport.post(ctx, request, func(*http.Response) error { return nil })
+
+	return err
+}
+
+// close ends the session.
+func (transport *httpTransport) close() error {
+	transport.mu.Lock()
+	sessionID := transport.sessionID
+	transport.mu.Unlock()
+
+	if sessionID == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mcpStopTimeout)
+	defer cancel()
+
+	// Servers may not allow ending sessions, the session expires either way.
+	_ = requests.URL(transport.server.URL).Method(http.MethodDelete).Header("Mcp-Session-Id", sessionID).Fetch(ctx)
+
+	return nil
+}
+
+// readEventStream reads server-sent events from body into response until the
+// response to the request id arrives.
+func readEventStream(body io.Reader, id int64, response *mcpResponse) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var data strings.Builder
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if value, ok := strings.CutPrefix(line, "data:"); ok {
+			data.WriteString(strings.TrimPrefix(value, " "))
+
+			continue
+		}
+
+		if line != "" || data.Len() == 0 {
+			continue
+		}
+
+		var event mcpResponse
+		err := json.Unmarshal([]byte(data.String()), &event)
+		data.Reset()
+
+		if err == nil && event.Method == "" && string(event.ID) == strconv.FormatInt(id, 10) {
+			*response = event
+
+			return nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return errors.New("event stream ended without a response")
+}
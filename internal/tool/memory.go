@@ -0,0 +1,123 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/internal/storage"
+)
+
+const (
+	memorySave   = "save"
+	memorySearch = "search"
+	memoryDelete = "delete"
+
+	maxMemoryResults = 10 // Memories returned by a search.
+)
+
+// Memory saves, searches, and deletes notes kept between threads.
+type Memory struct {
+	Store *storage.MemoryStore
+}
+
+// Definition returns the tool schema.
+func (memory Memory) Definition() llm.Tool {
+	return llm.Tool{
+		Type: "function",
+		Function: llm.ToolFunction{
+			Name:        "memory",
+			Description: "Long-term memory kept between conversations. Save facts worth remembering, like the user's preferences, projects, and conventions, search them when they could help, and delete those that are wrong or outdated.",
+			Parameters: llm.ToolParameters{
+				Type:     "object",
+				Required: []string{"action"},
+				Properties: map[string]llm.ToolProperty{
+					"action": {
+						Type:        "string",
+						Description: "save a memory, search memories, or delete a memory.",
+						Enum:        []any{memorySave, memorySearch, memoryDelete},
+					},
+					"content": {
+						Type:        "string",
+						Description: "The fact to remember, for save. Keep it short and self-contained.",
+					},
+					"query": {
+						Type:        "string",
+						Description: "Words to search for, for search. Empty lists the newest memories.",
+					},
+					"id": {
+						Type:        "string",
+						Description: "ID of the memory to delete, for delete.",
+					},
+				},
+			},
+		},
+	}
+}
+
+// Execute runs the action in args against the store.
+// Returns ErrInvalidArgs if an argument the action needs is missing.
+func (memory Memory) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var memoryArgs struct {
+		Action  string `json:"action"`
+		Content string `json:"content"`
+		Query   string `json:"query"`
+		ID      string `json:"id"`
+	}
+
+	if err := json.Unmarshal(args, &memoryArgs); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrParseArgs, err)
+	}
+
+	switch memoryArgs.Action {
+	case memorySave:
+		if strings.TrimSpace(memoryArgs.Content) == "" {
+			return "", fmt.Errorf("%w: save needs content", ErrInvalidArgs)
+		}
+
+		saved, err := memory.Store.Add(memoryArgs.Content)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("saved memory %s", saved.ID), nil
+
+	case memorySearch:
+		memories, err := memory.Store.Search(memoryArgs.Query, maxMemoryResults)
+		if err != nil {
+			return "", err
+		}
+
+		if len(memories) == 0 {
+			return "no memories found", nil
+		}
+
+		return FormatMemories(memories), nil
+
+	case memoryDelete:
+		if memoryArgs.ID == "" {
+			return "", fmt.Errorf("%w: delete needs id", ErrInvalidArgs)
+		}
+
+		if err := memory.Store.Delete(memoryArgs.ID); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("deleted memory %s", memoryArgs.ID), nil
+	}
+
+	return "", fmt.Errorf("%w: unknown action %q", ErrInvalidArgs, memoryArgs.Action)
+}
+
+// FormatMemories returns the memories one per line with their IDs.
+func FormatMemories(memories []storage.Memory) string {
+	var formatted strings.Builder
+
+	for _, memory := range memories {
+		fmt.Fprintf(&formatted, "- [%s] %s\n", memory.ID, memory.Content)
+	}
+
+	return strings.TrimSuffix(formatted.String(), "\n")
+}
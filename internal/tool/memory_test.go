@@ -0,0 +1,105 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/theantichris/ghost/v3/internal/storage"
+)
+
+func TestMemory_Execute(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     string
+		want     string
+		wantErr  bool
+		err      error
+		wantLeft int
+	}{
+		{
+			name:     "saves memory",
+			args:     `{"action":"save","content":"Prefer cobra for CLIs"}`,
+			want:     "saved memory ",
+			wantLeft: 2,
+		},
+		{
+			name:     "searches memories",
+			args:     `{"action":"search","query":"deploy"}`,
+			want:     "] The team deploys with Argo CD",
+			wantLeft: 1,
+		},
+		{
+			name:     "reports no matches",
+			args:     `{"action":"search","query":"braindance"}`,
+			want:     "no memories found",
+			wantLeft: 1,
+		},
+		{
+			name:     "deletes memory",
+			args:     `{"action":"delete","id":"%s"}`,
+			want:     "deleted memory ",
+			wantLeft: 0,
+		},
+		{
+			name:     "returns error for unknown ID",
+			args:     `{"action":"delete","id":"deadbeef"}`,
+			wantErr:  true,
+			err:      storage.ErrMemoryNotFound,
+			wantLeft: 1,
+		},
+		{
+			name:     "returns error for save without content",
+			args:     `{"action":"save","content":" "}`,
+			wantErr:  true,
+			err:      ErrInvalidArgs,
+			wantLeft: 1,
+		},
+		{
+			name:     "returns error for invalid JSON",
+			args:     `{"action":`,
+			wantErr:  true,
+			err:      ErrParseArgs,
+			wantLeft: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storage.NewMemoryStore(t.TempDir())
+
+			saved, err := store.Add("The team deploys with Argo CD")
+			if err != nil {
+				t.Fatalf("Add() err = %v, want nil", err)
+			}
+
+			args := tt.args
+			if strings.Contains(args, "%s") {
+				args = strings.Replace(args, "%s", saved.ID, 1)
+			}
+
+			got, err := Memory{Store: store}.Execute(context.Background(), json.RawMessage(args))
+
+			if tt.wantErr {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Execute() err = %v, want %v", err, tt.err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Execute() err = %v, want nil", err)
+				}
+
+				if !strings.Contains(got, tt.want) {
+					t.Errorf("Execute() = %q, want it to contain %q", got, tt.want)
+				}
+			}
+
+			memories, err := store.List()
+			if err != nil || len(memories) != tt.wantLeft {
+				t.Errorf("List() = %v, %v, want %d memories", memories, err, tt.wantLeft)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package tool
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/theantichris/ghost/v3/internal/llm"
 )
@@ -12,19 +13,29 @@ type MockTool struct {
 	Name   string
 	Result string
 	Err    error
+	Delay  time.Duration // Time Execute blocks for, ignoring its context like a hung tool.
+
+	Parameters llm.ToolParameters // Parameters of the definition, an object with any properties when empty.
 }
 
 func (t MockTool) Definition() llm.Tool {
+	parameters := t.Parameters
+	if parameters.Type == "" {
+		parameters.Type = "object"
+	}
+
 	return llm.Tool{
 		Type: "function",
 		Function: llm.ToolFunction{
 			Name:        t.Name,
 			Description: "mock tool",
-			Parameters:  llm.ToolParameters{Type: "object"},
+			Parameters:  parameters,
 		},
 	}
 }
 
 func (t MockTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	time.Sleep(t.Delay)
+
 	return t.Result, t.Err
 }
@@ -0,0 +1,67 @@
+package tool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Policy controls whether a tool call runs.
+type Policy string
+
+const (
+	PolicyAllow Policy = "allow" // Run without asking.
+	PolicyAsk   Policy = "ask"   // Ask the user before running.
+	PolicyDeny  Policy = "deny"  // Never run.
+)
+
+var (
+	ErrToolPolicy = errors.New("unknown tool policy: valid options are allow, ask, or deny")
+	ErrToolDenied = errors.New("tool call denied")
+)
+
+// CallPolicer is implemented by tools that set the policy of each call from
+// its arguments.
+type CallPolicer interface {
+	CallPolicy(args json.RawMessage) Policy
+}
+
+// ParsePolicy returns the Policy named by value.
+// Returns ErrToolPolicy if value isn't a policy.
+func ParsePolicy(value string) (Policy, error) {
+	switch policy := Policy(value); policy {
+	case PolicyAllow, PolicyAsk, PolicyDeny:
+		return policy, nil
+
+	default:
+		return "", fmt.Errorf("%w: %s", ErrToolPolicy, value)
+	}
+}
+
+// Policy returns the policy for the tool name.
+// Tools without a policy are allowed.
+func (registry *Registry) Policy(name string) Policy {
+	if policy, ok := registry.Policies[name]; ok {
+		return policy
+	}
+
+	return PolicyAllow
+}
+
+// CallPolicy returns the policy for a call to the tool name with args, the
+// stricter of the tool's policy and the policy the tool sets for the call.
+func (registry *Registry) CallPolicy(name string, args json.RawMessage) Policy {
+	policy := registry.Policy(name)
+
+	policer, ok := registry.Tools[name].(CallPolicer)
+	if !ok {
+		return policy
+	}
+
+	callPolicy := policer.CallPolicy(args)
+	if callPolicy == PolicyDeny || (callPolicy == PolicyAsk && policy == PolicyAllow) {
+		return callPolicy
+	}
+
+	return policy
+}
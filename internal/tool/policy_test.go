@@ -0,0 +1,54 @@
+package tool
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Policy
+		wantErr bool
+	}{
+		{name: "parses allow", value: "allow", want: PolicyAllow},
+		{name: "parses ask", value: "ask", want: PolicyAsk},
+		{name: "parses deny", value: "deny", want: PolicyDeny},
+		{name: "returns error for unknown policy", value: "maybe", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePolicy(tt.value)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrToolPolicy) {
+					t.Errorf("ParsePolicy() err = %v, want %v", err, ErrToolPolicy)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParsePolicy() err = %v, want nil", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("ParsePolicy() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	registry := Registry{Policies: map[string]Policy{"write_file": PolicyAsk}}
+
+	if got := registry.Policy("write_file"); got != PolicyAsk {
+		t.Errorf("Policy(write_file) = %q, want %q", got, PolicyAsk)
+	}
+
+	if got := registry.Policy("web_search"); got != PolicyAllow {
+		t.Errorf("Policy(web_search) = %q, want %q", got, PolicyAllow)
+	}
+}
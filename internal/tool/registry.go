@@ -1,16 +1,25 @@
 package tool
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/log"
 	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/internal/schema"
 )
 
-var ErrToolNotRegistered = errors.New("tool not registered")
+var (
+	ErrToolNotRegistered = errors.New("tool not registered")
+	ErrToolTimeout       = errors.New("tool timed out")
+	ErrInvalidArgs       = errors.New("invalid tool arguments")
+)
 
 // Tool is the interface that all the tools the LLM uses must implement.
 type Tool interface {
@@ -21,22 +30,45 @@ type Tool interface {
 // Registry holds all available tools, provides their definitions to send to chat
 // requests, and dispatches execution to the right tool by name.
 type Registry struct {
-	Tools map[string]Tool
+	Tools    map[string]Tool
+	Limits   Limits
+	Policies map[string]Policy // Policies by tool name, tools without one are allowed.
+}
+
+// Limits bounds how tools are executed. Zero values are unlimited.
+type Limits struct {
+	Concurrency   int                      // Max tool calls run at once, at least one runs.
+	Timeout       time.Duration            // Timeout for each tool call.
+	Timeouts      map[string]time.Duration // Timeouts by tool name, overrides Timeout.
+	MaxResultSize int                      // Max bytes of a result, longer results are truncated.
+	MaxIterations int                      // Max rounds of tool calls before a final answer is requested.
 }
 
 // NewRegistry creates a new Registry and initializes the tool map.
-func NewRegistry(tavilyAPIKey string, maxResults int, logger *log.Logger) Registry {
+// Web search is registered when search isn't nil and the filesystem tools are
+// registered when roots isn't empty and are confined to them.
+func NewRegistry(search SearchProvider, maxResults int, roots []string, logger *log.Logger) Registry {
 	registry := Registry{
 		Tools: map[string]Tool{},
 	}
 
-	if tavilyAPIKey != "" {
-		if maxResults == 0 {
-			maxResults = 5
+	if search != nil {
+		registry.Register(NewSearch(search, maxResults))
+		logger.Debug("tool registered", "name", "web_search")
+	}
+
+	if len(roots) > 0 {
+		sandbox, err := NewSandbox(roots)
+		if err != nil {
+			logger.Warn("file roots unavailable, skipping filesystem tools", "roots", roots, "error", err)
+
+			return registry
 		}
 
-		registry.Register(NewSearch(tavilyAPIKey, maxResults))
-		logger.Debug("tool registered", "name", "web_search")
+		for _, tool := range []Tool{ReadFile{Sandbox: sandbox}, ListDir{Sandbox: sandbox}, GrepFiles{Sandbox: sandbox}} {
+			registry.Register(tool)
+			logger.Debug("tool registered", "name", tool.Definition().Function.Name)
+		}
 	}
 
 	return registry
@@ -47,6 +79,26 @@ func (registry *Registry) Register(tool Tool) {
 	registry.Tools[tool.Definition().Function.Name] = tool
 }
 
+// Keep removes the tools not in names.
+// Returns the names that aren't registered.
+func (registry *Registry) Keep(names []string) []string {
+	var missing []string
+
+	for _, name := range names {
+		if _, ok := registry.Tools[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+
+	for name := range registry.Tools {
+		if !slices.Contains(names, name) {
+			delete(registry.Tools, name)
+		}
+	}
+
+	return missing
+}
+
 // Definitions returns a slice of all tool definitions.
 func (registry *Registry) Definitions() []llm.Tool {
 	var definitions []llm.Tool
@@ -58,16 +110,122 @@ func (registry *Registry) Definitions() []llm.Tool {
 	return definitions
 }
 
-// Execute looks up the tool by name, calls its Execute() function, and returns
-// the result.
-// Returns an error if the tool isn't found.
+// Execute looks up the tool by name, validates args against its parameters,
+// calls its Execute() function, and returns the result truncated to the max
+// result size.
+// Returns an error if the tool isn't found, ErrInvalidArgs if args don't match
+// its parameters, or ErrToolTimeout if it runs past its timeout.
 func (registry *Registry) Execute(ctx context.Context, name string, args json.RawMessage) (string, error) {
 	tool, ok := registry.Tools[name]
 	if !ok {
 		return "", fmt.Errorf("%w: %s", ErrToolNotRegistered, name)
 	}
 
-	result, err := tool.Execute(ctx, args)
+	if err := validateArgs(tool, args); err != nil {
+		return "", err
+	}
+
+	timeout := registry.Limits.Timeout
+	if toolTimeout, ok := registry.Limits.Timeouts[name]; ok {
+		timeout = toolTimeout
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	type outcome struct {
+		result string
+		err    error
+	}
+
+	// The tool runs in its own go routine so one that ignores its context can't
+	// stall the chat.
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := tool.Execute(ctx, args)
+		done <- outcome{result, err}
+	}()
+
+	select {
+	case outcome := <-done:
+		if outcome.err != nil {
+			return outcome.result, outcome.err
+		}
+
+		return truncate(outcome.result, registry.Limits.MaxResultSize), nil
+
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("%w: %s after %s", ErrToolTimeout, name, timeout)
+		}
+
+		return "", ctx.Err()
+	}
+}
+
+// InputSchemer is implemented by tools whose arguments are validated against
+// a schema fuller than the parameters of their definition.
+type InputSchemer interface {
+	InputSchema() *schema.Schema
+}
+
+// validateArgs validates args against the parameters of the tool definition,
+// or the tool's input schema when it has one.
+// Missing arguments are validated as an empty object.
+// Returns ErrInvalidArgs with the failure and the expected parameters so the
+// model can correct the call.
+func validateArgs(tool Tool, args json.RawMessage) error {
+	name := tool.Definition().Function.Name
+
+	parametersSchema := inputSchema(tool)
+	if parametersSchema == nil {
+		parameters, err := json.Marshal(tool.Definition().Function.Parameters)
+		if err != nil {
+			return nil
+		}
+
+		// A tool with invalid parameters is left for its Execute to reject.
+		parametersSchema, err = schema.Parse(parameters)
+		if err != nil {
+			return nil
+		}
+	}
+
+	if len(bytes.TrimSpace(args)) == 0 || bytes.Equal(bytes.TrimSpace(args), []byte("null")) {
+		args = json.RawMessage("{}")
+	}
+
+	if err := parametersSchema.Validate(args); err != nil {
+		return fmt.Errorf("%w for %s: %w\nexpected parameters: %s", ErrInvalidArgs, name, err, parametersSchema.Raw)
+	}
+
+	return nil
+}
+
+// inputSchema returns the input schema of tool, nil if it doesn't have one.
+func inputSchema(tool Tool) *schema.Schema {
+	schemer, ok := tool.(InputSchemer)
+	if !ok {
+		return nil
+	}
+
+	return schemer.InputSchema()
+}
+
+// truncate cuts result to maxSize bytes on a rune boundary and notes how much
+// was cut. Returns result unchanged if maxSize is 0.
+func truncate(result string, maxSize int) string {
+	if maxSize <= 0 || len(result) <= maxSize {
+		return result
+	}
+
+	cut := maxSize
+	for cut > 0 && !utf8.RuneStart(result[cut]) {
+		cut--
+	}
 
-	return result, err
+	return fmt.Sprintf("%s\n[truncated %d of %d bytes]", result[:cut], len(result)-cut, len(result))
 }
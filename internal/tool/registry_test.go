@@ -5,14 +5,17 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/theantichris/ghost/v3/internal/llm"
 )
 
 func TestRegister(t *testing.T) {
 	logger := log.New(io.Discard)
-	registry := NewRegistry("", 0, logger)
+	registry := NewRegistry(nil, 0, nil, logger)
 
 	tool := MockTool{
 		Name:   "mock tool",
@@ -29,7 +32,7 @@ func TestRegister(t *testing.T) {
 
 func TestDefinitions(t *testing.T) {
 	logger := log.New(io.Discard)
-	registry := NewRegistry("", 0, logger)
+	registry := NewRegistry(nil, 0, nil, logger)
 
 	tool := MockTool{
 		Name:   "mock tool",
@@ -49,11 +52,34 @@ func TestDefinitions(t *testing.T) {
 	}
 }
 
+func TestKeep(t *testing.T) {
+	registry := NewRegistry(nil, 0, nil, log.New(io.Discard))
+	registry.Register(MockTool{Name: "web_search"})
+	registry.Register(MockTool{Name: "fetch_url"})
+	registry.Register(MockTool{Name: "read_file"})
+
+	missing := registry.Keep([]string{"fetch_url", "read_file", "deck_scan"})
+
+	if len(registry.Tools) != 2 {
+		t.Errorf("Keep() tools = %d, want 2", len(registry.Tools))
+	}
+
+	if _, ok := registry.Tools["web_search"]; ok {
+		t.Error("Keep() kept web_search, want it removed")
+	}
+
+	if len(missing) != 1 || missing[0] != "deck_scan" {
+		t.Errorf("Keep() missing = %v, want [deck_scan]", missing)
+	}
+}
+
 func TestExecute(t *testing.T) {
 	tests := []struct {
 		name       string
 		tool       MockTool
 		calledTool string
+		limits     Limits
+		want       string
 		wantErr    bool
 		err        error
 	}{
@@ -61,6 +87,36 @@ func TestExecute(t *testing.T) {
 			name:       "executes tool",
 			tool:       MockTool{Name: "mock tool", Result: "mock result"},
 			calledTool: "mock tool",
+			want:       "mock result",
+		},
+		{
+			name:       "truncates oversized result",
+			tool:       MockTool{Name: "mock tool", Result: "mock result"},
+			calledTool: "mock tool",
+			limits:     Limits{MaxResultSize: 4},
+			want:       "mock\n[truncated 7 of 11 bytes]",
+		},
+		{
+			name:       "truncates on rune boundary",
+			tool:       MockTool{Name: "mock tool", Result: "ねこ"},
+			calledTool: "mock tool",
+			limits:     Limits{MaxResultSize: 4},
+			want:       "ね\n[truncated 3 of 6 bytes]",
+		},
+		{
+			name:       "returns error when tool times out",
+			tool:       MockTool{Name: "mock tool", Result: "mock result", Delay: time.Second},
+			calledTool: "mock tool",
+			limits:     Limits{Timeout: time.Millisecond},
+			wantErr:    true,
+			err:        ErrToolTimeout,
+		},
+		{
+			name:       "uses timeout by tool name",
+			tool:       MockTool{Name: "mock tool", Result: "mock result", Delay: 10 * time.Millisecond},
+			calledTool: "mock tool",
+			limits:     Limits{Timeout: time.Millisecond, Timeouts: map[string]time.Duration{"mock tool": time.Second}},
+			want:       "mock result",
 		},
 		{
 			name:       "returns error for unknown tool",
@@ -74,9 +130,10 @@ func TestExecute(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			logger := log.New(io.Discard)
-			registry := NewRegistry("", 0, logger)
+			registry := NewRegistry(nil, 0, nil, logger)
 
 			registry.Tools[tt.tool.Definition().Function.Name] = tt.tool
+			registry.Limits = tt.limits
 
 			got, err := registry.Execute(context.Background(), tt.calledTool, json.RawMessage{})
 
@@ -96,8 +153,75 @@ func TestExecute(t *testing.T) {
 				t.Fatalf("Execute() err = %v, want nil", err)
 			}
 
-			if got != tt.tool.Result {
-				t.Errorf("Execute() got = %s, want %s", got, tt.tool.Result)
+			if got != tt.want {
+				t.Errorf("Execute() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecute_Validation(t *testing.T) {
+	minLength := 1
+	maximum := 10.0
+
+	parameters := llm.ToolParameters{
+		Type:     "object",
+		Required: []string{"query"},
+		Properties: map[string]llm.ToolProperty{
+			"query": {Type: "string", MinLength: &minLength},
+			"mode":  {Type: "string", Enum: []any{"fast", "deep"}, Default: "fast"},
+			"limit": {Type: "integer", Maximum: &maximum},
+			"tags":  {Type: "array", Items: &llm.ToolProperty{Type: "string"}},
+			"filter": {
+				Type:       "object",
+				Required:   []string{"site"},
+				Properties: map[string]llm.ToolProperty{"site": {Type: "string"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		args     string
+		wantErr  bool
+		wantText string
+	}{
+		{name: "accepts valid arguments", args: `{"query":"ice","mode":"deep","limit":3,"tags":["a"],"filter":{"site":"night.city"}}`},
+		{name: "rejects missing required argument", args: `{}`, wantErr: true, wantText: `$: missing required property "query"`},
+		{name: "rejects missing arguments", args: ``, wantErr: true, wantText: `missing required property "query"`},
+		{name: "rejects wrong type", args: `{"query":42}`, wantErr: true, wantText: "$.query: expected string, got number"},
+		{name: "rejects value outside enum", args: `{"query":"ice","mode":"slow"}`, wantErr: true, wantText: "$.mode: value must be one of"},
+		{name: "rejects value over maximum", args: `{"query":"ice","limit":11}`, wantErr: true, wantText: "$.limit: expected maximum 10"},
+		{name: "rejects wrong item type", args: `{"query":"ice","tags":[1]}`, wantErr: true, wantText: "$.tags[0]: expected string"},
+		{name: "rejects nested missing property", args: `{"query":"ice","filter":{}}`, wantErr: true, wantText: `$.filter: missing required property "site"`},
+		{name: "rejects invalid JSON", args: `{"query":`, wantErr: true, wantText: "invalid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry(nil, 0, nil, log.New(io.Discard))
+			registry.Register(MockTool{Name: "lookup", Result: "found", Parameters: parameters})
+
+			got, err := registry.Execute(context.Background(), "lookup", json.RawMessage(tt.args))
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidArgs) {
+					t.Fatalf("Execute() err = %v, want %v", err, ErrInvalidArgs)
+				}
+
+				if !strings.Contains(err.Error(), tt.wantText) || !strings.Contains(err.Error(), `expected parameters: {"type":"object"`) {
+					t.Errorf("Execute() err = %q, want it to contain %q and the parameters", err, tt.wantText)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Execute() err = %v, want nil", err)
+			}
+
+			if got != "found" {
+				t.Errorf("Execute() = %q, want %q", got, "found")
 			}
 		})
 	}
@@ -7,47 +7,44 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/carlmjohnson/requests"
 	"github.com/theantichris/ghost/v3/internal/llm"
 )
 
-const tavilyURL = "https://api.tavily.com/search"
-
 var (
-	ErrNoAPIKey     = errors.New("tavily API key not found")
-	ErrSearchFailed = errors.New("matrix search failed")
-	ErrParseArgs    = errors.New("failed to parse arguments")
+	ErrNoAPIKey       = errors.New("search API key not found")
+	ErrSearchFailed   = errors.New("matrix search failed")
+	ErrSearchProvider = errors.New("unknown search provider: valid options are tavily, searxng, or brave")
+	ErrParseArgs      = errors.New("failed to parse arguments")
 )
 
-type searchRequest struct {
-	APIKey     string `json:"api_key"`
-	Query      string `json:"query"`
-	MaxResults int    `json:"max_results"`
-}
-
-type searchResults struct {
-	Title   string `json:"title"`
-	URL     string `json:"url"`
-	Content string `json:"content"`
+// SearchResult is a web search result.
+type SearchResult struct {
+	Title   string
+	URL     string
+	Content string
 }
 
-type searchResponse struct {
-	Results []searchResults `json:"results"`
+// SearchProvider is the interface search backends implement.
+type SearchProvider interface {
+	Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error)
 }
 
 // Search holds the web search configuration.
 type Search struct {
-	APIKey     string
+	Provider   SearchProvider
 	MaxResults int
-	URL        string
 }
 
 // NewSearch creates and returns a new search config.
-func NewSearch(apiKey string, maxResults int) Search {
+// Returns 5 results when maxResults is 0.
+func NewSearch(provider SearchProvider, maxResults int) Search {
+	if maxResults == 0 {
+		maxResults = 5
+	}
+
 	return Search{
-		APIKey:     apiKey,
+		Provider:   provider,
 		MaxResults: maxResults,
-		URL:        tavilyURL,
 	}
 }
 
@@ -76,8 +73,8 @@ func (search Search) Definition() llm.Tool {
 	return searchTool
 }
 
-// Execute parses the arguments into the search query, makes a request to the
-// Tavily API, then formats and returns the results as a string.
+// Execute parses the arguments into the search query, searches with the
+// provider, then formats and returns the results as a string.
 func (search Search) Execute(ctx context.Context, args json.RawMessage) (string, error) {
 	var searchArgs struct {
 		Query string `json:"query"`
@@ -87,25 +84,13 @@ func (search Search) Execute(ctx context.Context, args json.RawMessage) (string,
 		return "", fmt.Errorf("%w: %w", ErrParseArgs, err)
 	}
 
-	req := searchRequest{
-		APIKey:     search.APIKey,
-		Query:      searchArgs.Query,
-		MaxResults: search.MaxResults,
-	}
-
-	var resp searchResponse
-
-	err := requests.URL(search.URL).
-		BodyJSON(&req).
-		ToJSON(&resp).
-		Fetch(ctx)
-
+	results, err := search.Provider.Search(ctx, searchArgs.Query, search.MaxResults)
 	if err != nil {
 		return "", fmt.Errorf("%w: %w", ErrSearchFailed, err)
 	}
 
 	var sb strings.Builder
-	for i, result := range resp.Results {
+	for i, result := range results {
 		fmt.Fprintf(&sb, "Result: %d: %s\n", i+1, result.Title)
 		fmt.Fprintf(&sb, "URL: %s\n", result.URL)
 		fmt.Fprintf(&sb, "%s\n\n", result.Content)
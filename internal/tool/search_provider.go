@@ -0,0 +1,204 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/carlmjohnson/requests"
+)
+
+const (
+	tavilyURL = "https://api.tavily.com/search"
+	braveURL  = "https://api.search.brave.com/res/v1/web/search"
+)
+
+// htmlTags matches the tags search APIs use to highlight matches.
+var htmlTags = regexp.MustCompile(`<[^>]*>`)
+
+// NewSearchProvider returns the search provider named by name, tavily when
+// it's empty. apiURL overrides the provider's API URL and is required for
+// SearXNG.
+// Returns ErrSearchProvider for unknown providers and ErrNoAPIKey when the
+// provider needs a key and apiKey is empty.
+func NewSearchProvider(name, apiKey, apiURL string) (SearchProvider, error) {
+	switch strings.ToLower(name) {
+	case "", "tavily":
+		if apiKey == "" {
+			return nil, fmt.Errorf("%w: tavily", ErrNoAPIKey)
+		}
+
+		if apiURL == "" {
+			apiURL = tavilyURL
+		}
+
+		return Tavily{APIKey: apiKey, URL: apiURL}, nil
+
+	case "searxng":
+		if apiURL == "" {
+			return nil, fmt.Errorf("%w: searxng needs the URL of an instance", ErrSearchProvider)
+		}
+
+		return SearXNG{URL: apiURL}, nil
+
+	case "brave":
+		if apiKey == "" {
+			return nil, fmt.Errorf("%w: brave", ErrNoAPIKey)
+		}
+
+		if apiURL == "" {
+			apiURL = braveURL
+		}
+
+		return Brave{APIKey: apiKey, URL: apiURL}, nil
+
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrSearchProvider, name)
+	}
+}
+
+// Tavily searches with the Tavily API.
+type Tavily struct {
+	APIKey string
+	URL    string
+}
+
+type tavilyRequest struct {
+	APIKey     string `json:"api_key"`
+	Query      string `json:"query"`
+	MaxResults int    `json:"max_results"`
+}
+
+type tavilyResponse struct {
+	Results []struct {
+		Title   string `json:"title"`
+		URL     string `json:"url"`
+		Content string `json:"content"`
+	} `json:"results"`
+}
+
+// Search returns Tavily's results for query.
+func (tavily Tavily) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	req := tavilyRequest{
+		APIKey:     tavily.APIKey,
+		Query:      query,
+		MaxResults: maxResults,
+	}
+
+	var resp tavilyResponse
+
+	err := requests.URL(tavily.URL).
+		BodyJSON(&req).
+		ToJSON(&resp).
+		Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []SearchResult
+	for _, result := range resp.Results {
+		results = append(results, SearchResult{Title: result.Title, URL: result.URL, Content: result.Content})
+	}
+
+	return results, nil
+}
+
+// SearXNG searches with a SearXNG instance. The instance must have the JSON
+// format enabled.
+type SearXNG struct {
+	URL string // Base URL of the instance.
+}
+
+type searxngResponse struct {
+	Results []struct {
+		Title   string `json:"title"`
+		URL     string `json:"url"`
+		Content string `json:"content"`
+	} `json:"results"`
+}
+
+// Search returns the instance's first maxResults results for query.
+func (searxng SearXNG) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	searchURL, err := url.JoinPath(searxng.URL, "search")
+	if err != nil {
+		return nil, err
+	}
+
+	var resp searxngResponse
+
+	err = requests.URL(searchURL).
+		Param("q", query).
+		Param("format", "json").
+		ToJSON(&resp).
+		Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []SearchResult
+	for _, result := range resp.Results {
+		if maxResults > 0 && len(results) == maxResults {
+			break
+		}
+
+		results = append(results, SearchResult{Title: result.Title, URL: result.URL, Content: result.Content})
+	}
+
+	return results, nil
+}
+
+// Brave searches with the Brave Search API.
+type Brave struct {
+	APIKey string
+	URL    string
+}
+
+type braveResponse struct {
+	Web struct {
+		Results []struct {
+			Title       string `json:"title"`
+			URL         string `json:"url"`
+			Description string `json:"description"`
+		} `json:"results"`
+	} `json:"web"`
+}
+
+// Search returns Brave's web results for query with their highlighting
+// removed.
+func (brave Brave) Search(ctx context.Context, query string, maxResults int) ([]SearchResult, error) {
+	var resp braveResponse
+
+	builder := requests.URL(brave.URL).
+		Param("q", query).
+		Header("X-Subscription-Token", brave.APIKey).
+		Accept("application/json").
+		ToJSON(&resp)
+
+	if maxResults > 0 {
+		builder.Param("count", strconv.Itoa(maxResults))
+	}
+
+	if err := builder.Fetch(ctx); err != nil {
+		return nil, err
+	}
+
+	var results []SearchResult
+	for _, result := range resp.Web.Results {
+		results = append(results, SearchResult{
+			Title:   stripHTML(result.Title),
+			URL:     result.URL,
+			Content: stripHTML(result.Description),
+		})
+	}
+
+	return results, nil
+}
+
+// stripHTML removes tags from text and unescapes its entities.
+func stripHTML(text string) string {
+	return html.UnescapeString(htmlTags.ReplaceAllString(text, ""))
+}
@@ -0,0 +1,124 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewSearchProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		apiKey   string
+		apiURL   string
+		want     SearchProvider
+		err      error
+	}{
+		{name: "defaults to tavily", apiKey: "tvly-key", want: Tavily{APIKey: "tvly-key", URL: tavilyURL}},
+		{name: "creates searxng", provider: "SearXNG", apiURL: "https://searx.night.city", want: SearXNG{URL: "https://searx.night.city"}},
+		{name: "creates brave", provider: "brave", apiKey: "brave-key", want: Brave{APIKey: "brave-key", URL: braveURL}},
+		{name: "overrides API URL", provider: "tavily", apiKey: "tvly-key", apiURL: "http://proxy", want: Tavily{APIKey: "tvly-key", URL: "http://proxy"}},
+		{name: "returns error for tavily without key", provider: "tavily", err: ErrNoAPIKey},
+		{name: "returns error for brave without key", provider: "brave", err: ErrNoAPIKey},
+		{name: "returns error for searxng without URL", provider: "searxng", err: ErrSearchProvider},
+		{name: "returns error for unknown provider", provider: "altavista", err: ErrSearchProvider},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSearchProvider(tt.provider, tt.apiKey, tt.apiURL)
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("NewSearchProvider() err = %v, want %v", err, tt.err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("NewSearchProvider() err = %v, want nil", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("NewSearchProvider() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSearchProviders(t *testing.T) {
+	want := []SearchResult{
+		{Title: "Night City", URL: "https://night.city", Content: "Welcome to Night City"},
+		{Title: "Afterlife", URL: "https://afterlife.bar", Content: "Legends drink here"},
+	}
+
+	tests := []struct {
+		name     string
+		provider func(url string) SearchProvider
+		check    func(t *testing.T, r *http.Request)
+		response string
+	}{
+		{
+			name:     "tavily",
+			provider: func(url string) SearchProvider { return Tavily{APIKey: "tvly-key", URL: url} },
+			check: func(t *testing.T, r *http.Request) {
+				var req tavilyRequest
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Errorf("failed to decode request: %v", err)
+				}
+
+				if req != (tavilyRequest{APIKey: "tvly-key", Query: "night city", MaxResults: 2}) {
+					t.Errorf("request = %+v, want key, query, and max results", req)
+				}
+			},
+			response: `{"results":[{"title":"Night City","url":"https://night.city","content":"Welcome to Night City"},{"title":"Afterlife","url":"https://afterlife.bar","content":"Legends drink here"}]}`,
+		},
+		{
+			name:     "searxng",
+			provider: func(url string) SearchProvider { return SearXNG{URL: url + "/searx"} },
+			check: func(t *testing.T, r *http.Request) {
+				if r.URL.Path != "/searx/search" || r.URL.Query().Get("q") != "night city" || r.URL.Query().Get("format") != "json" {
+					t.Errorf("request = %s, want /searx/search with query and json format", r.URL)
+				}
+			},
+			response: `{"results":[{"title":"Night City","url":"https://night.city","content":"Welcome to Night City"},{"title":"Afterlife","url":"https://afterlife.bar","content":"Legends drink here"},{"title":"Extra","url":"https://extra","content":"cut"}]}`,
+		},
+		{
+			name:     "brave",
+			provider: func(url string) SearchProvider { return Brave{APIKey: "brave-key", URL: url} },
+			check: func(t *testing.T, r *http.Request) {
+				if r.Header.Get("X-Subscription-Token") != "brave-key" || r.URL.Query().Get("q") != "night city" || r.URL.Query().Get("count") != "2" {
+					t.Errorf("request = %s with token %q, want query, count, and token", r.URL, r.Header.Get("X-Subscription-Token"))
+				}
+			},
+			response: `{"web":{"results":[{"title":"Night <strong>City</strong>","url":"https://night.city","description":"Welcome to <strong>Night City</strong>"},{"title":"Afterlife","url":"https://afterlife.bar","description":"Legends drink here"}]}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.check(t, r)
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			got, err := tt.provider(server.URL).Search(context.Background(), "night city", 2)
+			if err != nil {
+				t.Fatalf("Search() err = %v, want nil", err)
+			}
+
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("Search() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
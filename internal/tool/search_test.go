@@ -65,11 +65,7 @@ func TestSearchExecute(t *testing.T) {
 			}))
 			defer server.Close()
 
-			search := Search{
-				APIKey:     "test-key",
-				MaxResults: 5,
-				URL:        server.URL,
-			}
+			search := NewSearch(Tavily{APIKey: "test-key", URL: server.URL}, 5)
 
 			got, err := search.Execute(context.Background(), json.RawMessage(tt.args))
 
@@ -97,7 +93,7 @@ func TestSearchExecute(t *testing.T) {
 }
 
 func TestSearchDefinition(t *testing.T) {
-	search := NewSearch("test-key", 5)
+	search := NewSearch(Tavily{APIKey: "test-key"}, 5)
 
 	def := search.Definition()
 
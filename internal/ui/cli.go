@@ -2,7 +2,9 @@ package ui
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"strings"
 
 	"charm.land/bubbles/v2/key"
 	"charm.land/bubbles/v2/spinner"
@@ -10,6 +12,7 @@ import (
 	"github.com/charmbracelet/log"
 	"github.com/theantichris/ghost/v3/internal/agent"
 	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/internal/schema"
 	"github.com/theantichris/ghost/v3/internal/tool"
 	"github.com/theantichris/ghost/v3/style"
 )
@@ -21,6 +24,14 @@ var quitKeys = key.NewBinding(
 // StreamChunkMsg represents a chunk of text received from the LLM.
 type StreamChunkMsg string
 
+// StreamThinkingMsg represents a chunk of thinking received from the LLM.
+type StreamThinkingMsg string
+
+// StreamRetryMsg signals the response failed validation and is being retried.
+type StreamRetryMsg struct {
+	Err error
+}
+
 // StreamErrorMsg signals an error occurred during streaming.
 type StreamErrorMsg struct {
 	Err error
@@ -28,22 +39,31 @@ type StreamErrorMsg struct {
 
 // CLIModel handles the UI for streaming LLM responses.
 type CLIModel struct {
-	ctx          context.Context
-	prompts      agent.Prompt
-	logger       *log.Logger // Logger for error visibility.
-	width        int         // Terminal width
-	content      string      // Accumulated response content.
-	messages     []llm.ChatMessage
-	url          string
-	model        string
-	visionModel  string
-	images       []string
-	toolRegistry tool.Registry
-	done         bool          // Whether streaming has finished.
-	Err          error         // Error if streaming failed.
-	spinner      spinner.Model // Animated spinner.
-	format       string        // Format for output.
-	responseCh   chan tea.Msg
+	ctx           context.Context
+	prompts       agent.Prompt
+	logger        *log.Logger // Logger for error visibility.
+	width         int         // Terminal width
+	content       string      // Accumulated response content.
+	thinking      string      // Accumulated thinking, shown while streaming.
+	hideThinking  bool        // Whether thinking is hidden while streaming.
+	think         *bool
+	metrics       *llm.Metrics // Metrics of the response, nil if not reported.
+	warnings      []error      // Warnings from the tool loop.
+	messages      []llm.ChatMessage
+	provider      llm.Provider
+	model         string
+	visionModel   string
+	options       llm.Options
+	images        []string
+	toolRegistry  tool.Registry
+	autoApprove   bool          // Approves tool calls that ask, they're denied otherwise.
+	done          bool          // Whether streaming has finished.
+	Err           error         // Error if streaming failed.
+	spinner       spinner.Model // Animated spinner.
+	format        string        // Format for output.
+	schema        *schema.Schema
+	schemaRetries int
+	responseCh    chan tea.Msg
 }
 
 // NewCLIModel creates and returns CLIModel.
@@ -68,25 +88,38 @@ func NewCLIModel(config ModelConfig, userPrompt string) (CLIModel, error) {
 	messages = append(messages, llm.ChatMessage{Role: llm.RoleUser, Content: userPrompt})
 
 	return CLIModel{
-		ctx:          config.Context,
-		prompts:      config.Prompts,
-		logger:       config.Logger,
-		width:        80,
-		messages:     messages,
-		url:          config.URL,
-		model:        config.ChatLLM,
-		visionModel:  config.VisionLLM,
-		images:       config.Images,
-		toolRegistry: config.Registry,
-		content:      "",
-		done:         false,
-		Err:          nil,
-		spinner:      s,
-		format:       config.Format,
-		responseCh:   make(chan tea.Msg),
+		ctx:           config.Context,
+		prompts:       config.Prompts,
+		logger:        config.Logger,
+		width:         80,
+		messages:      messages,
+		provider:      config.Provider,
+		model:         config.ChatLLM,
+		visionModel:   config.VisionLLM,
+		options:       config.Options,
+		think:         config.Think,
+		hideThinking:  config.HideThinking,
+		images:        config.Images,
+		toolRegistry:  config.Registry,
+		autoApprove:   config.AutoApprove,
+		content:       "",
+		done:          false,
+		Err:           nil,
+		spinner:       s,
+		format:        config.Format,
+		schema:        config.Schema,
+		schemaRetries: config.SchemaRetries,
+		responseCh:    make(chan tea.Msg),
 	}, nil
 }
 
+// Restart returns the model ready to send its request again.
+func (model CLIModel) Restart() CLIModel {
+	model.responseCh = make(chan tea.Msg)
+
+	return model
+}
+
 // Init starts the spinner's animation loop and the LLM response stream.
 func (model CLIModel) Init() tea.Cmd {
 	return tea.Batch(model.spinner.Tick, model.startStream())
@@ -110,6 +143,35 @@ func (model CLIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		return model, listenForChunk(model.responseCh)
 
+	case StreamThinkingMsg:
+		model.thinking += string(msg)
+
+		return model, listenForChunk(model.responseCh)
+
+	case StreamRetryMsg:
+		model.logger.Debug("discarding invalid response", "error", msg.Err)
+		model.content = ""
+		model.thinking = ""
+
+		return model, listenForChunk(model.responseCh)
+
+	case ToolCallsMsg:
+		model.logger.Debug("discarding text streamed before tool calls", "calls", len(msg.Response.ToolCalls))
+		model.content = ""
+
+		return model, listenForChunk(model.responseCh)
+
+	case LLMMetricsMsg:
+		model.metrics = &msg.Metrics
+
+		return model, listenForChunk(model.responseCh)
+
+	case LLMWarningMsg:
+		model.logger.Warn("tool loop warning", "error", msg.Err)
+		model.warnings = append(model.warnings, msg.Err)
+
+		return model, listenForChunk(model.responseCh)
+
 	case LLMDoneMsg:
 		model.done = true
 
@@ -137,6 +199,16 @@ func (model CLIModel) View() tea.View {
 		return tea.NewView("") // Clear the view.
 	}
 
+	var warnings string
+	for _, warning := range model.warnings {
+		warnings += style.FgTextMuted.Render(fmt.Sprintf("%s warning: %v", style.GlyphInfo, warning)) + "\n"
+	}
+
+	var thinking string
+	if model.thinking != "" && !model.hideThinking {
+		thinking = style.WordWrap(model.width, strings.TrimSpace(model.thinking), style.FgTextMuted) + "\n\n"
+	}
+
 	if model.content != "" {
 		content, err := style.RenderContent(model.content, model.format, true)
 		if err != nil {
@@ -148,16 +220,21 @@ func (model CLIModel) View() tea.View {
 			content = style.WordWrap(model.width, content, style.FgText)
 		}
 
-		return tea.NewView(content)
+		return tea.NewView(warnings + thinking + content)
+	}
+
+	if thinking != "" {
+		return tea.NewView(warnings + thinking + model.spinner.View())
 	}
 
 	processingMessage := style.FgAccent0.Render(style.GlyphInfo+" processing") + model.spinner.View()
 
-	return tea.NewView(processingMessage)
+	return tea.NewView(warnings + processingMessage)
 }
 
 // Content returns the full model content with styling for normal text.
 // JSON and Markdown output are returned raw.
+// Thinking is never included.
 func (model CLIModel) Content() string {
 	if model.format == "json" || model.format == "markdown" {
 		return model.content
@@ -166,6 +243,17 @@ func (model CLIModel) Content() string {
 	return style.WordWrap(model.width, model.content, style.FgText)
 }
 
+// Metrics returns the token usage and timing of the response.
+// Returns nil if the provider didn't report them.
+func (model CLIModel) Metrics() *llm.Metrics {
+	return model.metrics
+}
+
+// Warnings returns the warnings from the tool loop.
+func (model CLIModel) Warnings() []error {
+	return model.warnings
+}
+
 func (model CLIModel) startStream() tea.Cmd {
 	model.logger.Debug("establishing to neural network", "model", model.model, "messages", len(model.messages))
 
@@ -173,7 +261,7 @@ func (model CLIModel) startStream() tea.Cmd {
 		ch := model.responseCh
 		defer close(ch)
 
-		imageAnalysis, err := agent.AnalyseImages(model.ctx, model.url, model.visionModel, model.prompts, model.images, model.logger)
+		imageAnalysis, err := agent.AnalyseImages(model.ctx, model.provider, model.visionModel, model.options, model.prompts, model.images, model.logger)
 		if err != nil {
 			ch <- StreamErrorMsg{Err: err}
 
@@ -182,22 +270,53 @@ func (model CLIModel) startStream() tea.Cmd {
 
 		model.messages = append(model.messages, imageAnalysis...)
 
-		model.messages, err = agent.RunToolLoop(model.ctx, model.toolRegistry, model.url, model.model, model.messages, model.logger)
-		if err != nil {
-			ch <- StreamErrorMsg{Err: err}
+		onChunk := func(chunk llm.ChatMessage) {
+			if chunk.Thinking != "" {
+				ch <- StreamThinkingMsg(chunk.Thinking)
+			}
 
-			return
+			if chunk.Content != "" {
+				ch <- StreamChunkMsg(chunk.Content)
+			}
 		}
 
-		_, err = llm.StreamChat(model.ctx, model.url, model.model, model.messages, nil, func(chunk string) {
-			ch <- StreamChunkMsg(chunk)
-		})
+		onWarning := func(err error) {
+			ch <- LLMWarningMsg{Err: err}
+		}
+
+		// There's no prompt in one-shot mode so tools that ask are answered by
+		// --yes.
+		approve := func(llm.ToolCall) bool {
+			return model.autoApprove
+		}
+
+		// Text streamed before tool calls isn't part of the answer.
+		onToolCalls := func(response llm.ChatMessage, results []llm.ChatMessage) {
+			ch <- ToolCallsMsg{Response: response, Results: results}
+		}
+
+		request := llm.ChatRequest{Model: model.model, Messages: model.messages, Options: model.options, Think: model.think}
+		loopOptions := agent.ToolLoopOptions{OnChunk: onChunk, OnWarning: onWarning, Approve: approve, OnToolCalls: onToolCalls}
+
+		if model.schema != nil {
+			onRetry := func(err error) {
+				ch <- StreamRetryMsg{Err: err}
+			}
+
+			model.messages, err = agent.RunSchemaLoop(model.ctx, model.toolRegistry, model.provider, request, model.schema, model.schemaRetries, onRetry, loopOptions, model.logger)
+		} else {
+			model.messages, err = agent.RunToolLoop(model.ctx, model.toolRegistry, model.provider, request, loopOptions, model.logger)
+		}
 
 		if err != nil {
 			ch <- StreamErrorMsg{Err: err}
 
 			return
 		}
+
+		if metrics := model.messages[len(model.messages)-1].Metrics; metrics != nil {
+			ch <- LLMMetricsMsg{Metrics: *metrics}
+		}
 	}()
 
 	return listenForChunk(model.responseCh)
@@ -9,6 +9,7 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"github.com/charmbracelet/log"
 	"github.com/theantichris/ghost/v3/internal/agent"
+	"github.com/theantichris/ghost/v3/internal/llm"
 	"github.com/theantichris/ghost/v3/internal/tool"
 )
 
@@ -16,11 +17,11 @@ func newTestCLIModel(t *testing.T) CLIModel {
 	t.Helper()
 
 	logger := log.New(io.Discard)
-	registry := tool.NewRegistry("", 0, logger)
+	registry := tool.NewRegistry(nil, 0, nil, logger)
 
 	config := ModelConfig{
 		Context:  context.Background(),
-		URL:      "http://localhost/11434/api",
+		Provider: llm.NewOllama("http://localhost/11434/api"),
 		ChatLLM:  "test-model",
 		Prompts:  agent.Prompt{System: "test system prompt"},
 		Registry: registry,
@@ -37,13 +38,14 @@ func newTestCLIModel(t *testing.T) CLIModel {
 
 func TestCLIModel_Update(t *testing.T) {
 	tests := []struct {
-		name        string
-		msg         tea.Msg
-		wantContent string
-		wantDone    bool
-		wantErr     bool
-		wantCmd     bool
-		wantQuit    bool
+		name         string
+		msg          tea.Msg
+		wantContent  string
+		wantDone     bool
+		wantErr      bool
+		wantWarnings int
+		wantCmd      bool
+		wantQuit     bool
 	}{
 		{
 			name:        "StreamChunkMsg accumulates content and returns listen command",
@@ -53,6 +55,31 @@ func TestCLIModel_Update(t *testing.T) {
 			wantCmd:     true,
 			wantQuit:    false,
 		},
+		{
+			name:        "StreamThinkingMsg keeps thinking out of content",
+			msg:         StreamThinkingMsg("hmm "),
+			wantContent: "",
+			wantDone:    false,
+			wantCmd:     true,
+			wantQuit:    false,
+		},
+		{
+			name:        "LLMMetricsMsg stores metrics and returns listen command",
+			msg:         LLMMetricsMsg{Metrics: llm.Metrics{EvalCount: 3}},
+			wantContent: "",
+			wantDone:    false,
+			wantCmd:     true,
+			wantQuit:    false,
+		},
+		{
+			name:         "LLMWarningMsg keeps warning and returns listen command",
+			msg:          LLMWarningMsg{Err: errors.New("test warning")},
+			wantContent:  "",
+			wantDone:     false,
+			wantWarnings: 1,
+			wantCmd:      true,
+			wantQuit:     false,
+		},
 		{
 			name:        "LLMDoneMsg sets done and returns quit",
 			msg:         LLMDoneMsg{},
@@ -87,6 +114,10 @@ func TestCLIModel_Update(t *testing.T) {
 				t.Errorf("done = %v, want %v", got.done, tt.wantDone)
 			}
 
+			if len(got.Warnings()) != tt.wantWarnings {
+				t.Errorf("warnings = %v, want %d", got.Warnings(), tt.wantWarnings)
+			}
+
 			if tt.wantErr && got.Err == nil {
 				t.Error("expected error, got nil")
 			}
@@ -120,3 +151,23 @@ func TestCLIModel_ChunkAccumulation(t *testing.T) {
 		t.Errorf("accumulated content = %q, want %q", model.content, want)
 	}
 }
+
+func TestCLIModel_ToolCallsClearContent(t *testing.T) {
+	model := newTestCLIModel(t)
+
+	msgs := []tea.Msg{
+		StreamChunkMsg("Let me search for that."),
+		ToolCallsMsg{Response: llm.ChatMessage{Role: llm.RoleAssistant, ToolCalls: []llm.ToolCall{{}}}},
+		StreamChunkMsg(`{"answer":42}`),
+	}
+
+	for _, msg := range msgs {
+		newModel, _ := model.Update(msg)
+		model = newModel.(CLIModel)
+	}
+
+	want := `{"answer":42}`
+	if model.content != want {
+		t.Errorf("content = %q, want %q", model.content, want)
+	}
+}
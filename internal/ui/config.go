@@ -5,20 +5,30 @@ import (
 
 	"github.com/charmbracelet/log"
 	"github.com/theantichris/ghost/v3/internal/agent"
+	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/internal/schema"
 	"github.com/theantichris/ghost/v3/internal/storage"
 	"github.com/theantichris/ghost/v3/internal/tool"
 )
 
 // ModelConfig holds the configuration options for the UI models.
 type ModelConfig struct {
-	Context   context.Context
-	Logger    *log.Logger
-	URL       string
-	ChatLLM   string
-	VisionLLM string
-	Format    string
-	Prompts   agent.Prompt
-	Images    []string
-	Registry  tool.Registry
-	Store     *storage.Store
+	Context       context.Context
+	Logger        *log.Logger
+	Provider      llm.Provider
+	ChatLLM       string
+	VisionLLM     string
+	Options       llm.Options
+	Think         *bool // Nil leaves thinking to the model's default
+	HideThinking  bool
+	Format        string
+	Schema        *schema.Schema // Nil if the response isn't constrained to a schema
+	SchemaRetries int
+	Prompts       agent.Prompt
+	Images        []string
+	ContextWindow agent.ContextWindow // How the chat history is fit into the model's context window
+	Registry      tool.Registry
+	AutoApprove   bool // Approves tool calls that ask without prompting, for one-shot mode
+	Store         *storage.Store
+	Memories      *storage.MemoryStore // Memories added to the system prompt at the start of a thread, nil for none
 }
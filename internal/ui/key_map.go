@@ -21,7 +21,15 @@ type keyMap struct {
 	new        key.Binding
 	quit       key.Binding
 	readFile   key.Binding
+	set        key.Binding
+	thinking   key.Binding
+	tools      key.Binding
+	cancel     key.Binding
+	compact    key.Binding
 	threadList key.Binding
+	approve    key.Binding
+	deny       key.Binding
+	always     key.Binding
 }
 
 // matchesCommand is a helper to match the command string to a key.
@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/progress"
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/log"
+	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/style"
+)
+
+const maxProgressWidth = 60
+
+// PullProgressMsg carries a progress update while pulling a model.
+type PullProgressMsg llm.PullProgress
+
+// PullModel renders the progress of pulling a model.
+type PullModel struct {
+	ctx        context.Context
+	logger     *log.Logger
+	puller     llm.ModelPuller
+	name       string // Model being pulled.
+	status     string // Latest status reported by the server.
+	percent    float64
+	progress   progress.Model
+	done       bool  // Whether the pull has finished.
+	Err        error // Error if the pull failed.
+	responseCh chan tea.Msg
+}
+
+// NewPullModel creates and returns PullModel for the model name.
+func NewPullModel(ctx context.Context, logger *log.Logger, puller llm.ModelPuller, name string) PullModel {
+	bar := progress.New(
+		progress.WithColors(style.Accent0, style.Accent1),
+		progress.WithWidth(maxProgressWidth),
+	)
+
+	return PullModel{
+		ctx:        ctx,
+		logger:     logger,
+		puller:     puller,
+		name:       name,
+		status:     "connecting",
+		progress:   bar,
+		responseCh: make(chan tea.Msg),
+	}
+}
+
+// Init starts the pull.
+func (model PullModel) Init() tea.Cmd {
+	return model.startPull()
+}
+
+// Update handles messages and returns the updated model and optional command.
+func (model PullModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		model.progress.SetWidth(min(msg.Width-2, maxProgressWidth))
+
+		return model, nil
+
+	case tea.KeyPressMsg:
+		if key.Matches(msg, quitKeys) {
+			return model, tea.Quit
+		}
+
+	case PullProgressMsg:
+		model.status = msg.Status
+		model.percent = llm.PullProgress(msg).Percent()
+
+		return model, listenForChunk(model.responseCh)
+
+	case LLMDoneMsg:
+		model.done = true
+
+		return model, tea.Quit
+
+	case StreamErrorMsg:
+		model.Err = msg.Err
+		model.done = true
+
+		return model, tea.Quit
+	}
+
+	return model, nil
+}
+
+// View renders the current status and progress of the pull.
+func (model PullModel) View() tea.View {
+	if model.done {
+		return tea.NewView("")
+	}
+
+	status := style.FgAccent0.Render(fmt.Sprintf("%s pulling %s: %s", style.GlyphInfo, model.name, model.status))
+
+	return tea.NewView(status + "\n" + model.progress.ViewAs(model.percent))
+}
+
+func (model PullModel) startPull() tea.Cmd {
+	model.logger.Debug("pulling model", "model", model.name)
+
+	go func() {
+		ch := model.responseCh
+		defer close(ch)
+
+		onProgress := func(progress llm.PullProgress) {
+			ch <- PullProgressMsg(progress)
+		}
+
+		if err := model.puller.PullModel(model.ctx, model.name, onProgress); err != nil {
+			ch <- StreamErrorMsg{Err: err}
+		}
+	}()
+
+	return listenForChunk(model.responseCh)
+}
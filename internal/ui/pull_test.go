@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/log"
+	"github.com/theantichris/ghost/v3/internal/llm"
+)
+
+func TestPullModel_Update(t *testing.T) {
+	tests := []struct {
+		name        string
+		msg         tea.Msg
+		wantStatus  string
+		wantPercent float64
+		wantDone    bool
+		wantErr     bool
+	}{
+		{
+			name:        "progress msg updates status and percent",
+			msg:         PullProgressMsg{Status: "pulling abc", Total: 200, Completed: 50},
+			wantStatus:  "pulling abc",
+			wantPercent: 0.25,
+		},
+		{
+			name:       "done msg finishes pull",
+			msg:        LLMDoneMsg{},
+			wantStatus: "connecting",
+			wantDone:   true,
+		},
+		{
+			name:       "error msg sets error",
+			msg:        StreamErrorMsg{Err: errors.New("test error")},
+			wantStatus: "connecting",
+			wantDone:   true,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := NewPullModel(context.Background(), log.New(io.Discard), llm.NewOllama("http://localhost/11434/api"), "llama3")
+
+			newModel, cmd := model.Update(tt.msg)
+			got := newModel.(PullModel)
+
+			if got.status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", got.status, tt.wantStatus)
+			}
+
+			if got.percent != tt.wantPercent {
+				t.Errorf("percent = %v, want %v", got.percent, tt.wantPercent)
+			}
+
+			if got.done != tt.wantDone {
+				t.Errorf("done = %v, want %v", got.done, tt.wantDone)
+			}
+
+			if (got.Err != nil) != tt.wantErr {
+				t.Errorf("Err = %v, want error %v", got.Err, tt.wantErr)
+			}
+
+			if cmd == nil {
+				t.Error("expected command, got nil")
+			}
+
+			if view := got.View().Content; !tt.wantDone && !strings.Contains(view, "pulling llama3") {
+				t.Errorf("View() = %q, want pull status", view)
+			}
+		})
+	}
+}
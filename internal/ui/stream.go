@@ -1,10 +1,38 @@
 package ui
 
-import tea "charm.land/bubbletea/v2"
+import (
+	tea "charm.land/bubbletea/v2"
+	"github.com/theantichris/ghost/v3/internal/llm"
+)
 
 // LLMResponseMsg carries a chunk of the LLM response.
 type LLMResponseMsg string
 
+// LLMThinkingMsg carries a chunk of the LLM's thinking.
+type LLMThinkingMsg string
+
+// LLMMetricsMsg carries the token usage and timing of the LLM response.
+type LLMMetricsMsg struct {
+	Metrics llm.Metrics
+}
+
+// LLMFinalMsg carries the final message of the tool loop, the answer after any
+// tool calls.
+type LLMFinalMsg struct {
+	Message llm.ChatMessage
+}
+
+// ContextTrimmedMsg carries the message history after it was trimmed to fit
+// the context window.
+type ContextTrimmedMsg struct {
+	Messages []llm.ChatMessage
+}
+
+// LLMWarningMsg carries a warning from the tool loop, the response continues.
+type LLMWarningMsg struct {
+	Err error
+}
+
 // LLMErrorMsg signals an error from the LLM.
 type LLMErrorMsg struct {
 	Err error
@@ -2,6 +2,8 @@ package ui
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"charm.land/bubbles/v2/textarea"
 	"charm.land/bubbles/v2/textinput"
@@ -24,6 +26,7 @@ const (
 	ModeCommand
 	ModeInsert
 	ModeThreadList
+	ModeApproval
 )
 
 const inputHeight = 3
@@ -31,30 +34,49 @@ const statusHeight = 1
 
 // TUIModel holds the TUI state.
 type TUIModel struct {
-	ctx               context.Context
-	prompts           agent.Prompt
-	logger            *log.Logger
-	viewport          viewport.Model
-	userInput         textarea.Model
-	messages          []llm.ChatMessage
-	chatHistory       string // Rendered conversation for display
-	width             int
-	height            int
-	ready             bool // True if the viewport is initialized
-	mode              Mode
-	cmdInput          textinput.Model
-	url               string
-	chatLLM           string
-	visionLLM         string
-	responseCh        chan tea.Msg
-	currentResponse   string // Buffer for the LLM's streaming response
-	awaitingG         bool   // Used for gg command
-	inputHistory      []string
-	inputHistoryIndex int
-	toolRegistry      tool.Registry
-	store             *storage.Store
-	threadID          string // ID of current conversation
-	threadList        ThreadListModel
+	ctx                context.Context
+	prompts            agent.Prompt
+	logger             *log.Logger
+	viewport           viewport.Model
+	userInput          textarea.Model
+	messages           []llm.ChatMessage
+	chatHistory        string // Rendered conversation for display
+	width              int
+	height             int
+	ready              bool // True if the viewport is initialized
+	mode               Mode
+	cmdInput           textinput.Model
+	provider           llm.Provider
+	chatLLM            string
+	visionLLM          string
+	options            llm.Options // Model options sent with each request
+	think              *bool       // Enables thinking for models that support it
+	responseCh         chan tea.Msg
+	currentResponse    string           // Buffer for the LLM's streaming response
+	currentThinking    string           // Buffer for the LLM's streaming thinking
+	finalResponse      *llm.ChatMessage // Final message of the tool loop, nil until it returns
+	thinkingBlocks     []string         // Thinking for each response, rendered at its marker in chatHistory
+	showThinking       bool             // True if thinking blocks are expanded
+	toolBlocks         []toolBlock      // Tool calls and results, rendered at their marker in chatHistory
+	showTools          bool             // True if tool call blocks are expanded
+	hideThinking       bool             // True if thinking blocks aren't rendered at all
+	metrics            *llm.Metrics     // Metrics of the last response, nil until it's reported
+	awaitingG          bool             // Used for gg command
+	inputHistory       []string
+	inputHistoryIndex  int
+	toolRegistry       tool.Registry
+	store              *storage.Store
+	memories           *storage.MemoryStore // Memories added at the start of a thread, nil for none
+	threadID           string               // ID of current conversation
+	threadList         ThreadListModel
+	cancel             context.CancelFunc // Cancels the in-flight request, nil when idle
+	interrupted        bool               // True if the in-flight request was cancelled
+	compactCancel      context.CancelFunc // Cancels the in-flight compaction, nil when idle
+	compactID          int                // Identifies the latest compaction so stale results are dropped
+	contextWindow      agent.ContextWindow
+	pendingApproval    *ToolApprovalMsg // Tool call waiting for approval, nil if none
+	approvalReturnMode Mode             // Mode to return to once the approval is answered
+	allowedTools       map[string]bool  // Tools always allowed for this session
 }
 
 // NewTUIModel creates the chat model and initializes the text input.
@@ -79,12 +101,18 @@ func NewTUIModel(config ModelConfig) TUIModel {
 		cmdInput:          cmdInput,
 		messages:          messages,
 		chatHistory:       "",
-		url:               config.URL,
+		provider:          config.Provider,
 		chatLLM:           config.ChatLLM,
 		visionLLM:         config.VisionLLM,
+		options:           config.Options,
+		think:             config.Think,
+		hideThinking:      config.HideThinking,
 		inputHistoryIndex: 0,
 		toolRegistry:      config.Registry,
 		store:             config.Store,
+		memories:          config.Memories,
+		contextWindow:     config.ContextWindow,
+		allowedTools:      map[string]bool{},
 	}
 
 	return chatModel
@@ -114,17 +142,41 @@ func (model TUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case ModeThreadList:
 			return model.handleThreadListMode(msg)
+
+		case ModeApproval:
+			return model.handleApprovalMode(msg)
 		}
 
 	case LLMResponseMsg:
 		return model.handleLLMResponseMsg(msg)
 
+	case LLMThinkingMsg:
+		return model.handleLLMThinkingMsg(msg)
+
+	case LLMFinalMsg:
+		return model.handleLLMFinalMsg(msg)
+
+	case ContextTrimmedMsg:
+		return model.handleContextTrimmedMsg(msg)
+
 	case LLMDoneMsg:
 		return model.handleLLMDoneMsg()
 
+	case LLMWarningMsg:
+		return model.handleLLMWarningMsg(msg)
+
+	case ToolCallsMsg:
+		return model.handleToolCallsMsg(msg)
+
+	case ToolApprovalMsg:
+		return model.handleToolApprovalMsg(msg)
+
 	case LLMErrorMsg:
 		return model.handleLLMErrorMsg(msg)
 
+	case CompactedMsg:
+		return model.handleCompactedMsg(msg)
+
 	default:
 		// Pass through to inputs
 		var cmd tea.Cmd
@@ -169,6 +221,8 @@ func (model TUIModel) View() tea.View {
 		view = tea.NewView(model.renderTUI("[INS]"))
 	case ModeThreadList:
 		view = model.threadList.View()
+	case ModeApproval:
+		view = tea.NewView(model.renderTUI(approvalStatus))
 	}
 
 	view.AltScreen = true
@@ -180,6 +234,10 @@ func (model TUIModel) renderTUI(statusBar string) string {
 	width := model.width - panelStyle.GetHorizontalFrameSize()
 	panel := panelStyle.Width(width)
 
+	if metrics := model.renderMetrics(); metrics != "" {
+		statusBar += "  " + metrics
+	}
+
 	str := lipgloss.JoinVertical(
 		lipgloss.Center,
 		panel.Render(model.viewport.View()),
@@ -225,7 +283,32 @@ func (model TUIModel) viewportHeight() int {
 	return model.height - inputHeight - statusHeight - panelCount*frameHeight
 }
 
-// renderHistory returns the model history word wrapped to the width of the viewport.
+// renderMetrics returns the generation rate and context used by the last
+// response for the status bar.
+func (model TUIModel) renderMetrics() string {
+	if model.metrics == nil {
+		return ""
+	}
+
+	context := fmt.Sprintf("ctx %d", model.metrics.ContextUsed())
+	if model.options.NumCtx != nil {
+		context += fmt.Sprintf("/%d", *model.options.NumCtx)
+	}
+
+	return style.FgTextMuted.Render(fmt.Sprintf("%.1f tok/s  %s", model.metrics.TokensPerSecond(), context))
+}
+
+// renderHistory returns the model history with thinking and tool call blocks
+// rendered, word wrapped to the width of the viewport.
 func (model TUIModel) renderHistory() string {
-	return lipgloss.NewStyle().Width(model.viewport.Width()).Render(model.chatHistory)
+	history := model.chatHistory
+	for i, thinking := range model.thinkingBlocks {
+		history = strings.Replace(history, fmt.Sprintf(thinkingMarker, i), model.renderThinking(thinking), 1)
+	}
+
+	for i, block := range model.toolBlocks {
+		history = strings.Replace(history, fmt.Sprintf(toolMarker, i), model.renderToolBlock(block), 1)
+	}
+
+	return lipgloss.NewStyle().Width(model.viewport.Width()).Render(history)
 }
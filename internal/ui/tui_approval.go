@@ -0,0 +1,158 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/internal/tool"
+	"github.com/theantichris/ghost/v3/style"
+)
+
+var approvalKeyMap = keyMap{
+	approve: key.NewBinding(
+		key.WithKeys("y"),
+		key.WithHelp("y", "approve"),
+	),
+	deny: key.NewBinding(
+		key.WithKeys("n", "esc"),
+		key.WithHelp("n/esc", "deny"),
+	),
+	always: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "always allow this session"),
+	),
+	cancel: key.NewBinding(
+		key.WithKeys("ctrl+c"),
+		key.WithHelp("ctrl+c", "cancel response"),
+	),
+}
+
+// approvalStatus is shown in the status bar while a tool call waits for
+// approval.
+const approvalStatus = "[APR] y approve  n deny  a always allow"
+
+// ToolApprovalMsg asks the user to approve a tool call.
+// The answer is sent on Reply.
+type ToolApprovalMsg struct {
+	ToolCall llm.ToolCall
+	Reply    chan<- bool
+}
+
+// requestApproval sends a ToolApprovalMsg for toolCall on ch and waits for the
+// answer. Returns false if done closes first.
+func requestApproval(ch chan<- tea.Msg, done <-chan struct{}, toolCall llm.ToolCall) bool {
+	reply := make(chan bool, 1)
+
+	select {
+	case ch <- ToolApprovalMsg{ToolCall: toolCall, Reply: reply}:
+	case <-done:
+		return false
+	}
+
+	select {
+	case approved := <-reply:
+		return approved
+	case <-done:
+		return false
+	}
+}
+
+func (model TUIModel) handleToolApprovalMsg(msg ToolApprovalMsg) (tea.Model, tea.Cmd) {
+	name := msg.ToolCall.Function.Name
+
+	if model.allowedTools[model.approvalKey(msg.ToolCall)] {
+		model.logger.Debug("tool allowed for session", "name", name)
+		msg.Reply <- true
+
+		return model, listenForChunk(model.responseCh)
+	}
+
+	model.logger.Debug("awaiting tool approval", "name", name)
+
+	model.pendingApproval = &msg
+	model.approvalReturnMode = model.mode
+	model.mode = ModeApproval
+
+	model.chatHistory += fmt.Sprintf("\n[%s run %s?]\n%s\n", style.GlyphInfo, name, formatArguments(msg.ToolCall.Function.Arguments))
+	model.viewport.SetContent(model.renderHistory())
+	model.viewport.GotoBottom()
+
+	return model, listenForChunk(model.responseCh)
+}
+
+func (model TUIModel) handleApprovalMode(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, approvalKeyMap.approve):
+		model = model.answerApproval(true)
+
+	case key.Matches(msg, approvalKeyMap.always):
+		if model.allowedTools == nil {
+			model.allowedTools = map[string]bool{}
+		}
+
+		model.allowedTools[model.approvalKey(model.pendingApproval.ToolCall)] = true
+		model = model.answerApproval(true)
+
+	case key.Matches(msg, approvalKeyMap.deny):
+		model = model.answerApproval(false)
+
+	case key.Matches(msg, approvalKeyMap.cancel):
+		model = model.answerApproval(false)
+		model = model.cancelStream()
+	}
+
+	return model, nil
+}
+
+// approvalKey returns the key toolCall is allowed for the session under.
+// Tools that set the policy of each call, like run_command, are allowed per
+// call so allowing one command doesn't allow every other.
+func (model TUIModel) approvalKey(toolCall llm.ToolCall) string {
+	name := toolCall.Function.Name
+
+	if _, ok := model.toolRegistry.Tools[name].(tool.CallPolicer); !ok {
+		return name
+	}
+
+	var args bytes.Buffer
+	if err := json.Compact(&args, toolCall.Function.Arguments); err != nil {
+		return name + " " + string(toolCall.Function.Arguments)
+	}
+
+	return name + " " + args.String()
+}
+
+// answerApproval sends the answer to the pending approval and returns to the
+// mode it interrupted.
+// Denials are shown by the warning the tool loop sends back.
+func (model TUIModel) answerApproval(approved bool) TUIModel {
+	name := model.pendingApproval.ToolCall.Function.Name
+	model.logger.Debug("tool approval answered", "name", name, "approved", approved)
+
+	model.pendingApproval.Reply <- approved
+	model.pendingApproval = nil
+	model.mode = model.approvalReturnMode
+
+	if approved {
+		model.chatHistory += fmt.Sprintf("[%s approved]\n", style.GlyphInfo)
+		model.viewport.SetContent(model.renderHistory())
+		model.viewport.GotoBottom()
+	}
+
+	return model
+}
+
+// formatArguments returns args indented for display, or as is if they aren't
+// valid JSON.
+func formatArguments(args json.RawMessage) string {
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, args, "", "  "); err != nil {
+		return string(args)
+	}
+
+	return indented.String()
+}
@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/internal/tool"
+)
+
+func TestTUIModel_HandleApprovalMode(t *testing.T) {
+	tests := []struct {
+		name         string
+		key          tea.KeyPressMsg
+		want         bool
+		wantAllowed  bool
+		wantCanceled bool
+	}{
+		{
+			name: "y approves the tool call",
+			key:  tea.KeyPressMsg{Code: 'y', Text: "y"},
+			want: true,
+		},
+		{
+			name: "n denies the tool call",
+			key:  tea.KeyPressMsg{Code: 'n', Text: "n"},
+			want: false,
+		},
+		{
+			name:        "a approves and allows the tool for the session",
+			key:         tea.KeyPressMsg{Code: 'a', Text: "a"},
+			want:        true,
+			wantAllowed: true,
+		},
+		{
+			name:         "ctrl+c denies the tool call and cancels the response",
+			key:          tea.KeyPressMsg{Code: 'c', Mod: tea.ModCtrl},
+			want:         false,
+			wantCanceled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := newTestModel(t)
+			model.ready = true
+			model.mode = ModeInsert
+			model.responseCh = make(chan tea.Msg)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			model.cancel = cancel
+
+			var toolCall llm.ToolCall
+			toolCall.Function.Name = "write_file"
+			toolCall.Function.Arguments = json.RawMessage(`{"path":"notes.txt"}`)
+
+			reply := make(chan bool, 1)
+
+			newModel, cmd := model.Update(ToolApprovalMsg{ToolCall: toolCall, Reply: reply})
+			model = newModel.(TUIModel)
+
+			if model.mode != ModeApproval {
+				t.Fatalf("mode = %v, want %v", model.mode, ModeApproval)
+			}
+
+			if cmd == nil {
+				t.Error("expected listen command, got nil")
+			}
+
+			if !strings.Contains(model.chatHistory, "write_file") || !strings.Contains(model.chatHistory, `"path": "notes.txt"`) {
+				t.Errorf("chatHistory = %q, want tool name and indented arguments", model.chatHistory)
+			}
+
+			newModel, _ = model.Update(tt.key)
+			model = newModel.(TUIModel)
+
+			if got := <-reply; got != tt.want {
+				t.Errorf("reply = %v, want %v", got, tt.want)
+			}
+
+			if model.mode != ModeInsert {
+				t.Errorf("mode = %v, want %v", model.mode, ModeInsert)
+			}
+
+			if model.allowedTools["write_file"] != tt.wantAllowed {
+				t.Errorf("allowedTools[write_file] = %v, want %v", model.allowedTools["write_file"], tt.wantAllowed)
+			}
+
+			if (ctx.Err() != nil) != tt.wantCanceled {
+				t.Errorf("canceled = %v, want %v", ctx.Err() != nil, tt.wantCanceled)
+			}
+		})
+	}
+}
+
+func TestTUIModel_ToolApprovalAllowedForSession(t *testing.T) {
+	model := newTestModel(t)
+	model.ready = true
+	model.responseCh = make(chan tea.Msg)
+	model.allowedTools["write_file"] = true
+
+	var toolCall llm.ToolCall
+	toolCall.Function.Name = "write_file"
+
+	reply := make(chan bool, 1)
+
+	newModel, _ := model.Update(ToolApprovalMsg{ToolCall: toolCall, Reply: reply})
+	model = newModel.(TUIModel)
+
+	if got := <-reply; !got {
+		t.Error("reply = false, want true")
+	}
+
+	if model.mode == ModeApproval {
+		t.Error("mode = ModeApproval, want no prompt for an allowed tool")
+	}
+}
+
+func TestTUIModel_ApprovalKey(t *testing.T) {
+	model := newTestModel(t)
+	model.toolRegistry.Register(tool.RunCommand{})
+
+	tests := []struct {
+		name string
+		tool string
+		args string
+		want string
+	}{
+		{
+			name: "keys tools by name",
+			tool: "write_file",
+			args: `{"path":"notes.txt"}`,
+			want: "write_file",
+		},
+		{
+			name: "keys run_command by the compacted command",
+			tool: "run_command",
+			args: `{"command": "go test ./..."}`,
+			want: `run_command {"command":"go test ./..."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var toolCall llm.ToolCall
+			toolCall.Function.Name = tt.tool
+			toolCall.Function.Arguments = json.RawMessage(tt.args)
+
+			if got := model.approvalKey(toolCall); got != tt.want {
+				t.Errorf("approvalKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
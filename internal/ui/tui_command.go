@@ -1,12 +1,14 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
 	"github.com/theantichris/ghost/v3/internal/agent"
+	"github.com/theantichris/ghost/v3/internal/file"
 	"github.com/theantichris/ghost/v3/internal/llm"
 	"github.com/theantichris/ghost/v3/style"
 )
@@ -28,10 +30,18 @@ var commandKeyMap = keyMap{
 		key.WithKeys("r"),
 		key.WithHelp("r", "read file"),
 	),
+	set: key.NewBinding(
+		key.WithKeys("set"),
+		key.WithHelp("set <option> <value>", "set model option"),
+	),
 	threadList: key.NewBinding(
 		key.WithKeys("t"),
 		key.WithHelp("t", "open thread list"),
 	),
+	compact: key.NewBinding(
+		key.WithKeys("compact"),
+		key.WithHelp("compact", "summarize older messages"),
+	),
 	esc: key.NewBinding(
 		key.WithKeys("esc"),
 		key.WithHelp("esc", "normal mode"),
@@ -60,8 +70,14 @@ func (model TUIModel) handleCommandMode(msg tea.KeyPressMsg) (tea.Model, tea.Cmd
 		case matchesCommand(cmd, commandKeyMap.readFile):
 			return model.readFile(arg)
 
+		case matchesCommand(cmd, commandKeyMap.set):
+			return model.setOption(arg)
+
 		case matchesCommand(cmd, commandKeyMap.threadList):
 			return model.createThreadList()
+
+		case matchesCommand(cmd, commandKeyMap.compact):
+			return model.compact()
 		}
 
 		// Resets mode for invalid commands.
@@ -111,7 +127,7 @@ func (model TUIModel) readFile(arg string) (tea.Model, tea.Cmd) {
 		return model, nil
 	}
 
-	fileType, err := agent.DetectFileType(arg)
+	fileType, err := file.DetectType(arg)
 	if err != nil {
 		model.logger.Error("failed to validate file", "error", err.Error(), "path", arg)
 		model.chatHistory += fmt.Sprintf("\n[%s error: %s]\n", style.GlyphError, err.Error())
@@ -123,16 +139,16 @@ func (model TUIModel) readFile(arg string) (tea.Model, tea.Cmd) {
 	}
 
 	switch fileType {
-	case agent.FileTypeDir:
+	case file.TypeDir:
 		model.chatHistory += fmt.Sprintf("\n[%s error: file is directory]\n", style.GlyphError)
 		model.viewport.SetContent(model.renderHistory())
 		model.mode = ModeNormal
 		model.cmdInput.Reset()
 
-	case agent.FileTypeImage:
+	case file.TypeImage:
 		return model.analyzeImage(arg)
 
-	case agent.FileTypeText:
+	case file.TypeText:
 		return model.readTextFile(arg)
 	}
 
@@ -140,7 +156,7 @@ func (model TUIModel) readFile(arg string) (tea.Model, tea.Cmd) {
 }
 
 func (model TUIModel) analyzeImage(path string) (tea.Model, tea.Cmd) {
-	content, err := agent.AnalyseImages(model.ctx, model.url, model.visionLLM, model.prompts, []string{path}, model.logger)
+	content, err := agent.AnalyseImages(model.ctx, model.provider, model.visionLLM, model.options, model.prompts, []string{path}, model.logger)
 	if err != nil {
 		model.logger.Error("image read failed", "path", path, "error", err)
 		model.chatHistory += fmt.Sprintf("\n[%s error: %s]\n", style.GlyphError, err.Error())
@@ -164,7 +180,7 @@ func (model TUIModel) analyzeImage(path string) (tea.Model, tea.Cmd) {
 }
 
 func (model TUIModel) readTextFile(path string) (tea.Model, tea.Cmd) {
-	content, err := agent.ReadTextFile(path)
+	content, err := file.ReadText(path)
 	if err != nil {
 		model.logger.Error("file read failed", "path", path, "error", err)
 		model.chatHistory += fmt.Sprintf("\n[%s error: %s]\n", style.GlyphError, err.Error())
@@ -186,9 +202,143 @@ func (model TUIModel) readTextFile(path string) (tea.Model, tea.Cmd) {
 	return model, nil
 }
 
+// setOption sets a model option from an "<option> <value>" argument.
+// Without an argument the current options are shown, an option without a value
+// is reset to the model's default.
+func (model TUIModel) setOption(arg string) (tea.Model, tea.Cmd) {
+	if arg != "" {
+		parts := strings.SplitN(arg, " ", 2)
+
+		var value string
+		if len(parts) > 1 {
+			value = parts[1]
+		}
+
+		if err := model.options.Set(parts[0], value); err != nil {
+			model.logger.Error("failed to set option", "option", parts[0], "value", value, "error", err)
+			model.chatHistory += fmt.Sprintf("\n[%s error: %s]\n", style.GlyphError, err.Error())
+			model.viewport.SetContent(model.renderHistory())
+			model.mode = ModeNormal
+			model.cmdInput.Reset()
+
+			return model, nil
+		}
+
+		model.logger.Info("model option set", "option", parts[0], "value", value)
+	}
+
+	model.chatHistory += fmt.Sprintf("\n[%s options: %s]\n", style.GlyphInfo, model.options)
+	model.viewport.SetContent(model.renderHistory())
+	model.mode = ModeNormal
+	model.cmdInput.Reset()
+
+	return model, nil
+}
+
+// CompactedMsg carries the message history after compacting, or the error
+// that stopped it.
+type CompactedMsg struct {
+	ID        int // compactID of the compaction, results of earlier ones are dropped.
+	Messages  []llm.ChatMessage
+	Compacted int // Number of messages in the history that was compacted.
+	Err       error
+}
+
+// compact replaces all but the latest turn of the message history with a
+// summary.
+// The summary is requested in a command with a context that's cancelled by
+// cancelCompact, the result is applied in handleCompactedMsg.
+// Refused while a response is streaming or another compaction is running.
+func (model TUIModel) compact() (tea.Model, tea.Cmd) {
+	model.mode = ModeNormal
+	model.cmdInput.Reset()
+
+	if model.cancel != nil || model.compactCancel != nil {
+		model.chatHistory += fmt.Sprintf("\n[%s error: wait for the response to finish before compacting]\n", style.GlyphError)
+		model.viewport.SetContent(model.renderHistory())
+		model.viewport.GotoBottom()
+
+		return model, nil
+	}
+
+	ctx, cancel := context.WithCancel(model.ctx)
+	model.compactCancel = cancel
+	model.compactID++
+
+	model.chatHistory += fmt.Sprintf("\n[%s compacting]\n", style.GlyphInfo)
+	model.viewport.SetContent(model.renderHistory())
+	model.viewport.GotoBottom()
+
+	id := model.compactID
+	history := model.messages
+
+	return model, func() tea.Msg {
+		messages, err := agent.CompactContext(ctx, model.provider, model.chatLLM, model.prompts, history, 1, model.logger)
+
+		return CompactedMsg{ID: id, Messages: messages, Compacted: len(history), Err: err}
+	}
+}
+
+// handleCompactedMsg replaces the compacted messages with the summary.
+// Messages added while compacting are kept after it. Results of cancelled
+// compactions are dropped.
+func (model TUIModel) handleCompactedMsg(msg CompactedMsg) (tea.Model, tea.Cmd) {
+	if msg.ID != model.compactID || model.compactCancel == nil {
+		model.logger.Debug("dropping stale compaction", "id", msg.ID, "current", model.compactID)
+
+		return model, nil
+	}
+
+	model.compactCancel()
+	model.compactCancel = nil
+
+	switch {
+	case msg.Err != nil:
+		model.logger.Error("compact failed", "error", msg.Err)
+		model.chatHistory += fmt.Sprintf("[%s error: %s]\n", style.GlyphError, msg.Err.Error())
+
+	case len(msg.Messages) == msg.Compacted:
+		model.chatHistory += fmt.Sprintf("[%s nothing to compact]\n", style.GlyphInfo)
+
+	case msg.Compacted > len(model.messages):
+		model.logger.Warn("history changed while compacting, dropping summary", "compacted", msg.Compacted, "messages", len(model.messages))
+		model.chatHistory += fmt.Sprintf("[%s history changed while compacting, nothing compacted]\n", style.GlyphInfo)
+
+	default:
+		model.logger.Info("history compacted", "before", msg.Compacted, "after", len(msg.Messages))
+		model.chatHistory += fmt.Sprintf("[%s compacted %d messages into a summary]\n", style.GlyphInfo, msg.Compacted-len(msg.Messages)+1)
+		model.messages = append(msg.Messages, model.messages[msg.Compacted:]...)
+	}
+
+	model.viewport.SetContent(model.renderHistory())
+	model.viewport.GotoBottom()
+
+	return model, nil
+}
+
+// cancelCompact cancels the in-flight compaction, its result is dropped when
+// it arrives.
+func (model TUIModel) cancelCompact() TUIModel {
+	if model.compactCancel == nil {
+		return model
+	}
+
+	model.logger.Debug("interrupting compaction", "id", model.compactID)
+
+	model.compactCancel()
+	model.compactCancel = nil
+	model.compactID++
+
+	return model
+}
+
 func (model TUIModel) newChat() (tea.Model, tea.Cmd) {
+	model = model.cancelCompact()
 	model.messages = []llm.ChatMessage{{Role: llm.RoleSystem, Content: model.prompts.System}}
 	model.chatHistory = ""
+	model.thinkingBlocks = nil
+	model.toolBlocks = nil
+	model.metrics = nil
 	model.threadID = ""
 	model.viewport.SetContent("")
 	model.cmdInput.Reset()
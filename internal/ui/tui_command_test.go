@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -70,6 +72,16 @@ func TestTUIModel_HandleCommandMode(t *testing.T) {
 			wantQuit:       false,
 			wantCmd:        true, // textinput returns cursor blink command
 		},
+		{
+			name:                 "compact starts compacting in a command",
+			inputValue:           "compact",
+			msg:                  tea.KeyPressMsg{Code: tea.KeyEnter},
+			wantMode:             ModeNormal,
+			wantInputValue:       "",
+			wantCmd:              true,
+			wantChatHistoryMatch: fmt.Sprintf("[%s compacting]", style.GlyphInfo),
+			wantMessageCount:     1,
+		},
 		{
 			name:                 "r without path shows error",
 			inputValue:           "r",
@@ -169,6 +181,30 @@ func TestTUIModel_HandleCommandMode(t *testing.T) {
 			wantMessageCount: 1,
 			wantLastRole:     llm.RoleSystem,
 		},
+		{
+			name:                 "set without argument shows options",
+			inputValue:           "set",
+			msg:                  tea.KeyPressMsg{Code: tea.KeyEnter},
+			wantMode:             ModeNormal,
+			wantInputValue:       "",
+			wantChatHistoryMatch: fmt.Sprintf("[%s options: defaults]", style.GlyphInfo),
+		},
+		{
+			name:                 "set option updates options",
+			inputValue:           "set temperature 0.2",
+			msg:                  tea.KeyPressMsg{Code: tea.KeyEnter},
+			wantMode:             ModeNormal,
+			wantInputValue:       "",
+			wantChatHistoryMatch: fmt.Sprintf("[%s options: temperature=0.2]", style.GlyphInfo),
+		},
+		{
+			name:                 "set invalid option shows error",
+			inputValue:           "set temperature hot",
+			msg:                  tea.KeyPressMsg{Code: tea.KeyEnter},
+			wantMode:             ModeNormal,
+			wantInputValue:       "",
+			wantChatHistoryMatch: fmt.Sprintf("[%s error: %s", style.GlyphError, llm.ErrInvalidOption),
+		},
 		{
 			name:           "t command switches to thread list mode",
 			inputValue:     "t",
@@ -233,6 +269,147 @@ func TestTUIModel_HandleCommandMode(t *testing.T) {
 	}
 }
 
+func TestTUIModel_Compact(t *testing.T) {
+	summary := llm.ChatMessage{Role: llm.RoleUser, Content: "summary"}
+	latest := llm.ChatMessage{Role: llm.RoleUser, Content: "latest"}
+
+	tests := []struct {
+		name             string
+		msg              func(model TUIModel) CompactedMsg // nil runs the compact command
+		before           func(model TUIModel) TUIModel     // Runs before the result arrives.
+		wantChatHistory  string
+		wantMessageCount int
+	}{
+		{
+			name:             "reports nothing to compact",
+			wantChatHistory:  fmt.Sprintf("[%s nothing to compact]", style.GlyphInfo),
+			wantMessageCount: 1,
+		},
+		{
+			name: "replaces compacted messages and keeps later ones",
+			msg: func(model TUIModel) CompactedMsg {
+				return CompactedMsg{ID: model.compactID, Messages: []llm.ChatMessage{model.messages[0], summary}, Compacted: 4}
+			},
+			wantChatHistory:  fmt.Sprintf("[%s compacted 3 messages into a summary]", style.GlyphInfo),
+			wantMessageCount: 3,
+		},
+		{
+			name: "reports error",
+			msg: func(model TUIModel) CompactedMsg {
+				return CompactedMsg{ID: model.compactID, Compacted: 4, Err: errors.New("test error")}
+			},
+			wantChatHistory:  fmt.Sprintf("[%s error: test error]", style.GlyphError),
+			wantMessageCount: 5,
+		},
+		{
+			name: "ctrl+c interrupts compacting",
+			msg: func(model TUIModel) CompactedMsg {
+				return CompactedMsg{ID: model.compactID, Compacted: 4, Err: context.Canceled}
+			},
+			before: func(model TUIModel) TUIModel {
+				newModel, _ := model.Update(tea.KeyPressMsg{Code: 'c', Mod: tea.ModCtrl})
+
+				return newModel.(TUIModel)
+			},
+			wantChatHistory:  fmt.Sprintf("[%s compact interrupted]", style.GlyphInfo),
+			wantMessageCount: 5,
+		},
+		{
+			name: "drops the summary of the thread left for a new chat",
+			msg: func(model TUIModel) CompactedMsg {
+				return CompactedMsg{ID: model.compactID, Messages: []llm.ChatMessage{model.messages[0], summary}, Compacted: 4}
+			},
+			before: func(model TUIModel) TUIModel {
+				newModel, _ := model.newChat()
+
+				return newModel.(TUIModel)
+			},
+			wantMessageCount: 1,
+		},
+		{
+			name: "drops the summary when the history shrank",
+			msg: func(model TUIModel) CompactedMsg {
+				return CompactedMsg{ID: model.compactID, Messages: []llm.ChatMessage{model.messages[0], summary}, Compacted: 4}
+			},
+			before: func(model TUIModel) TUIModel {
+				model.messages = model.messages[:2]
+
+				return model
+			},
+			wantChatHistory:  fmt.Sprintf("[%s history changed while compacting, nothing compacted]", style.GlyphInfo),
+			wantMessageCount: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := newTestModel(t)
+			model.ready = true
+			model.mode = ModeCommand
+			model.cmdInput.SetValue("compact")
+
+			newModel, cmd := model.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+			model = newModel.(TUIModel)
+
+			if cmd == nil || model.compactCancel == nil {
+				t.Fatal("compact didn't start a cancellable command")
+			}
+
+			var msg CompactedMsg
+			if tt.msg == nil {
+				msg = cmd().(CompactedMsg)
+			} else {
+				msg = tt.msg(model)
+				model.messages = append(model.messages, summary, summary, summary, latest)
+			}
+
+			if tt.before != nil {
+				model = tt.before(model)
+			}
+
+			newModel, _ = model.Update(msg)
+			model = newModel.(TUIModel)
+
+			if !strings.Contains(model.chatHistory, tt.wantChatHistory) {
+				t.Errorf("chatHistory = %q, want to contain %q", model.chatHistory, tt.wantChatHistory)
+			}
+
+			if len(model.messages) != tt.wantMessageCount {
+				t.Errorf("messages count = %d, want %d", len(model.messages), tt.wantMessageCount)
+			}
+
+			if model.compactCancel != nil {
+				t.Error("compact didn't release its context")
+			}
+		})
+	}
+}
+
+func TestTUIModel_CompactWhileStreaming(t *testing.T) {
+	model := newTestModel(t)
+	model.ready = true
+	model.mode = ModeCommand
+	model.cmdInput.SetValue("compact")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	model.cancel = cancel
+
+	newModel, cmd := model.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	model = newModel.(TUIModel)
+
+	if cmd != nil || model.compactCancel != nil {
+		t.Error("compact started while a response is streaming")
+	}
+
+	if ctx.Err() != nil {
+		t.Error("compact cancelled the streaming response")
+	}
+
+	if !strings.Contains(model.chatHistory, "wait for the response to finish") {
+		t.Errorf("chatHistory = %q, want compact refused", model.chatHistory)
+	}
+}
+
 func TestTUIModel_NewChat(t *testing.T) {
 	model := newTestModel(t)
 	model.threadID = "old-thread-id"
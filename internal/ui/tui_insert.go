@@ -30,6 +30,10 @@ var insertKeyMap = keyMap{
 		key.WithKeys("enter"),
 		key.WithHelp("enter", "send message"),
 	),
+	cancel: key.NewBinding(
+		key.WithKeys("ctrl+c"),
+		key.WithHelp("ctrl+c", "cancel response"),
+	),
 }
 
 func (model TUIModel) handleInsertMode(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
@@ -40,6 +44,9 @@ func (model TUIModel) handleInsertMode(msg tea.KeyPressMsg) (tea.Model, tea.Cmd)
 		model.mode = ModeNormal
 		model.userInput.Blur()
 
+	case key.Matches(msg, insertKeyMap.cancel):
+		model = model.cancelStream()
+
 	case key.Matches(msg, insertKeyMap.newline):
 		value := model.userInput.Value() + "\n"
 		model.userInput.SetValue(value)
@@ -84,13 +91,17 @@ func (model TUIModel) handleInsertMode(msg tea.KeyPressMsg) (tea.Model, tea.Cmd)
 		model.inputHistoryIndex = len(model.inputHistory)
 
 		model.userInput.SetValue("")
+		model = model.addMemories(value)
 		userMsg := llm.ChatMessage{Role: llm.RoleUser, Content: value}
 		model.messages = append(model.messages, userMsg)
 		model = model.saveMessage(userMsg)
+		model.metrics = nil
 		model.chatHistory += fmt.Sprintf("You: %s\n\nghost: ", value)
 		model.viewport.SetContent(model.renderHistory())
 
-		return model, model.startLLMStream()
+		cmd := model.startLLMStream()
+
+		return model, cmd
 
 	default:
 		model.userInput, cmd = model.userInput.Update(msg)
@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"slices"
+
+	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/internal/tool"
+)
+
+// maxThreadMemories is the number of memories added at the start of a thread.
+const maxThreadMemories = 5
+
+// memoriesPrompt introduces the memories added to the system prompt.
+const memoriesPrompt = "Memories saved in earlier conversations that may be relevant, use them when they help:\n"
+
+// addMemories adds the memories relevant to content to the system prompt when
+// it starts a thread, and saves them with the thread so they're kept when it's
+// loaded.
+func (model TUIModel) addMemories(content string) TUIModel {
+	if model.memories == nil || slices.ContainsFunc(model.messages, func(message llm.ChatMessage) bool { return message.Role == llm.RoleUser }) {
+		return model
+	}
+
+	memories, err := model.memories.Search(content, maxThreadMemories)
+	if err != nil {
+		model.logger.Error("failed to search memories", "error", err)
+
+		return model
+	}
+
+	if len(memories) == 0 {
+		return model
+	}
+
+	model.logger.Debug("memories added to system prompt", "count", len(memories))
+
+	// The thread is titled after content rather than the memories.
+	if model.threadID == "" {
+		thread, err := model.createThread(content)
+		if err != nil {
+			return model
+		}
+
+		model.threadID = thread.ID
+	}
+
+	memoriesMsg := llm.ChatMessage{Role: llm.RoleSystem, Content: memoriesPrompt + tool.FormatMemories(memories)}
+	model.messages = append(model.messages, memoriesMsg)
+	model = model.saveMessage(memoriesMsg)
+
+	return model
+}
@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/internal/storage"
+)
+
+func TestTUIModel_AddMemories(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		history     []llm.ChatMessage
+		wantMessage string // Content of the memories message, empty for none.
+	}{
+		{
+			name:        "adds relevant memories at the start of a thread",
+			content:     "scaffold a new CLI",
+			wantMessage: "Prefer cobra and viper for CLI tools",
+		},
+		{
+			name:    "skips memories that aren't relevant",
+			content: "tell me a joke",
+		},
+		{
+			name:    "skips memories after the start of a thread",
+			content: "scaffold a new CLI",
+			history: []llm.ChatMessage{{Role: llm.RoleUser, Content: "hello"}, {Role: llm.RoleAssistant, Content: "hi"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := newTestModel(t)
+			model.memories = storage.NewMemoryStore(t.TempDir())
+			model.messages = append(model.messages, tt.history...)
+
+			for _, content := range []string{"Prefer cobra and viper for CLI tools", "Deploys go through Argo CD"} {
+				if _, err := model.memories.Add(content); err != nil {
+					t.Fatalf("Add() err = %v, want nil", err)
+				}
+			}
+
+			before := len(model.messages)
+			model = model.addMemories(tt.content)
+
+			if tt.wantMessage == "" {
+				if len(model.messages) != before {
+					t.Errorf("addMemories() messages = %+v, want none added", model.messages)
+				}
+
+				return
+			}
+
+			if len(model.messages) != before+1 {
+				t.Fatalf("addMemories() messages = %d, want %d", len(model.messages), before+1)
+			}
+
+			added := model.messages[len(model.messages)-1]
+			if added.Role != llm.RoleSystem || !strings.Contains(added.Content, tt.wantMessage) || strings.Contains(added.Content, "Argo") {
+				t.Errorf("addMemories() message = %+v, want system message with %q only", added, tt.wantMessage)
+			}
+
+			saved, err := model.store.GetMessages(model.threadID)
+			if err != nil || len(saved) != 1 || saved[0].Content != added.Content {
+				t.Errorf("GetMessages() = %+v, %v, want the memories message saved", saved, err)
+			}
+
+			threads, err := model.store.ListThreads()
+			if err != nil || len(threads) != 1 || threads[0].Title != tt.content {
+				t.Errorf("ListThreads() = %+v, %v, want a thread titled %q", threads, err, tt.content)
+			}
+		})
+	}
+}
@@ -39,6 +39,18 @@ var normalKeyMap = keyMap{
 		key.WithKeys("G"),
 		key.WithHelp("G", "go to bottom"),
 	),
+	thinking: key.NewBinding(
+		key.WithKeys("z"),
+		key.WithHelp("z", "toggle thinking"),
+	),
+	tools: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "toggle tool calls"),
+	),
+	cancel: key.NewBinding(
+		key.WithKeys("ctrl+c"),
+		key.WithHelp("ctrl+c", "cancel response"),
+	),
 }
 
 func (model TUIModel) handleNormalMode(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
@@ -80,6 +92,15 @@ func (model TUIModel) handleNormalMode(msg tea.KeyPressMsg) (tea.Model, tea.Cmd)
 
 	case key.Matches(msg, normalKeyMap.goToBottom):
 		model.viewport.GotoBottom()
+
+	case key.Matches(msg, normalKeyMap.thinking):
+		model = model.toggleThinking()
+
+	case key.Matches(msg, normalKeyMap.tools):
+		model = model.toggleTools()
+
+	case key.Matches(msg, normalKeyMap.cancel):
+		model = model.cancelStream()
 	}
 
 	return model, nil
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/theantichris/ghost/v3/internal/llm"
@@ -18,7 +19,19 @@ func (model TUIModel) saveMessage(chatMsg llm.ChatMessage) TUIModel {
 		model.threadID = thread.ID
 	}
 
-	_, err := model.store.AddMessage(model.threadID, chatMsg)
+	var metadata storage.Metadata
+	if chatMsg.Role == llm.RoleAssistant {
+		options := model.options
+		metadata.Options = &options
+		metadata.Metrics = chatMsg.Metrics
+		metadata.Interrupted = model.interrupted
+	}
+
+	if chatMsg.Role == llm.RoleTool {
+		metadata.Duration = chatMsg.Duration
+	}
+
+	_, err := model.store.AddMessage(model.threadID, chatMsg, metadata)
 	if err != nil {
 		model.logger.Error("failed to add message to thread", "thread_id", model.threadID, "error", err)
 	}
@@ -35,36 +48,102 @@ func (model TUIModel) loadThread(threadID string) (TUIModel, error) {
 
 	var chatMessages []llm.ChatMessage
 	var chatHistory strings.Builder
+	var thinkingBlocks []string
+	var toolBlocks []toolBlock
+	var pendingCalls []llm.ToolCall // Calls of the last response waiting for their results
+	afterTools := false             // True if the next response continues after tool calls
 	for _, message := range messages {
 		chatMessage := llm.ChatMessage{
-			Role:      message.Role,
-			Content:   message.Content,
-			Images:    message.Images,
-			ToolCalls: message.ToolCalls,
+			Role:       message.Role,
+			Content:    message.Content,
+			Thinking:   message.Thinking,
+			Images:     message.Images,
+			ToolCalls:  message.ToolCalls,
+			ToolName:   message.ToolName,
+			ToolCallID: message.ToolCallID,
+			Duration:   message.Duration,
 		}
 
 		chatMessages = append(chatMessages, chatMessage)
 
-		if message.Role == llm.RoleSystem || message.Role == llm.RoleTool {
+		if message.Role == llm.RoleSystem {
+			continue
+		}
+
+		if message.Role == llm.RoleTool {
+			var call llm.ToolCall
+			call, pendingCalls = takeToolCall(pendingCalls, message)
+
+			chatHistory.WriteString(fmt.Sprintf(toolMarker, len(toolBlocks)))
+			toolBlocks = append(toolBlocks, toolBlock{call: call, result: message.Content, duration: message.Duration})
+			afterTools = true
+
 			continue
 		}
 
-		label := "You"
+		label := "You: "
 		if message.Role == llm.RoleAssistant {
-			label = "ghost"
+			label = "ghost: "
+
+			if afterTools {
+				label = ""
+			}
 		}
 
-		history := fmt.Sprintf("%s: %s \n\n", label, message.Content)
+		afterTools = false
+
+		thinking := ""
+		if message.Thinking != "" {
+			thinking = fmt.Sprintf(thinkingMarker, len(thinkingBlocks))
+			thinkingBlocks = append(thinkingBlocks, message.Thinking)
+		}
+
+		content := message.Content
+		if message.Interrupted {
+			content += " " + interruptedNote
+		}
+
+		if len(message.ToolCalls) > 0 {
+			pendingCalls = message.ToolCalls
+			chatHistory.WriteString(fmt.Sprintf("%s%s%s\n", label, thinking, content))
+
+			continue
+		}
+
+		history := fmt.Sprintf("%s%s%s \n\n", label, thinking, content)
 		chatHistory.WriteString(history)
 	}
 
 	model.threadID = threadID
 	model.messages = chatMessages
 	model.chatHistory = chatHistory.String()
+	model.thinkingBlocks = thinkingBlocks
+	model.toolBlocks = toolBlocks
 
 	return model, nil
 }
 
+// takeToolCall returns the call result answers and the calls left. Results
+// are matched by call ID, those without one by the order of the calls.
+func takeToolCall(calls []llm.ToolCall, result storage.Message) (llm.ToolCall, []llm.ToolCall) {
+	index := slices.IndexFunc(calls, func(call llm.ToolCall) bool {
+		return result.ToolCallID != "" && call.ID == result.ToolCallID
+	})
+
+	if index < 0 {
+		if result.ToolCallID != "" || len(calls) == 0 {
+			var call llm.ToolCall
+			call.Function.Name = result.ToolName
+
+			return call, calls
+		}
+
+		index = 0
+	}
+
+	return calls[index], slices.Delete(slices.Clone(calls), index, index+1)
+}
+
 func (model TUIModel) createThread(content string) (*storage.Thread, error) {
 	words := strings.Fields(content)
 	title := ""
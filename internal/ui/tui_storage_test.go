@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/internal/storage"
 )
 
 func TestTUIModel_SaveMessage(t *testing.T) {
@@ -75,6 +76,46 @@ func TestTUIModel_SaveMessage(t *testing.T) {
 	}
 }
 
+func TestTUIModel_SaveMessageMetadata(t *testing.T) {
+	model := newTestModel(t)
+
+	if err := model.options.Set("seed", "42"); err != nil {
+		t.Fatalf("Set() err = %v", err)
+	}
+
+	// The last response's metrics don't belong to an interrupted response.
+	model.metrics = &llm.Metrics{PromptEvalCount: 8, EvalCount: 5}
+
+	model = model.saveMessage(llm.ChatMessage{Role: llm.RoleUser, Content: "hello ghost"})
+	model = model.saveMessage(llm.ChatMessage{Role: llm.RoleAssistant, Content: "greetings runner", Metrics: &llm.Metrics{PromptEvalCount: 12, EvalCount: 3}})
+	model = model.saveMessage(llm.ChatMessage{Role: llm.RoleAssistant, Content: "greet"})
+
+	messages, err := model.store.GetMessages(model.threadID)
+	if err != nil {
+		t.Fatalf("saveMessage() GetMessages() returned error: %v", err)
+	}
+
+	if messages[0].Options != nil {
+		t.Errorf("saveMessage() user message options = %v, want nil", messages[0].Options)
+	}
+
+	if messages[1].Options == nil || messages[1].Options.Seed == nil || *messages[1].Options.Seed != 42 {
+		t.Errorf("saveMessage() assistant message options = %v, want seed=42", messages[1].Options)
+	}
+
+	if messages[0].Metrics != nil {
+		t.Errorf("saveMessage() user message metrics = %v, want nil", messages[0].Metrics)
+	}
+
+	if messages[1].Metrics == nil || messages[1].Metrics.EvalCount != 3 {
+		t.Errorf("saveMessage() assistant message metrics = %v, want eval_count=3", messages[1].Metrics)
+	}
+
+	if messages[2].Metrics != nil {
+		t.Errorf("saveMessage() interrupted message metrics = %v, want nil", messages[2].Metrics)
+	}
+}
+
 func TestTUIModel_CreateThread(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -166,7 +207,7 @@ func TestTUIModel_LoadThread(t *testing.T) {
 				threadID = thread.ID
 
 				for _, msg := range tt.seedMessages {
-					_, err := model.store.AddMessage(threadID, msg)
+					_, err := model.store.AddMessage(threadID, msg, storage.Metadata{})
 					if err != nil {
 						t.Fatalf("failed to add message: %v", err)
 					}
@@ -209,3 +250,57 @@ func TestTUIModel_LoadThread(t *testing.T) {
 		})
 	}
 }
+
+func TestTakeToolCall(t *testing.T) {
+	var search, fetch llm.ToolCall
+	search.ID = "call_search"
+	search.Function.Name = "web_search"
+	fetch.ID = "call_fetch"
+	fetch.Function.Name = "fetch_url"
+
+	tests := []struct {
+		name     string
+		result   storage.Message
+		wantName string
+		wantLeft int
+	}{
+		{
+			name:     "matches result by call ID",
+			result:   storage.Message{Role: llm.RoleTool, ToolName: "fetch_url", ToolCallID: "call_fetch"},
+			wantName: "fetch_url",
+			wantLeft: 1,
+		},
+		{
+			name:     "matches result without ID by order",
+			result:   storage.Message{Role: llm.RoleTool},
+			wantName: "web_search",
+			wantLeft: 1,
+		},
+		{
+			name:     "names unmatched result after its tool",
+			result:   storage.Message{Role: llm.RoleTool, ToolName: "read_file", ToolCallID: "call_read"},
+			wantName: "read_file",
+			wantLeft: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := []llm.ToolCall{search, fetch}
+
+			call, left := takeToolCall(calls, tt.result)
+
+			if call.Function.Name != tt.wantName {
+				t.Errorf("takeToolCall() name = %q, want %q", call.Function.Name, tt.wantName)
+			}
+
+			if len(left) != tt.wantLeft {
+				t.Errorf("takeToolCall() calls left = %d, want %d", len(left), tt.wantLeft)
+			}
+
+			if calls[0].ID != "call_search" || calls[1].ID != "call_fetch" {
+				t.Errorf("takeToolCall() modified calls = %+v", calls)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 
 	tea "charm.land/bubbletea/v2"
@@ -9,40 +10,75 @@ import (
 	"github.com/theantichris/ghost/v3/style"
 )
 
-// startLLMStream starts the LLM call in a go routine.
+// interruptedNote marks a response that was cancelled before it finished.
+var interruptedNote = fmt.Sprintf("[%s interrupted]", style.GlyphInfo)
+
+// startLLMStream starts the LLM call in a go routine with a context that's
+// cancelled by cancelStream.
 // It returns the first listenForChunk command to start receiving.
 func (model *TUIModel) startLLMStream() tea.Cmd {
 	model.logger.Debug("transmitting to neural network", "model", model.chatLLM, "messages", len(model.messages))
 
+	ctx, cancel := context.WithCancel(model.ctx)
+	model.cancel = cancel
 	model.responseCh = make(chan tea.Msg)
 
+	window := model.contextWindow
+	if model.options.NumCtx != nil {
+		window.Limit = *model.options.NumCtx
+	}
+
 	go func() {
 		ch := model.responseCh
 		defer close(ch)
 
-		messages, err := agent.RunToolLoop(model.ctx, model.toolRegistry, model.url, model.chatLLM, model.messages, model.logger)
-		if err != nil {
-			ch <- LLMErrorMsg{Err: err}
-
-			return
+		history := agent.FitContext(ctx, model.provider, model.chatLLM, model.prompts, window, model.messages, model.logger)
+		if len(history) != len(model.messages) {
+			ch <- ContextTrimmedMsg{Messages: history}
 		}
 
-		model.messages = messages
-
-		_, err = llm.StreamChat(
-			model.ctx,
-			model.url,
-			model.chatLLM,
-			model.messages,
-			nil,
-			func(chunk string) {
-				ch <- LLMResponseMsg(chunk)
+		// Tool calls are added to the history in handleToolCallsMsg and the final
+		// message in handleLLMDoneMsg.
+		messages, err := agent.RunToolLoop(
+			ctx,
+			model.toolRegistry,
+			model.provider,
+			llm.ChatRequest{Model: model.chatLLM, Messages: history, Options: model.options, Think: model.think},
+			agent.ToolLoopOptions{
+				OnChunk: func(chunk llm.ChatMessage) {
+					if chunk.Thinking != "" {
+						ch <- LLMThinkingMsg(chunk.Thinking)
+					}
+
+					if chunk.Content != "" {
+						ch <- LLMResponseMsg(chunk.Content)
+					}
+				},
+				OnWarning: func(err error) {
+					ch <- LLMWarningMsg{Err: err}
+				},
+				Approve: func(toolCall llm.ToolCall) bool {
+					return requestApproval(ch, ctx.Done(), toolCall)
+				},
+				OnToolCalls: func(response llm.ChatMessage, results []llm.ChatMessage) {
+					ch <- ToolCallsMsg{Response: response, Results: results}
+				},
 			},
+			model.logger,
 		)
 
 		if err != nil {
+			// The partial response is saved when the channel closes.
+			if ctx.Err() != nil {
+				return
+			}
+
 			ch <- LLMErrorMsg{Err: err}
+
+			return
 		}
+
+		ch <- LLMFinalMsg{Message: messages[len(messages)-1]}
 	}()
 
 	return listenForChunk(model.responseCh)
@@ -57,18 +93,62 @@ func (model TUIModel) handleLLMResponseMsg(msg LLMResponseMsg) (tea.Model, tea.C
 	return model, listenForChunk(model.responseCh)
 }
 
+// handleLLMFinalMsg keeps the final message of the tool loop so it's saved
+// instead of the streamed chunks.
+func (model TUIModel) handleLLMFinalMsg(msg LLMFinalMsg) (tea.Model, tea.Cmd) {
+	if metrics := msg.Message.Metrics; metrics != nil {
+		model.logger.Debug("response metrics", "prompt_eval_count", metrics.PromptEvalCount, "eval_count", metrics.EvalCount, "total_duration", metrics.TotalDuration)
+
+		model.metrics = metrics
+	}
+
+	model.finalResponse = &msg.Message
+
+	return model, listenForChunk(model.responseCh)
+}
+
+func (model TUIModel) handleContextTrimmedMsg(msg ContextTrimmedMsg) (tea.Model, tea.Cmd) {
+	model.logger.Debug("history trimmed to context window", "before", len(model.messages), "after", len(msg.Messages))
+
+	model.messages = msg.Messages
+
+	return model, listenForChunk(model.responseCh)
+}
+
 func (model TUIModel) handleLLMDoneMsg() (tea.Model, tea.Cmd) {
 	model.logger.Debug("transmission complete", "response_length", len(model.currentResponse))
 
+	if model.interrupted {
+		model.chatHistory += " " + interruptedNote
+	}
+
 	model.chatHistory += "\n\n"
 	model.viewport.SetContent(model.renderHistory())
-	assistantMsg := llm.ChatMessage{Role: llm.RoleAssistant, Content: model.currentResponse}
+
+	// The streamed chunks are only saved if the loop didn't finish.
+	assistantMsg := llm.ChatMessage{Role: llm.RoleAssistant, Content: model.currentResponse, Thinking: model.currentThinking}
+	if model.finalResponse != nil {
+		assistantMsg = *model.finalResponse
+	}
+
 	model.messages = append(model.messages, assistantMsg)
 	model = model.saveMessage(assistantMsg)
 
 	model.currentResponse = ""
+	model.currentThinking = ""
+	model.finalResponse = nil
 
-	return model, nil
+	return model.endStream(), nil
+}
+
+func (model TUIModel) handleLLMWarningMsg(msg LLMWarningMsg) (tea.Model, tea.Cmd) {
+	model.logger.Warn("tool loop warning", "error", msg.Err)
+
+	model.chatHistory += fmt.Sprintf("\n[%s warning: %v]\n", style.GlyphInfo, msg.Err)
+	model.viewport.SetContent(model.renderHistory())
+	model.viewport.GotoBottom()
+
+	return model, listenForChunk(model.responseCh)
 }
 
 func (model TUIModel) handleLLMErrorMsg(msg LLMErrorMsg) (tea.Model, tea.Cmd) {
@@ -77,5 +157,42 @@ func (model TUIModel) handleLLMErrorMsg(msg LLMErrorMsg) (tea.Model, tea.Cmd) {
 	model.chatHistory += fmt.Sprintf("\n[%s error: %v]\n", style.GlyphInfo, msg.Err)
 	model.viewport.SetContent(model.renderHistory())
 
-	return model, nil
+	return model.endStream(), nil
+}
+
+// cancelStream cancels the in-flight request and any pending tool calls, or
+// the in-flight compaction.
+// The stream keeps draining so the partial response is saved when it closes.
+func (model TUIModel) cancelStream() TUIModel {
+	if model.compactCancel != nil {
+		model = model.cancelCompact()
+		model.chatHistory += fmt.Sprintf("[%s compact interrupted]\n", style.GlyphInfo)
+		model.viewport.SetContent(model.renderHistory())
+		model.viewport.GotoBottom()
+
+		return model
+	}
+
+	if model.cancel == nil {
+		return model
+	}
+
+	model.logger.Debug("interrupting transmission", "response_length", len(model.currentResponse))
+
+	model.cancel()
+	model.interrupted = true
+
+	return model
+}
+
+// endStream releases the context of the finished request.
+func (model TUIModel) endStream() TUIModel {
+	if model.cancel != nil {
+		model.cancel()
+	}
+
+	model.cancel = nil
+	model.interrupted = false
+
+	return model
 }
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
@@ -55,6 +56,16 @@ func TestTUIModel_HandleLLMMessages(t *testing.T) {
 			wantMessageCount:    1,
 			wantCmd:             false,
 		},
+		{
+			name:                "warning msg adds warning to history and keeps listening",
+			currentResponse:     "",
+			chatHistory:         "",
+			msg:                 LLMWarningMsg{Err: errors.New("test warning")},
+			wantChatHistory:     fmt.Sprintf("\n[%s warning: test warning]\n", style.GlyphInfo),
+			wantCurrentResponse: "",
+			wantMessageCount:    1,
+			wantCmd:             true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -100,3 +111,86 @@ func TestTUIModel_HandleLLMMessages(t *testing.T) {
 		})
 	}
 }
+
+func TestTUIModel_FinalResponseSaved(t *testing.T) {
+	model := newTestModel(t)
+	model.ready = true
+	model.responseCh = make(chan tea.Msg)
+	model.currentResponse = "streamed"
+
+	final := llm.ChatMessage{Role: llm.RoleAssistant, Content: "final answer", Metrics: &llm.Metrics{EvalCount: 3}}
+
+	newModel, cmd := model.Update(LLMFinalMsg{Message: final})
+	model = newModel.(TUIModel)
+
+	if cmd == nil {
+		t.Error("expected listen command, got nil")
+	}
+
+	if model.metrics == nil || model.metrics.EvalCount != 3 {
+		t.Errorf("metrics = %v, want the final message's metrics", model.metrics)
+	}
+
+	newModel, _ = model.Update(LLMDoneMsg{})
+	model = newModel.(TUIModel)
+
+	if got := model.messages[len(model.messages)-1].Content; got != final.Content {
+		t.Errorf("last message content = %q, want %q", got, final.Content)
+	}
+
+	if model.finalResponse != nil {
+		t.Errorf("finalResponse = %v, want nil after the response is saved", model.finalResponse)
+	}
+}
+
+func TestTUIModel_CancelStream(t *testing.T) {
+	model := newTestModel(t)
+	model.ready = true
+	model.responseCh = make(chan tea.Msg)
+	model.chatHistory = "You: hi\n\nghost: partial"
+	model.currentResponse = "partial"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	model.cancel = cancel
+
+	newModel, _ := model.Update(tea.KeyPressMsg{Code: 'c', Mod: tea.ModCtrl})
+	model = newModel.(TUIModel)
+
+	if ctx.Err() == nil {
+		t.Error("ctrl+c didn't cancel the request context")
+	}
+
+	if !model.interrupted {
+		t.Error("interrupted = false, want true")
+	}
+
+	newModel, _ = model.Update(LLMDoneMsg{})
+	model = newModel.(TUIModel)
+
+	wantHistory := "You: hi\n\nghost: partial " + interruptedNote + "\n\n"
+	if model.chatHistory != wantHistory {
+		t.Errorf("chatHistory = %q, want %q", model.chatHistory, wantHistory)
+	}
+
+	if model.cancel != nil || model.interrupted {
+		t.Errorf("stream state not reset: cancel set %v, interrupted %v", model.cancel != nil, model.interrupted)
+	}
+
+	messages, err := model.store.GetMessages(model.threadID)
+	if err != nil {
+		t.Fatalf("GetMessages() err = %v", err)
+	}
+
+	last := messages[len(messages)-1]
+	if last.Content != "partial" || !last.Interrupted {
+		t.Errorf("saved message = %q interrupted %v, want %q interrupted true", last.Content, last.Interrupted, "partial")
+	}
+}
+
+func TestTUIModel_CancelStreamIdle(t *testing.T) {
+	model := newTestModel(t).cancelStream()
+
+	if model.interrupted {
+		t.Error("cancelStream() interrupted idle model")
+	}
+}
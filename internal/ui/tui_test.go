@@ -4,9 +4,12 @@ import (
 	"context"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/theantichris/ghost/v3/internal/agent"
+	"github.com/theantichris/ghost/v3/internal/llm"
 	"github.com/theantichris/ghost/v3/internal/storage"
 	"github.com/theantichris/ghost/v3/internal/tool"
 )
@@ -15,14 +18,55 @@ func newTestModel(t *testing.T) TUIModel {
 	t.Helper()
 
 	logger := log.New(io.Discard)
-	registry := tool.NewRegistry("", 0, logger)
+	registry := tool.NewRegistry(nil, 0, nil, logger)
 
 	store, err := storage.NewStore(t.TempDir())
 	if err != nil {
 		t.Fatalf("failed to create test store: %v", err)
 	}
 
-	config := ModelConfig{Context: context.Background(), URL: "http://localhost/11434/api", ChatLLM: "test-model", VisionLLM: "test-vision-model", Prompts: agent.Prompt{System: "test system prompt"}, Registry: registry, Logger: logger, Store: store}
+	config := ModelConfig{Context: context.Background(), Provider: llm.NewOllama("http://localhost/11434/api"), ChatLLM: "test-model", VisionLLM: "test-vision-model", Prompts: agent.Prompt{System: "test system prompt"}, Registry: registry, Logger: logger, Store: store}
 
 	return NewTUIModel(config)
 }
+
+func TestTUIModel_RenderMetrics(t *testing.T) {
+	numCtx := 8192
+
+	tests := []struct {
+		name    string
+		metrics *llm.Metrics
+		options llm.Options
+		want    string
+	}{
+		{
+			name: "renders nothing before metrics are reported",
+			want: "",
+		},
+		{
+			name:    "renders rate and context used",
+			metrics: &llm.Metrics{PromptEvalCount: 100, EvalCount: 50, EvalDuration: 2 * time.Second},
+			want:    "25.0 tok/s  ctx 150",
+		},
+		{
+			name:    "renders context window size when set",
+			metrics: &llm.Metrics{PromptEvalCount: 100, EvalCount: 50, EvalDuration: 2 * time.Second},
+			options: llm.Options{NumCtx: &numCtx},
+			want:    "25.0 tok/s  ctx 150/8192",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := newTestModel(t)
+			model.metrics = tt.metrics
+			model.options = tt.options
+
+			got := ansi.Strip(model.renderMetrics())
+
+			if got != tt.want {
+				t.Errorf("renderMetrics() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
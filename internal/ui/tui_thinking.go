@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/theantichris/ghost/v3/style"
+)
+
+// thinkingMarker marks where a thinking block is rendered in the chat history.
+// Thinking is kept out of chatHistory so it can be expanded and collapsed.
+const thinkingMarker = "\x00thinking:%d\x00"
+
+func (model TUIModel) handleLLMThinkingMsg(msg LLMThinkingMsg) (tea.Model, tea.Cmd) {
+	if model.currentThinking == "" {
+		model.chatHistory += fmt.Sprintf(thinkingMarker, len(model.thinkingBlocks))
+		model.thinkingBlocks = append(model.thinkingBlocks, "")
+	}
+
+	model.currentThinking += string(msg)
+	model.thinkingBlocks[len(model.thinkingBlocks)-1] = model.currentThinking
+	model.viewport.SetContent(model.renderHistory())
+	model.viewport.GotoBottom()
+
+	return model, listenForChunk(model.responseCh)
+}
+
+// toggleThinking expands or collapses the thinking blocks in the viewport.
+func (model TUIModel) toggleThinking() TUIModel {
+	model.showThinking = !model.showThinking
+	model.viewport.SetContent(model.renderHistory())
+
+	return model
+}
+
+// renderThinking returns a thinking block dimmed when expanded or a one line
+// placeholder when collapsed.
+func (model TUIModel) renderThinking(thinking string) string {
+	if model.hideThinking {
+		return ""
+	}
+
+	if !model.showThinking {
+		placeholder := fmt.Sprintf("[%s thinking: %d words, z to expand]", style.GlyphInfo, len(strings.Fields(thinking)))
+
+		return style.FgTextMuted.Render(placeholder) + "\n\n"
+	}
+
+	return style.FgTextMuted.Render(strings.TrimSpace(thinking)) + "\n\n"
+}
@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/internal/storage"
+)
+
+func TestTUIModel_Thinking(t *testing.T) {
+	tests := []struct {
+		name         string
+		hideThinking bool
+		toggle       bool
+		wantContain  []string
+		wantExclude  []string
+	}{
+		{
+			name:        "thinking is collapsed by default",
+			wantContain: []string{"thinking: 2 words", "Hello runner"},
+			wantExclude: []string{"greet them"},
+		},
+		{
+			name:        "toggle expands thinking",
+			toggle:      true,
+			wantContain: []string{"greet them", "Hello runner"},
+			wantExclude: []string{"thinking: 2 words"},
+		},
+		{
+			name:         "hide thinking renders no thinking",
+			hideThinking: true,
+			toggle:       true,
+			wantContain:  []string{"Hello runner"},
+			wantExclude:  []string{"greet them", "thinking: 2 words"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := newTestModel(t)
+			model.ready = true
+			model.hideThinking = tt.hideThinking
+			model.responseCh = make(chan tea.Msg)
+			model.chatHistory = "You: hi\n\nghost: "
+
+			var result tea.Model = model
+			for _, msg := range []tea.Msg{LLMThinkingMsg("greet "), LLMThinkingMsg("them"), LLMResponseMsg("Hello runner"), LLMDoneMsg{}} {
+				result, _ = result.Update(msg)
+			}
+
+			if tt.toggle {
+				result, _ = result.Update(tea.KeyPressMsg{Text: "z"})
+			}
+
+			got := result.(TUIModel)
+			history := got.renderHistory()
+
+			for _, want := range tt.wantContain {
+				if !strings.Contains(history, want) {
+					t.Errorf("renderHistory() = %q, missing %q", history, want)
+				}
+			}
+
+			for _, exclude := range tt.wantExclude {
+				if strings.Contains(history, exclude) {
+					t.Errorf("renderHistory() = %q, should not contain %q", history, exclude)
+				}
+			}
+
+			lastMsg := got.messages[len(got.messages)-1]
+			if lastMsg.Content != "Hello runner" || lastMsg.Thinking != "greet them" {
+				t.Errorf("last message = %+v, want content and thinking split", lastMsg)
+			}
+		})
+	}
+}
+
+func TestTUIModel_LoadThreadThinking(t *testing.T) {
+	model := newTestModel(t)
+
+	thread, err := model.store.CreateThread("test thread")
+	if err != nil {
+		t.Fatalf("failed to create thread: %v", err)
+	}
+
+	_, err = model.store.AddMessage(thread.ID, llm.ChatMessage{Role: llm.RoleAssistant, Content: "Hello runner", Thinking: "greet them"}, storage.Metadata{})
+	if err != nil {
+		t.Fatalf("failed to add message: %v", err)
+	}
+
+	model, err = model.loadThread(thread.ID)
+	if err != nil {
+		t.Fatalf("loadThread() err = %v, want nil", err)
+	}
+
+	if model.messages[0].Thinking != "greet them" {
+		t.Errorf("loadThread() thinking = %q, want %q", model.messages[0].Thinking, "greet them")
+	}
+
+	model = model.toggleThinking()
+
+	if history := model.renderHistory(); !strings.Contains(history, "greet them") {
+		t.Errorf("renderHistory() = %q, missing thinking", history)
+	}
+}
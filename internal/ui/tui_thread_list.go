@@ -30,6 +30,7 @@ func (model TUIModel) handleThreadListMode(msg tea.KeyPressMsg) (tea.Model, tea.
 		selectedThread, ok := model.threadList.list.SelectedItem().(threadItem)
 		if ok {
 			var err error
+			model = model.cancelCompact()
 			model, err = model.loadThread(selectedThread.thread.ID)
 			if err != nil {
 				model.logger.Error("error loading thread", "thread_id", selectedThread.thread.ID, "error", err.Error())
@@ -5,6 +5,7 @@ import (
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/internal/storage"
 )
 
 func TestTUIModel_HandleThreadListMode(t *testing.T) {
@@ -61,7 +62,7 @@ func TestTUIModel_HandleThreadListMode(t *testing.T) {
 				}
 
 				for _, msg := range tt.seedMessages {
-					_, err := model.store.AddMessage(thread.ID, msg)
+					_, err := model.store.AddMessage(thread.ID, msg, storage.Metadata{})
 					if err != nil {
 						t.Fatalf("failed to add message: %v", err)
 					}
@@ -139,7 +140,7 @@ func TestTUIModel_HandleThreadListMode_LoadsMessages(t *testing.T) {
 	}
 
 	for _, msg := range seedMessages {
-		_, err := model.store.AddMessage(thread.ID, msg)
+		_, err := model.store.AddMessage(thread.ID, msg, storage.Metadata{})
 		if err != nil {
 			t.Fatalf("failed to add message: %v", err)
 		}
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/theantichris/ghost/v3/internal/llm"
+	"github.com/theantichris/ghost/v3/style"
+)
+
+// toolMarker marks where a tool call block is rendered in the chat history.
+// Tool calls are kept out of chatHistory so they can be expanded and collapsed.
+const toolMarker = "\x00tool:%d\x00"
+
+// maxToolResultLines is the number of lines of a tool result shown when its
+// block is expanded.
+const maxToolResultLines = 10
+
+// toolBlock holds a tool call and its result for display.
+type toolBlock struct {
+	call     llm.ToolCall
+	result   string
+	duration time.Duration
+}
+
+// ToolCallsMsg carries a response's tool calls and their results once they've
+// run.
+type ToolCallsMsg struct {
+	Response llm.ChatMessage
+	Results  []llm.ChatMessage
+}
+
+// handleToolCallsMsg adds the response and results to the history and saves
+// them. The streamed response so far belongs to the tool call message, the
+// response after the calls starts empty.
+func (model TUIModel) handleToolCallsMsg(msg ToolCallsMsg) (tea.Model, tea.Cmd) {
+	model.logger.Debug("tool calls complete", "calls", len(msg.Response.ToolCalls))
+
+	if msg.Response.Metrics != nil {
+		model.metrics = msg.Response.Metrics
+	}
+
+	model.messages = append(model.messages, msg.Response)
+	model = model.saveMessage(msg.Response)
+
+	model.chatHistory += "\n"
+
+	for i, result := range msg.Results {
+		model.messages = append(model.messages, result)
+		model = model.saveMessage(result)
+
+		var call llm.ToolCall
+		if i < len(msg.Response.ToolCalls) {
+			call = msg.Response.ToolCalls[i]
+		}
+
+		model = model.addToolBlock(toolBlock{call: call, result: result.Content, duration: result.Duration})
+	}
+
+	model.currentResponse = ""
+	model.currentThinking = ""
+	model.viewport.SetContent(model.renderHistory())
+	model.viewport.GotoBottom()
+
+	return model, listenForChunk(model.responseCh)
+}
+
+// addToolBlock adds block to the chat history at a marker.
+func (model TUIModel) addToolBlock(block toolBlock) TUIModel {
+	model.chatHistory += fmt.Sprintf(toolMarker, len(model.toolBlocks))
+	model.toolBlocks = append(model.toolBlocks, block)
+
+	return model
+}
+
+// toggleTools expands or collapses the tool call blocks in the viewport.
+func (model TUIModel) toggleTools() TUIModel {
+	model.showTools = !model.showTools
+	model.viewport.SetContent(model.renderHistory())
+
+	return model
+}
+
+// renderToolBlock returns a tool call block dimmed with its arguments and the
+// start of its result when expanded, or a one line summary when collapsed.
+func (model TUIModel) renderToolBlock(block toolBlock) string {
+	name := block.call.Function.Name
+	if name == "" {
+		name = "tool"
+	}
+
+	header := fmt.Sprintf("[%s %s", style.GlyphInfo, name)
+	if block.duration > 0 {
+		header += " " + block.duration.Round(time.Millisecond).String()
+	}
+
+	lines := strings.Split(strings.TrimSpace(block.result), "\n")
+
+	if !model.showTools {
+		summary := fmt.Sprintf("%s: %d lines, t to expand]", header, len(lines))
+
+		return style.FgTextMuted.Render(summary) + "\n"
+	}
+
+	var expanded strings.Builder
+	expanded.WriteString(header + "]\n")
+
+	if len(block.call.Function.Arguments) > 0 {
+		expanded.WriteString(formatArguments(block.call.Function.Arguments) + "\n")
+	}
+
+	if len(lines) > maxToolResultLines {
+		dropped := len(lines) - maxToolResultLines
+		lines = append(lines[:maxToolResultLines], fmt.Sprintf("[%d more lines]", dropped))
+	}
+
+	expanded.WriteString("→ " + strings.Join(lines, "\n  "))
+
+	return style.FgTextMuted.Render(expanded.String()) + "\n"
+}
@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/theantichris/ghost/v3/internal/llm"
+)
+
+// newToolCallsMsg returns a ToolCallsMsg for a web_search call and its result.
+func newToolCallsMsg() ToolCallsMsg {
+	var call llm.ToolCall
+	call.Function.Name = "web_search"
+	call.Function.Arguments = json.RawMessage(`{"query":"arasaka"}`)
+
+	return ToolCallsMsg{
+		Response: llm.ChatMessage{Role: llm.RoleAssistant, Content: "Searching", ToolCalls: []llm.ToolCall{call}},
+		Results:  []llm.ChatMessage{{Role: llm.RoleTool, Content: "Arasaka Tower\nNight City", Duration: 1500 * time.Millisecond}},
+	}
+}
+
+func TestTUIModel_ToolCalls(t *testing.T) {
+	tests := []struct {
+		name        string
+		toggle      bool
+		wantContain []string
+		wantExclude []string
+	}{
+		{
+			name:        "tool calls are collapsed by default",
+			wantContain: []string{"Searching", "web_search 1.5s: 2 lines", "Found it"},
+			wantExclude: []string{`"query"`, "Night City"},
+		},
+		{
+			name:        "toggle expands tool calls",
+			toggle:      true,
+			wantContain: []string{"web_search 1.5s]", `"query": "arasaka"`, "Night City", "Found it"},
+			wantExclude: []string{"2 lines"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := newTestModel(t)
+			model.ready = true
+			model.responseCh = make(chan tea.Msg)
+			model.chatHistory = "You: hi\n\nghost: "
+
+			var result tea.Model = model
+			for _, msg := range []tea.Msg{LLMResponseMsg("Searching"), newToolCallsMsg(), LLMResponseMsg("Found it"), LLMDoneMsg{}} {
+				result, _ = result.Update(msg)
+			}
+
+			if tt.toggle {
+				result, _ = result.Update(tea.KeyPressMsg{Text: "t"})
+			}
+
+			got := result.(TUIModel)
+			history := got.renderHistory()
+
+			for _, want := range tt.wantContain {
+				if !strings.Contains(history, want) {
+					t.Errorf("renderHistory() = %q, missing %q", history, want)
+				}
+			}
+
+			for _, exclude := range tt.wantExclude {
+				if strings.Contains(history, exclude) {
+					t.Errorf("renderHistory() = %q, should not contain %q", history, exclude)
+				}
+			}
+
+			roles := []llm.Role{llm.RoleSystem, llm.RoleAssistant, llm.RoleTool, llm.RoleAssistant}
+			if len(got.messages) != len(roles) {
+				t.Fatalf("messages = %d, want %d", len(got.messages), len(roles))
+			}
+
+			for i, role := range roles {
+				if got.messages[i].Role != role {
+					t.Errorf("messages[%d] role = %s, want %s", i, got.messages[i].Role, role)
+				}
+			}
+
+			if last := got.messages[len(got.messages)-1]; last.Content != "Found it" {
+				t.Errorf("last message content = %q, want the response after the tool calls", last.Content)
+			}
+		})
+	}
+}
+
+func TestTUIModel_LoadThreadToolCalls(t *testing.T) {
+	model := newTestModel(t)
+	model.ready = true
+	model.responseCh = make(chan tea.Msg)
+
+	model = model.saveMessage(llm.ChatMessage{Role: llm.RoleUser, Content: "find arasaka"})
+
+	var result tea.Model = model
+	for _, msg := range []tea.Msg{LLMResponseMsg("Searching"), newToolCallsMsg(), LLMResponseMsg("Found it"), LLMDoneMsg{}} {
+		result, _ = result.Update(msg)
+	}
+
+	saved, err := model.store.GetMessages(result.(TUIModel).threadID)
+	if err != nil {
+		t.Fatalf("GetMessages() err = %v, want nil", err)
+	}
+
+	if len(saved) != 4 || saved[2].Role != llm.RoleTool || saved[2].Duration != 1500*time.Millisecond {
+		t.Fatalf("saved messages = %+v, want user, tool call, tool result with duration, response", saved)
+	}
+
+	loaded, err := model.loadThread(result.(TUIModel).threadID)
+	if err != nil {
+		t.Fatalf("loadThread() err = %v, want nil", err)
+	}
+
+	if len(loaded.messages) != 4 || len(loaded.messages[1].ToolCalls) != 1 {
+		t.Errorf("loadThread() messages = %+v, want the tool call kept", loaded.messages)
+	}
+
+	loaded = loaded.toggleTools()
+	history := loaded.renderHistory()
+
+	for _, want := range []string{"You: find arasaka", "ghost: Searching", "web_search 1.5s]", "Night City", "Found it"} {
+		if !strings.Contains(history, want) {
+			t.Errorf("renderHistory() = %q, missing %q", history, want)
+		}
+	}
+
+	if strings.Count(history, "ghost:") != 1 {
+		t.Errorf("renderHistory() = %q, want one ghost label for the turn", history)
+	}
+}
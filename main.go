@@ -10,10 +10,12 @@ import (
 )
 
 func main() {
+	errorHandler := style.NewFangErrorHandler(cmd.ErrorHints)
+
 	rootCmd, loggerCleanup, err := cmd.NewRootCmd()
 
 	if err != nil {
-		style.FangErrorHandler(os.Stderr, fang.Styles{}, err)
+		errorHandler(os.Stderr, fang.Styles{}, err)
 		os.Exit(1)
 	}
 
@@ -26,7 +28,7 @@ func main() {
 		rootCmd,
 		fang.WithVersion(rootCmd.Version),
 		fang.WithColorSchemeFunc(style.GetFangColorScheme),
-		fang.WithErrorHandler(style.FangErrorHandler),
+		fang.WithErrorHandler(errorHandler),
 		fang.WithNotifySignal(os.Interrupt),
 	); err != nil {
 		os.Exit(1)
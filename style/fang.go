@@ -1,6 +1,7 @@
 package style
 
 import (
+	"errors"
 	"fmt"
 	"image/color"
 	"io"
@@ -9,6 +10,12 @@ import (
 	"github.com/charmbracelet/fang"
 )
 
+// ErrorHint is a hint on how to fix errors matching Err.
+type ErrorHint struct {
+	Err  error
+	Hint string
+}
+
 var colorScheme = fang.ColorScheme{
 	Base:           Text,
 	Title:          Accent0,
@@ -36,16 +43,34 @@ func GetFangColorScheme(ld lipgloss.LightDarkFunc) fang.ColorScheme {
 	return colorScheme
 }
 
-// FangErrorHandler renders error messages with styles.
-func FangErrorHandler(w io.Writer, styles fang.Styles, err error) {
-	headerStyle := lipgloss.NewStyle().
-		Foreground(Error)
+// NewFangErrorHandler returns a handler that renders error messages with
+// styles, followed by the first of hints matching the error.
+func NewFangErrorHandler(hints []ErrorHint) func(io.Writer, fang.Styles, error) {
+	return func(w io.Writer, styles fang.Styles, err error) {
+		headerStyle := lipgloss.NewStyle().
+			Foreground(Error)
+
+		messageStyle := lipgloss.NewStyle().
+			Foreground(Error)
 
-	messageStyle := lipgloss.NewStyle().
-		Foreground(Error)
+		header := headerStyle.Render(GlyphError + " error: ")
+		message := messageStyle.Render(err.Error())
+
+		fmt.Fprintf(w, "%s%s\n", header, message)
+
+		if hint := errorHint(hints, err); hint != "" {
+			fmt.Fprintf(w, "%s\n", FgTextMuted.Render(GlyphInfo+" hint: "+hint))
+		}
+	}
+}
 
-	header := headerStyle.Render(GlyphError + " error: ")
-	message := messageStyle.Render(err.Error())
+// errorHint returns the hint for err or an empty string if there isn't one.
+func errorHint(hints []ErrorHint, err error) string {
+	for _, errorHint := range hints {
+		if errors.Is(err, errorHint.Err) {
+			return errorHint.Hint
+		}
+	}
 
-	fmt.Fprintf(w, "%s%s\n", header, message)
+	return ""
 }
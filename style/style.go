@@ -56,6 +56,7 @@ var (
 var (
 	FgAccent0       = lipgloss.NewStyle().Foreground(Accent0)
 	FgText          = lipgloss.NewStyle().Foreground(Text)
+	FgTextMuted     = lipgloss.NewStyle().Foreground(TextMuted)
 	JSONKey         = lipgloss.NewStyle().Foreground(Accent1)
 	JSONString      = lipgloss.NewStyle().Foreground(SyntaxString)
 	JSONNumber      = lipgloss.NewStyle().Foreground(SyntaxString)